@@ -5,7 +5,10 @@
 //   - 无状态辅助函数 [Run]、[RunAll]、[Wait]、[AllOf]、[AnyOf],适用于任务集
 //     已知的一次性 fan-out。
 //   - [Group] 构建器(见 [New]),适用于任务被增量添加的开放式场景,可选并发
-//     上限与"首个出错即取消"。
+//     上限、启动速率与"首个出错即取消"。
+//
+// 此外,[Go]/[Detach] 用于请求返回后仍需继续的后台任务,[SingleFlight]
+// 用于按 key 合并并发调用。
 //
 // 每个任务都在 panic 安全的包装器中执行,panic 会被转换为有类型的
 // [*PanicError],因此 panic 的任务永远不会让进程崩溃。
@@ -0,0 +1,72 @@
+package async
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/tenz-io/gokit/logger/v3"
+	"github.com/tenz-io/gokit/monitor/v3"
+	"github.com/tenz-io/gokit/tracer/v3"
+)
+
+// Detach 返回一个与 ctx 取消解耦的全新 context:它不继承 ctx 的 deadline
+// 与取消,只转发请求级的可观测性值——request ID、[tracer.Flag]、绑定的
+// logger Entry([logger.CopyToContext])与单飞 Exporter
+// ([monitor.CopyToContext])。其余 ctx 值不会被携带,因此被分离的任务不会
+// 意外持有请求作用域的资源。
+//
+// timeout 为正数时,返回的 context 拥有自己的超时;否则仅能通过返回的
+// cancel 取消。调用方必须调用 cancel 以释放资源。nil ctx 视作
+// context.Background()。
+func Detach(ctx context.Context, timeout time.Duration) (context.Context, context.CancelFunc) {
+	detached := context.Background()
+	if ctx != nil {
+		detached = tracer.WithRequestID(detached, tracer.RequestIDFromCtxOr(ctx))
+		if flag := tracer.FromContext(ctx); flag != tracer.FlagNone {
+			detached = tracer.WithFlag(detached, flag)
+		}
+		detached = logger.CopyToContext(ctx, detached)
+		detached = monitor.CopyToContext(ctx, detached)
+	}
+	if timeout > 0 {
+		return context.WithTimeout(detached, timeout)
+	}
+	return context.WithCancel(detached)
+}
+
+// Go 以 fire-and-forget 方式在新 goroutine 中执行 task,适用于请求返回后
+// 仍需继续的后台工作(写审计、刷缓存、异步通知)。task 收到的是 [Detach]
+// 派生的 context:调用方 ctx 被取消或到期不会影响它,但 request ID、flag、
+// logger 与 Exporter 会被保留;timeout 为正数时限定其自身的运行时长。
+//
+// Go 立即返回。task 的返回值与错误被丢弃;panic 会被 recover,并通过
+// [logger.FromContext] 以原始 request ID 记一条 error 日志(带 panic 值与
+// 堆栈),绝不让进程崩溃。nil task 为 no-op。
+func Go[T any](ctx context.Context, timeout time.Duration, task Task[T]) {
+	if task == nil {
+		return
+	}
+	detached, cancel := Detach(ctx, timeout)
+	go func() {
+		defer cancel()
+		_, err := recoverTask(task)(detached)
+		var pe *PanicError
+		if errors.As(err, &pe) {
+			logPanic(detached, pe)
+		}
+	}()
+}
+
+// logPanic 以 detached context 上的 logger 与 request ID 记录 panic。
+// request ID 缺失时不追加该 field,避免记下一个与请求无关的生成 id。
+func logPanic(ctx context.Context, pe *PanicError) {
+	entry := logger.FromContext(ctx)
+	if id := tracer.RequestIDFromCtxOr(ctx); id != "" {
+		entry = entry.WithRequestID(id)
+	}
+	entry.Errorw("async: detached task panic",
+		"panic", pe.Value(),
+		"stack", string(pe.Stack()),
+	)
+}
@@ -0,0 +1,169 @@
+package async
+
+import (
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/tenz-io/gokit/logger/v3"
+	"github.com/tenz-io/gokit/monitor/v3"
+	"github.com/tenz-io/gokit/tracer/v3"
+)
+
+func TestDetach_SurvivesParentCancel(t *testing.T) {
+	parent, cancel := context.WithCancel(context.Background())
+	detached, dcancel := Detach(parent, 0)
+	defer dcancel()
+
+	cancel()
+	if err := detached.Err(); err != nil {
+		t.Errorf("detached.Err() after parent cancel = %v, want nil", err)
+	}
+	if _, ok := detached.Deadline(); ok {
+		t.Error("detached ctx should have no deadline when timeout <= 0")
+	}
+	dcancel()
+	if !errors.Is(detached.Err(), context.Canceled) {
+		t.Errorf("detached.Err() after own cancel = %v, want Canceled", detached.Err())
+	}
+}
+
+func TestDetach_IgnoresParentDeadline(t *testing.T) {
+	parent, cancel := context.WithTimeout(context.Background(), time.Millisecond)
+	defer cancel()
+	<-parent.Done()
+
+	detached, dcancel := Detach(parent, time.Hour)
+	defer dcancel()
+	if err := detached.Err(); err != nil {
+		t.Fatalf("detached.Err() = %v, want nil", err)
+	}
+	dl, ok := detached.Deadline()
+	if !ok || time.Until(dl) < 59*time.Minute {
+		t.Errorf("detached deadline = %v (ok=%v), want ~1h from now", dl, ok)
+	}
+}
+
+func TestDetach_OwnTimeout(t *testing.T) {
+	detached, dcancel := Detach(context.Background(), 5*time.Millisecond)
+	defer dcancel()
+	select {
+	case <-detached.Done():
+	case <-time.After(time.Second):
+		t.Fatal("detached ctx did not time out")
+	}
+	if !errors.Is(detached.Err(), context.DeadlineExceeded) {
+		t.Errorf("detached.Err() = %v, want DeadlineExceeded", detached.Err())
+	}
+}
+
+func TestDetach_CarriesObservabilityValues(t *testing.T) {
+	type otherKey struct{}
+	entry := logger.With("k", "v")
+	ctx := context.Background()
+	ctx = tracer.WithRequestID(ctx, "req-1")
+	ctx = tracer.WithFlag(ctx, tracer.FlagDebug)
+	ctx = logger.WithLogger(ctx, entry)
+	ctx = monitor.Init(ctx, "cmd")
+	ctx = context.WithValue(ctx, otherKey{}, "x")
+
+	detached, dcancel := Detach(ctx, 0)
+	defer dcancel()
+
+	if got := tracer.RequestIDFromCtxOr(detached); got != "req-1" {
+		t.Errorf("request ID = %q, want req-1", got)
+	}
+	if got := tracer.FromContext(detached); got != tracer.FlagDebug {
+		t.Errorf("flag = %v, want FlagDebug", got)
+	}
+	if got := logger.FromContext(detached); got != entry {
+		t.Error("logger Entry not carried over")
+	}
+	if got := monitor.FromContext(detached); got != monitor.FromContext(ctx) {
+		t.Error("monitor Exporter not carried over")
+	}
+	if detached.Value(otherKey{}) != nil {
+		t.Error("unrelated ctx values should not be carried over")
+	}
+}
+
+func TestDetach_NilContext(t *testing.T) {
+	detached, dcancel := Detach(nil, 0)
+	defer dcancel()
+	if detached == nil || detached.Err() != nil {
+		t.Errorf("Detach(nil) = %v, want live ctx", detached)
+	}
+	if got := tracer.RequestIDFromCtxOr(detached); got != "" {
+		t.Errorf("request ID = %q, want empty", got)
+	}
+}
+
+func TestGo_RunsDetached(t *testing.T) {
+	parent, cancel := context.WithCancel(tracer.WithRequestID(context.Background(), "req-2"))
+	release := make(chan struct{})
+	type seen struct {
+		id  string
+		err error
+	}
+	out := make(chan seen, 1)
+
+	Go(parent, time.Second, func(ctx context.Context) (int, error) {
+		<-release
+		out <- seen{id: tracer.RequestIDFromCtxOr(ctx), err: ctx.Err()}
+		return 0, nil
+	})
+	cancel()
+	close(release)
+
+	select {
+	case s := <-out:
+		if s.id != "req-2" {
+			t.Errorf("request ID = %q, want req-2", s.id)
+		}
+		if s.err != nil {
+			t.Errorf("ctx.Err() = %v, want nil after parent cancel", s.err)
+		}
+	case <-time.After(time.Second):
+		t.Fatal("detached task did not run")
+	}
+}
+
+func TestGo_NilTask(t *testing.T) {
+	Go[int](context.Background(), 0, nil) // 不应 panic
+}
+
+func TestGo_PanicLoggedWithRequestID(t *testing.T) {
+	dir := t.TempDir()
+	entry := logger.NewEntryWithOpts(
+		logger.WithConsole(false),
+		logger.WithFilePath(dir),
+		logger.WithEncoding(logger.JSONEncoding),
+	)
+	ctx := tracer.WithRequestID(context.Background(), "req-panic")
+	ctx = logger.WithLogger(ctx, entry)
+
+	done := make(chan struct{})
+	Go(ctx, 0, func(context.Context) (int, error) {
+		defer close(done)
+		panic("boom")
+	})
+	<-done
+
+	path := filepath.Join(dir, "error.log")
+	deadline := time.Now().Add(time.Second)
+	for {
+		b, _ := os.ReadFile(path)
+		s := string(b)
+		if strings.Contains(s, "req-panic") && strings.Contains(s, "boom") {
+			return
+		}
+		if time.Now().After(deadline) {
+			t.Fatalf("error.log = %q, want panic entry with request ID", s)
+		}
+		time.Sleep(5 * time.Millisecond)
+	}
+}
@@ -8,6 +8,7 @@ import (
 	"time"
 
 	"github.com/tenz-io/gokit/async/v3"
+	"github.com/tenz-io/gokit/tracer/v3"
 )
 
 func main() {
@@ -53,6 +54,19 @@ func main() {
 		fmt.Printf("Group err=%v (first failure wins)\n", err)
 	}
 	fmt.Printf("Group successes: %d\n", len(g.Results()))
+
+	// Go: fire-and-forget detached from the request ctx. The request ID is
+	// carried over; cancelling reqCtx does not stop the background task.
+	reqCtx, cancelReq := context.WithCancel(tracer.WithRequestID(ctx, "req-123"))
+	done := make(chan struct{})
+	async.Go(reqCtx, time.Second, func(ctx context.Context) (struct{}, error) {
+		defer close(done)
+		time.Sleep(5 * time.Millisecond)
+		fmt.Printf("Go request_id=%s err=%v\n", tracer.RequestIDFromCtxOr(ctx), ctx.Err())
+		return struct{}{}, nil
+	})
+	cancelReq() // the request returns; the detached task keeps running
+	<-done
 }
 
 func slowTask(v string, d time.Duration) async.Task[string] {
@@ -10,7 +10,9 @@ import (
 type Option[T any] func(*Group[T])
 
 // WithLimit 将并发任务数上限设为 n。超出上限的任务会阻塞,直到有空位释放。
-// 非正数将被忽略(默认无上限)。本选项不影响 cancel-on-error 行为。
+// 非正数将被忽略(默认无上限)。[Group.GoWeighted] 提交的任务按其权重占用
+// 空位。仅设置 WithLimit 时,[Group.Go] 提交的任务与以往一样总会运行(等位
+// 不可取消);GoWeighted 的等位可被派生 context 的取消打断。
 func WithLimit[T any](n int) Option[T] {
 	return func(g *Group[T]) {
 		if n > 0 {
@@ -29,6 +31,19 @@ func WithCancelOnError[T any]() Option[T] {
 	}
 }
 
+// WithRate 为任务的启动速率加上令牌桶限制:平均每秒最多启动 perSecond
+// 个任务,允许 burst 个突发(小于 1 按 1 计)。它与 [WithLimit] 正交——
+// 前者限定"每秒启动多少",后者限定"同时在跑多少";同时设置时先占空位、
+// 再取令牌。每个任务消耗一枚令牌,与权重无关。perSecond 非正数将被忽略。
+// 等待令牌期间若派生 context 被取消,任务不会启动。
+func WithRate[T any](perSecond float64, burst int) Option[T] {
+	return func(g *Group[T]) {
+		if perSecond > 0 {
+			g.rate = newTokenBucket(perSecond, burst)
+		}
+	}
+}
+
 // Group 是一个通用、panic 安全的 errgroup 风格构建器,服务于共享同一类型
 // 参数 T 的任务。用 [Group.Go] 添加任务;收集合并后的错误([Group.Wait])
 // 或有序结果([Group.Results])。零值 Group 不可用——务必通过 [New] 获取。
@@ -39,7 +54,8 @@ type Group[T any] struct {
 	limit         int
 	cancelOnError bool
 
-	sem      chan struct{}
+	sem      *semaphore   // 仅在设置了 WithLimit 时非 nil
+	rate     *tokenBucket // 仅在设置了 WithRate 时非 nil
 	wg       sync.WaitGroup
 	mu       sync.Mutex
 	errs     []error
@@ -64,46 +80,65 @@ func New[T any](ctx context.Context, opts ...Option[T]) *Group[T] {
 		opt(g)
 	}
 	if g.limit > 0 {
-		g.sem = make(chan struct{}, g.limit)
+		g.sem = newSemaphore(g.limit)
 	}
 	return g
 }
 
-// Go 提交一个任务以并发执行。当设置了 [WithLimit] 时,它会阻塞直到获得
-// 并发空位。不支持 Go 与 Wait 并发调用:先添加完所有任务,再调用 Wait。
+// Go 提交一个任务以并发执行。当设置了 [WithLimit] 或 [WithRate] 时,它会
+// 阻塞直到获得并发空位与启动令牌。不支持 Go 与 Wait 并发调用:先添加完
+// 所有任务,再调用 Wait。
+//
+// 仅设置 [WithLimit] 时,等位不可取消,提交的任务总会运行(任务自行通过
+// ctx 观察取消);设置了 [WithRate] 时,其放行语义同 [Group.GoWeighted]。
 //
 // nil 任务为 no-op。结果按完成顺序收集;仅当未使用
 // [WithCancelOnError] 时,才应在 [Group.Wait] 之后调用 [Group.Results]
 // (被取消的任务不会产生结果)。
 func (g *Group[T]) Go(task Task[T]) {
+	g.submit(1, task, g.rate != nil)
+}
+
+// GoWeighted 提交一个占用 weight 个并发空位的任务,用于让重任务(例如一次
+// 大导出)在 [WithLimit] 下按多个空位计。weight 非正数按 1 计;超过上限时
+// 按上限计(独占整个 group),否则它永远无法被调度。未设置 WithLimit 时
+// 权重不起作用。空位按提交顺序分配,大权重任务不会被后到的小任务饿死。
+//
+// 与 [Group.Go] 相同,它会阻塞到任务被放行。若等位或等令牌期间派生
+// context 被取消,任务不会启动,其 ctx.Err() 按一次失败记录(在
+// cancel-on-error 模式下作为下游症状被丢弃)。
+func (g *Group[T]) GoWeighted(weight int, task Task[T]) {
+	g.submit(weight, task, true)
+}
+
+// submit 放行并启动任务。cancellable 为 false 时以不可取消的方式等位,
+// 保持 [Group.Go] 在仅设置 [WithLimit] 时"提交即运行"的语义。
+func (g *Group[T]) submit(weight int, task Task[T], cancellable bool) {
 	if task == nil {
 		return
 	}
+	if weight < 1 {
+		weight = 1
+	}
 	g.started = true
 	g.wg.Add(1)
-	if g.sem != nil {
-		g.sem <- struct{}{}
+	ctx := g.derived
+	if !cancellable {
+		ctx = context.WithoutCancel(ctx)
+	}
+	if err := g.admit(ctx, weight); err != nil {
+		g.fail(err)
+		g.wg.Done()
+		return
 	}
 	go func() {
 		defer g.wg.Done()
 		if g.sem != nil {
-			defer func() { <-g.sem }()
+			defer g.sem.release(weight)
 		}
 		v, err := recoverTask(task)(g.derived)
 		if err != nil {
-			g.mu.Lock()
-			// 在 cancel-on-error 模式下,因 group 已被取消(ctx.Err())而失败
-			// 的任务属于下游症状,而非独立失败:丢弃它,使 Wait 只上报首个错误。
-			if g.cancelOnError && g.firstErr != nil && errors.Is(err, context.Canceled) {
-				g.mu.Unlock()
-				return
-			}
-			g.errs = append(g.errs, err)
-			if g.cancelOnError && g.firstErr == nil {
-				g.firstErr = err
-				g.cancel()
-			}
-			g.mu.Unlock()
+			g.fail(err)
 			return
 		}
 		g.mu.Lock()
@@ -112,6 +147,41 @@ func (g *Group[T]) Go(task Task[T]) {
 	}()
 }
 
+// admit 在 ctx 下依次占用 weight 个并发空位与一枚启动令牌。取令牌失败时
+// 退还已占的空位,使失败的提交不泄漏容量。
+func (g *Group[T]) admit(ctx context.Context, weight int) error {
+	if g.sem != nil {
+		if err := g.sem.acquire(ctx, weight); err != nil {
+			return err
+		}
+	}
+	if g.rate != nil {
+		if err := g.rate.wait(ctx); err != nil {
+			if g.sem != nil {
+				g.sem.release(weight)
+			}
+			return err
+		}
+	}
+	return nil
+}
+
+// fail 记录一次任务失败,并在 cancel-on-error 模式下于首错时取消 group。
+func (g *Group[T]) fail(err error) {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	// 在 cancel-on-error 模式下,因 group 已被取消(ctx.Err())而失败
+	// 的任务属于下游症状,而非独立失败:丢弃它,使 Wait 只上报首个错误。
+	if g.cancelOnError && g.firstErr != nil && errors.Is(err, context.Canceled) {
+		return
+	}
+	g.errs = append(g.errs, err)
+	if g.cancelOnError && g.firstErr == nil {
+		g.firstErr = err
+		g.cancel()
+	}
+}
+
 // Wait 阻塞至所有已提交任务完成,并返回合并后的错误。设置
 // [WithCancelOnError] 时返回首个失败;否则合并每个错误。没有任务(或仅有
 // nil 任务)的 group 返回 nil。
@@ -177,3 +177,171 @@ func TestGroup_ResultsAliasSafe(t *testing.T) {
 		t.Errorf("internal results mutated: got %d, want 5", again[0].Value)
 	}
 }
+
+func TestGroup_GoWeighted_BoundsWeightedConcurrency(t *testing.T) {
+	const limit = 5
+	var inUse, peak int32
+	track := func(w int32) Task[int] {
+		return func(context.Context) (int, error) {
+			cur := atomic.AddInt32(&inUse, w)
+			for {
+				p := atomic.LoadInt32(&peak)
+				if cur <= p || atomic.CompareAndSwapInt32(&peak, p, cur) {
+					break
+				}
+			}
+			time.Sleep(5 * time.Millisecond)
+			atomic.AddInt32(&inUse, -w)
+			return 0, nil
+		}
+	}
+	g := New[int](context.Background(), WithLimit[int](limit))
+	for i := 0; i < 10; i++ {
+		g.Go(track(1))
+		g.GoWeighted(3, track(3))
+	}
+	if err := g.Wait(); err != nil {
+		t.Fatalf("Wait() = %v", err)
+	}
+	if got := atomic.LoadInt32(&peak); got > limit {
+		t.Errorf("peak weighted usage = %d, want <= %d", got, limit)
+	}
+}
+
+func TestGroup_GoWeighted_ClampsToLimit(t *testing.T) {
+	g := New[int](context.Background(), WithLimit[int](2))
+	g.GoWeighted(10, intTask(1, nil)) // weight above the limit must not deadlock
+	g.GoWeighted(0, intTask(2, nil))  // non-positive weight counts as 1
+	if err := g.Wait(); err != nil {
+		t.Fatalf("Wait() = %v", err)
+	}
+	if got := len(g.Results()); got != 2 {
+		t.Errorf("Results() len = %d, want 2", got)
+	}
+}
+
+func TestGroup_WithRate_SpacesStarts(t *testing.T) {
+	const n = 5
+	g := New[int](context.Background(), WithRate[int](100, 1))
+	start := time.Now()
+	for i := 0; i < n; i++ {
+		g.Go(intTask(i, nil))
+	}
+	if err := g.Wait(); err != nil {
+		t.Fatalf("Wait() = %v", err)
+	}
+	// One token of burst, then one every 10ms: 5 tasks need at least ~40ms.
+	if elapsed := time.Since(start); elapsed < 35*time.Millisecond {
+		t.Errorf("elapsed = %v, want >= ~40ms under 100/s", elapsed)
+	}
+}
+
+func TestGroup_WithRate_DropsNonPositive(t *testing.T) {
+	for _, r := range []float64{0, -1} {
+		g := New[int](context.Background(), WithRate[int](r, 1))
+		if g.rate != nil {
+			t.Errorf("WithRate(%v) should be a no-op", r)
+		}
+		_ = g.Wait()
+	}
+}
+
+func TestGroup_WithLimit_GoRunsAfterCancelWhileQueued(t *testing.T) {
+	// Regression: under WithLimit alone, Go keeps its original semantics —
+	// every submitted task runs, even if the ctx ends while it is queued.
+	ctx, cancel := context.WithCancel(context.Background())
+	g := New[int](ctx, WithLimit[int](1))
+	release := make(chan struct{})
+	g.Go(func(context.Context) (int, error) {
+		<-release
+		return 1, nil
+	})
+
+	var ran atomic.Bool
+	submitted := make(chan struct{})
+	go func() {
+		g.Go(func(ctx context.Context) (int, error) {
+			ran.Store(true)
+			return 2, ctx.Err()
+		})
+		close(submitted)
+	}()
+	time.Sleep(10 * time.Millisecond)
+	cancel()
+	select {
+	case <-submitted:
+		t.Fatal("Go() returned before a slot was freed")
+	case <-time.After(20 * time.Millisecond):
+	}
+	close(release)
+	<-submitted
+
+	err := g.Wait()
+	if !ran.Load() {
+		t.Error("queued Go task should still run after cancellation")
+	}
+	if !errors.Is(err, context.Canceled) {
+		t.Errorf("Wait() = %v, want the task's context.Canceled", err)
+	}
+}
+
+func TestGroup_GoWeighted_CancelWhileQueued(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	g := New[int](ctx, WithLimit[int](1))
+	release := make(chan struct{})
+	g.Go(func(context.Context) (int, error) {
+		<-release
+		return 1, nil
+	})
+
+	var ran atomic.Bool
+	submitted := make(chan struct{})
+	go func() {
+		g.GoWeighted(1, func(context.Context) (int, error) { // cancelled while queued; must not run
+			ran.Store(true)
+			return 2, nil
+		})
+		close(submitted)
+	}()
+	time.Sleep(10 * time.Millisecond)
+	cancel()
+	select {
+	case <-submitted:
+	case <-time.After(time.Second):
+		t.Fatal("Go() did not return after ctx cancellation")
+	}
+	close(release)
+
+	err := g.Wait()
+	if !errors.Is(err, context.Canceled) {
+		t.Errorf("Wait() = %v, want context.Canceled", err)
+	}
+	if ran.Load() {
+		t.Error("queued task should not run after cancellation")
+	}
+}
+
+func TestGroup_WithRate_CancelOnErrorSkipsPending(t *testing.T) {
+	g := New[int](context.Background(),
+		WithRate[int](1, 1), // the second token is 1s away
+		WithCancelOnError[int](),
+	)
+	g.Go(intTask(0, errFirst))
+	time.Sleep(10 * time.Millisecond)
+
+	var ran atomic.Bool
+	start := time.Now()
+	g.Go(func(context.Context) (int, error) {
+		ran.Store(true)
+		return 0, nil
+	})
+	if elapsed := time.Since(start); elapsed > 500*time.Millisecond {
+		t.Errorf("Go() blocked %v after group cancellation", elapsed)
+	}
+	if err := g.Wait(); !errors.Is(err, errFirst) {
+		t.Errorf("Wait() = %v, want first error", err)
+	}
+	if ran.Load() {
+		t.Error("task waiting for a token should not run after cancel-on-error")
+	}
+}
@@ -0,0 +1,142 @@
+package async
+
+import (
+	"container/list"
+	"context"
+	"sync"
+	"time"
+)
+
+// semaphore 是一个带权重、FIFO、可被 ctx 取消的信号量,供 [Group] 的
+// 并发上限与 [Group.GoWeighted] 使用。等待者严格按到达顺序放行:队首的
+// 大权重请求不会被后到的小权重请求饿死。
+type semaphore struct {
+	size    int
+	mu      sync.Mutex
+	cur     int
+	waiters list.List // 元素为 *waiter
+}
+
+type waiter struct {
+	n     int
+	ready chan struct{} // 获得许可时被关闭
+}
+
+func newSemaphore(size int) *semaphore {
+	return &semaphore{size: size}
+}
+
+// acquire 阻塞直到获得 n 个许可或 ctx 结束。n 超过容量时按容量计,否则
+// 它永远无法被满足。ctx 结束时返回 ctx.Err(),且不占用任何许可。
+func (s *semaphore) acquire(ctx context.Context, n int) error {
+	n = min(n, s.size)
+	s.mu.Lock()
+	if s.size-s.cur >= n && s.waiters.Len() == 0 {
+		s.cur += n
+		s.mu.Unlock()
+		return nil
+	}
+	w := &waiter{n: n, ready: make(chan struct{})}
+	elem := s.waiters.PushBack(w)
+	s.mu.Unlock()
+
+	select {
+	case <-w.ready:
+		return nil
+	case <-ctx.Done():
+		s.mu.Lock()
+		select {
+		case <-w.ready:
+			// 取消与放行竞争且放行先到:退回许可,由后续等待者接手。
+			s.cur -= n
+			s.notifyLocked()
+		default:
+			isFront := s.waiters.Front() == elem
+			s.waiters.Remove(elem)
+			// 队首离开后,其后较小的请求可能已经可以满足。
+			if isFront && s.size > s.cur {
+				s.notifyLocked()
+			}
+		}
+		s.mu.Unlock()
+		return ctx.Err()
+	}
+}
+
+// release 归还 n 个许可并唤醒可被满足的等待者。n 须与 acquire 时一致。
+func (s *semaphore) release(n int) {
+	n = min(n, s.size)
+	s.mu.Lock()
+	s.cur -= n
+	s.notifyLocked()
+	s.mu.Unlock()
+}
+
+// notifyLocked 按 FIFO 放行队首起所有可被满足的等待者。调用方须持锁。
+func (s *semaphore) notifyLocked() {
+	for {
+		front := s.waiters.Front()
+		if front == nil {
+			return
+		}
+		w := front.Value.(*waiter)
+		if s.size-s.cur < w.n {
+			return
+		}
+		s.cur += w.n
+		s.waiters.Remove(front)
+		close(w.ready)
+	}
+}
+
+// tokenBucket 是一个按预约放行的令牌桶:每次 wait 预先扣除一枚令牌(可
+// 扣成负数),再睡眠到该令牌"到账"的时刻。这样并发等待者按到达顺序排开,
+// 而无需轮询。ctx 中途结束时退还令牌。
+type tokenBucket struct {
+	mu     sync.Mutex
+	rate   float64 // 每秒补充的令牌数
+	burst  float64
+	tokens float64
+	last   time.Time
+	now    func() time.Time
+}
+
+func newTokenBucket(perSecond float64, burst int) *tokenBucket {
+	if burst < 1 {
+		burst = 1
+	}
+	return &tokenBucket{
+		rate:   perSecond,
+		burst:  float64(burst),
+		tokens: float64(burst),
+		now:    time.Now,
+	}
+}
+
+// wait 阻塞直到取得一枚令牌或 ctx 结束。ctx 结束时返回 ctx.Err()。
+func (b *tokenBucket) wait(ctx context.Context) error {
+	b.mu.Lock()
+	now := b.now()
+	if !b.last.IsZero() {
+		b.tokens = min(b.burst, b.tokens+now.Sub(b.last).Seconds()*b.rate)
+	}
+	b.last = now
+	b.tokens--
+	deficit := -b.tokens
+	b.mu.Unlock()
+
+	if deficit <= 0 {
+		return nil
+	}
+	timer := time.NewTimer(time.Duration(deficit / b.rate * float64(time.Second)))
+	defer timer.Stop()
+	select {
+	case <-timer.C:
+		return nil
+	case <-ctx.Done():
+		b.mu.Lock()
+		b.tokens++
+		b.mu.Unlock()
+		return ctx.Err()
+	}
+}
@@ -0,0 +1,103 @@
+package async
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+)
+
+func TestSemaphore_FIFO(t *testing.T) {
+	s := newSemaphore(3)
+	ctx := context.Background()
+	if err := s.acquire(ctx, 2); err != nil {
+		t.Fatal(err)
+	}
+
+	big := make(chan struct{})
+	go func() {
+		_ = s.acquire(ctx, 3) // heavy waiter at the front
+		close(big)
+	}()
+	time.Sleep(5 * time.Millisecond)
+
+	small := make(chan struct{})
+	go func() {
+		_ = s.acquire(ctx, 1) // one slot is free, but it must queue behind the heavy waiter
+		close(small)
+	}()
+	time.Sleep(5 * time.Millisecond)
+
+	select {
+	case <-small:
+		t.Fatal("small waiter overtook the queued big waiter")
+	default:
+	}
+	s.release(2)
+	<-big
+	s.release(3)
+	<-small
+}
+
+func TestSemaphore_CancelledFrontUnblocksNext(t *testing.T) {
+	s := newSemaphore(2)
+	if err := s.acquire(context.Background(), 1); err != nil {
+		t.Fatal(err)
+	}
+	ctx, cancel := context.WithCancel(context.Background())
+	errc := make(chan error, 1)
+	go func() { errc <- s.acquire(ctx, 2) }()
+	time.Sleep(5 * time.Millisecond)
+
+	small := make(chan struct{})
+	go func() {
+		_ = s.acquire(context.Background(), 1)
+		close(small)
+	}()
+	time.Sleep(5 * time.Millisecond)
+
+	cancel()
+	if err := <-errc; !errors.Is(err, context.Canceled) {
+		t.Errorf("acquire() = %v, want Canceled", err)
+	}
+	select {
+	case <-small:
+	case <-time.After(time.Second):
+		t.Fatal("waiter behind a cancelled front was not released")
+	}
+}
+
+func TestTokenBucket_BurstThenRate(t *testing.T) {
+	now := time.Unix(0, 0)
+	b := newTokenBucket(10, 2)
+	b.now = func() time.Time { return now }
+	ctx := context.Background()
+
+	for i := 0; i < 2; i++ {
+		if err := b.wait(ctx); err != nil {
+			t.Fatalf("burst wait %d = %v", i, err)
+		}
+	}
+	now = now.Add(100 * time.Millisecond) // refills one token
+	if err := b.wait(ctx); err != nil {
+		t.Fatalf("refilled wait = %v", err)
+	}
+	if b.tokens != 0 {
+		t.Errorf("tokens = %v, want 0", b.tokens)
+	}
+}
+
+func TestTokenBucket_CancelRefunds(t *testing.T) {
+	b := newTokenBucket(0.001, 1)
+	if err := b.wait(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Millisecond)
+	defer cancel()
+	if err := b.wait(ctx); !errors.Is(err, context.DeadlineExceeded) {
+		t.Errorf("wait() = %v, want DeadlineExceeded", err)
+	}
+	if b.tokens > 0.01 || b.tokens < -0.01 {
+		t.Errorf("tokens = %v, want refunded to ~0", b.tokens)
+	}
+}
@@ -0,0 +1,144 @@
+package async
+
+import (
+	"context"
+	"errors"
+	"sync"
+)
+
+// SingleFlight 对同一 key 的并发调用做合并:同一时刻每个 key 至多一次在途
+// 调用,其余调用方等待并共享其结果。适用于缓存回源、token 刷新、配置重载
+// 等"只需一次在途"的场景。零值可直接使用;不可复制。
+//
+// 与 golang.org/x/sync/singleflight 的区别:
+//
+//   - 等待可被取消:某个调用方的 ctx 结束时,它立即带 ctx.Err() 返回,但
+//     共享调用继续为其余等待者运行;只有当所有等待者都离开时,共享调用的
+//     context 才被取消,且该 key 被释放,下一次调用会重新发起。
+//   - panic 不会在调用方重新抛出,而是以 [*PanicError] 交付给每个等待者。
+type SingleFlight[K comparable, V any] struct {
+	mu    sync.Mutex
+	calls map[K]*flight[V]
+}
+
+// flight 是一次在途的共享调用。val/err 在 done 关闭前写入,之后只读。
+type flight[V any] struct {
+	cancel  context.CancelFunc
+	done    chan struct{}
+	waiters int // 受 SingleFlight.mu 保护
+
+	val V
+	err error
+}
+
+// Do 以 key 执行 fn 并返回其结果。若该 key 已有在途调用,Do 不再执行 fn,
+// 而是等待并共享那次调用的结果。
+//
+// fn 收到的 context 继承首个调用方 ctx 的值,但不随其取消;它仅在所有
+// 等待者都因 ctx 结束而离开时被取消。调用方 ctx 结束时 Do 返回 ctx.Err()。
+// fn panic 时,每个等待者都收到同一个 [*PanicError]。nil fn 返回错误;
+// nil ctx 视作 context.Background()。
+func (s *SingleFlight[K, V]) Do(ctx context.Context, key K, fn Task[V]) (V, error) {
+	var zero V
+	if fn == nil {
+		return zero, errors.New("async.SingleFlight: nil task")
+	}
+	if ctx == nil {
+		ctx = context.Background()
+	}
+	if err := ctx.Err(); err != nil {
+		return zero, err
+	}
+	return s.await(ctx, key, s.join(ctx, key, fn))
+}
+
+// DoChan 与 [SingleFlight.Do] 语义相同,但立即返回一个 channel,结果(含
+// ctx 结束时的 ctx.Err())在就绪时以单个 [Result] 送达。channel 带缓冲,
+// 调用方不读取也不会泄漏 goroutine。
+func (s *SingleFlight[K, V]) DoChan(ctx context.Context, key K, fn Task[V]) <-chan Result[V] {
+	ch := make(chan Result[V], 1)
+	if fn == nil {
+		ch <- Result[V]{Err: errors.New("async.SingleFlight: nil task")}
+		return ch
+	}
+	if ctx == nil {
+		ctx = context.Background()
+	}
+	if err := ctx.Err(); err != nil {
+		ch <- Result[V]{Err: err}
+		return ch
+	}
+	f := s.join(ctx, key, fn)
+	go func() {
+		v, err := s.await(ctx, key, f)
+		ch <- Result[V]{Value: v, Err: err}
+	}()
+	return ch
+}
+
+// Forget 让 key 的下一次调用重新执行 fn,而不是加入当前在途调用。已在
+// 等待的调用方不受影响,仍会收到当前调用的结果。
+func (s *SingleFlight[K, V]) Forget(key K) {
+	s.mu.Lock()
+	delete(s.calls, key)
+	s.mu.Unlock()
+}
+
+// join 加入 key 的在途调用,不存在时发起一次新调用。
+func (s *SingleFlight[K, V]) join(ctx context.Context, key K, fn Task[V]) *flight[V] {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if f, ok := s.calls[key]; ok {
+		f.waiters++
+		return f
+	}
+	if s.calls == nil {
+		s.calls = make(map[K]*flight[V])
+	}
+
+	callCtx, cancel := context.WithCancel(context.WithoutCancel(ctx))
+	f := &flight[V]{cancel: cancel, done: make(chan struct{}), waiters: 1}
+	s.calls[key] = f
+
+	go func() {
+		v, err := recoverTask(fn)(callCtx)
+		s.mu.Lock()
+		if s.calls[key] == f {
+			delete(s.calls, key)
+		}
+		s.mu.Unlock()
+		f.val, f.err = v, err
+		cancel()
+		close(f.done)
+	}()
+	return f
+}
+
+// await 等待 f 完成或 ctx 结束。最后一个离开的等待者取消共享调用并释放
+// key,使后续调用不会加入一个已被放弃的调用。
+func (s *SingleFlight[K, V]) await(ctx context.Context, key K, f *flight[V]) (V, error) {
+	select {
+	case <-f.done:
+		return f.val, f.err
+	case <-ctx.Done():
+	}
+
+	// 完成与取消同时就绪时,优先交付结果。
+	select {
+	case <-f.done:
+		return f.val, f.err
+	default:
+	}
+
+	s.mu.Lock()
+	f.waiters--
+	if f.waiters == 0 {
+		f.cancel()
+		if s.calls[key] == f {
+			delete(s.calls, key)
+		}
+	}
+	s.mu.Unlock()
+	var zero V
+	return zero, ctx.Err()
+}
@@ -0,0 +1,249 @@
+package async
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestSingleFlight_DedupesConcurrentCalls(t *testing.T) {
+	var sf SingleFlight[string, int]
+	var calls int32
+	release := make(chan struct{})
+	fn := func(context.Context) (int, error) {
+		atomic.AddInt32(&calls, 1)
+		<-release
+		return 42, nil
+	}
+
+	const n = 10
+	var wg sync.WaitGroup
+	results := make([]int, n)
+	for i := 0; i < n; i++ {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			v, err := sf.Do(context.Background(), "k", fn)
+			if err != nil {
+				t.Errorf("Do() err = %v", err)
+			}
+			results[i] = v
+		}(i)
+	}
+	time.Sleep(10 * time.Millisecond)
+	close(release)
+	wg.Wait()
+
+	if got := atomic.LoadInt32(&calls); got != 1 {
+		t.Errorf("fn called %d times, want 1", got)
+	}
+	for i, v := range results {
+		if v != 42 {
+			t.Errorf("results[%d] = %d, want 42", i, v)
+		}
+	}
+}
+
+func TestSingleFlight_DistinctKeysRunIndependently(t *testing.T) {
+	var sf SingleFlight[int, int]
+	a, _ := sf.Do(context.Background(), 1, intTask(1, nil))
+	b, _ := sf.Do(context.Background(), 2, intTask(2, nil))
+	if a != 1 || b != 2 {
+		t.Errorf("Do() = %d, %d; want 1, 2", a, b)
+	}
+}
+
+func TestSingleFlight_SequentialCallsRerun(t *testing.T) {
+	var sf SingleFlight[string, int]
+	var calls int32
+	fn := func(context.Context) (int, error) { return int(atomic.AddInt32(&calls, 1)), nil }
+	first, _ := sf.Do(context.Background(), "k", fn)
+	second, _ := sf.Do(context.Background(), "k", fn)
+	if first != 1 || second != 2 {
+		t.Errorf("Do() = %d, %d; want 1, 2 (completed calls are not cached)", first, second)
+	}
+}
+
+func TestSingleFlight_ErrorShared(t *testing.T) {
+	var sf SingleFlight[string, int]
+	_, err := sf.Do(context.Background(), "k", intTask(0, errOne))
+	if !errors.Is(err, errOne) {
+		t.Errorf("Do() err = %v, want errOne", err)
+	}
+}
+
+func TestSingleFlight_PanicDeliveredToAllWaiters(t *testing.T) {
+	var sf SingleFlight[string, int]
+	release := make(chan struct{})
+	fn := func(context.Context) (int, error) {
+		<-release
+		panic("kaboom")
+	}
+
+	const n = 3
+	errs := make(chan error, n)
+	for i := 0; i < n; i++ {
+		go func() {
+			_, err := sf.Do(context.Background(), "k", fn)
+			errs <- err
+		}()
+	}
+	time.Sleep(10 * time.Millisecond)
+	close(release)
+
+	for i := 0; i < n; i++ {
+		err := <-errs
+		var pe *PanicError
+		if !errors.As(err, &pe) {
+			t.Fatalf("waiter %d err = %v, want *PanicError", i, err)
+		}
+		if pe.Value() != "kaboom" {
+			t.Errorf("PanicError.Value() = %v, want kaboom", pe.Value())
+		}
+	}
+}
+
+func TestSingleFlight_WaiterCancelDoesNotCancelShared(t *testing.T) {
+	var sf SingleFlight[string, int]
+	release := make(chan struct{})
+	var sharedErr atomic.Value
+	fn := func(ctx context.Context) (int, error) {
+		<-release
+		if err := ctx.Err(); err != nil {
+			sharedErr.Store(err)
+		}
+		return 7, nil
+	}
+
+	stayer := make(chan Result[int], 1)
+	go func() {
+		v, err := sf.Do(context.Background(), "k", fn)
+		stayer <- Result[int]{Value: v, Err: err}
+	}()
+	time.Sleep(5 * time.Millisecond)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	leaver := sf.DoChan(ctx, "k", fn)
+	cancel()
+	if r := <-leaver; !errors.Is(r.Err, context.Canceled) {
+		t.Errorf("leaving waiter err = %v, want Canceled", r.Err)
+	}
+
+	close(release)
+	r := <-stayer
+	if r.Err != nil || r.Value != 7 {
+		t.Errorf("remaining waiter = %+v, want 7/nil", r)
+	}
+	if err := sharedErr.Load(); err != nil {
+		t.Errorf("shared call ctx cancelled by a leaving waiter: %v", err)
+	}
+}
+
+func TestSingleFlight_AllWaitersLeaveCancelsShared(t *testing.T) {
+	var sf SingleFlight[string, int]
+	cancelled := make(chan struct{})
+	fn := func(ctx context.Context) (int, error) {
+		<-ctx.Done()
+		close(cancelled)
+		return 0, ctx.Err()
+	}
+
+	ctx1, cancel1 := context.WithCancel(context.Background())
+	ctx2, cancel2 := context.WithCancel(context.Background())
+	ch1 := sf.DoChan(ctx1, "k", fn)
+	ch2 := sf.DoChan(ctx2, "k", fn)
+
+	cancel1()
+	<-ch1
+	select {
+	case <-cancelled:
+		t.Fatal("shared call cancelled while a waiter remains")
+	case <-time.After(10 * time.Millisecond):
+	}
+
+	cancel2()
+	<-ch2
+	select {
+	case <-cancelled:
+	case <-time.After(time.Second):
+		t.Fatal("shared call not cancelled after all waiters left")
+	}
+
+	// The abandoned key is released: a new call runs fn afresh.
+	v, err := sf.Do(context.Background(), "k", intTask(9, nil))
+	if err != nil || v != 9 {
+		t.Errorf("Do() after abandonment = %d, %v; want 9, nil", v, err)
+	}
+}
+
+func TestSingleFlight_SharedCtxKeepsValues(t *testing.T) {
+	type key struct{}
+	var sf SingleFlight[string, string]
+	ctx := context.WithValue(context.Background(), key{}, "v")
+	got, err := sf.Do(ctx, "k", func(ctx context.Context) (string, error) {
+		s, _ := ctx.Value(key{}).(string)
+		return s, nil
+	})
+	if err != nil || got != "v" {
+		t.Errorf("Do() = %q, %v; want value from caller ctx", got, err)
+	}
+}
+
+func TestSingleFlight_Forget(t *testing.T) {
+	var sf SingleFlight[string, int]
+	release := make(chan struct{})
+	var calls int32
+	fn := func(context.Context) (int, error) {
+		n := atomic.AddInt32(&calls, 1)
+		if n == 1 {
+			<-release
+		}
+		return int(n), nil
+	}
+
+	first := sf.DoChan(context.Background(), "k", fn)
+	time.Sleep(5 * time.Millisecond)
+	sf.Forget("k")
+
+	v, err := sf.Do(context.Background(), "k", fn)
+	if err != nil || v != 2 {
+		t.Errorf("Do() after Forget = %d, %v; want a fresh call (2)", v, err)
+	}
+	close(release)
+	if r := <-first; r.Value != 1 {
+		t.Errorf("original waiter = %d, want 1", r.Value)
+	}
+}
+
+func TestSingleFlight_NilAndDoneCtx(t *testing.T) {
+	var sf SingleFlight[string, int]
+	if _, err := sf.Do(context.Background(), "k", nil); err == nil {
+		t.Error("Do(nil fn) should error")
+	}
+	if r := <-sf.DoChan(context.Background(), "k", nil); r.Err == nil {
+		t.Error("DoChan(nil fn) should error")
+	}
+	if v, err := sf.Do(nil, "k", intTask(1, nil)); err != nil || v != 1 {
+		t.Errorf("Do(nil ctx) = (%v, %v), want (1, nil)", v, err)
+	}
+	if r := <-sf.DoChan(nil, "k", intTask(2, nil)); r.Err != nil || r.Value != 2 {
+		t.Errorf("DoChan(nil ctx) = %+v, want value 2", r)
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	var ran atomic.Bool
+	_, err := sf.Do(ctx, "k", func(context.Context) (int, error) {
+		ran.Store(true)
+		return 0, nil
+	})
+	if !errors.Is(err, context.Canceled) {
+		t.Errorf("Do(done ctx) err = %v, want Canceled", err)
+	}
+	if ran.Load() {
+		t.Error("fn should not run for an already-done ctx")
+	}
+}
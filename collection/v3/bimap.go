@@ -0,0 +1,170 @@
+package collection
+
+import (
+	"errors"
+	"fmt"
+)
+
+// ErrBiMapConflict 在 [BiMap.Put] 的 key 或值已与其他值或 key 关联时返回。
+var ErrBiMapConflict = errors.New("collection: bimap conflict")
+
+// BiMap 是一个双向一一映射:每个 key 恰对应一个值,每个值也恰对应一个 key,两个方向的查找
+// 都是 O(1),用于 ID ↔ 名称一类的关系。
+//
+// [BiMap.Put] 拒绝破坏一一对应的写入并返回 [ErrBiMapConflict];确实要覆盖时使用
+// [BiMap.ForcePut]。[BiMap.Inverse] 返回共享底层存储的反向视图,对任一方向的修改在另一方向
+// 立即可见。
+//
+// 零值是可用的空 BiMap。它不是 goroutine 安全的。
+type BiMap[K, V comparable] struct {
+	fwd map[K]V
+	inv map[V]K
+}
+
+// NewBiMap 创建一个空 BiMap。
+func NewBiMap[K, V comparable]() *BiMap[K, V] {
+	return &BiMap[K, V]{fwd: make(map[K]V), inv: make(map[V]K)}
+}
+
+// Put 关联 key 与 val。key 已关联到其他值、或 val 已被其他 key 关联时返回
+// [ErrBiMapConflict] 且 BiMap 不变;(key, val) 已存在时是 no-op。
+func (b *BiMap[K, V]) Put(key K, val V) error {
+	if cur, ok := b.fwd[key]; ok {
+		if cur == val {
+			return nil
+		}
+		return fmt.Errorf("%w: key %v is already mapped to %v", ErrBiMapConflict, key, cur)
+	}
+	if cur, ok := b.inv[val]; ok {
+		return fmt.Errorf("%w: value %v is already mapped from %v", ErrBiMapConflict, val, cur)
+	}
+	b.init()
+	b.fwd[key] = val
+	b.inv[val] = key
+	return nil
+}
+
+// ForcePut 关联 key 与 val,先删除 key 原有的值与 val 原有的 key,使一一对应保持成立。
+func (b *BiMap[K, V]) ForcePut(key K, val V) {
+	b.Delete(key)
+	b.DeleteValue(val)
+	b.init()
+	b.fwd[key] = val
+	b.inv[val] = key
+}
+
+// Get 返回 key 关联的值。key 不存在时返回 (zero, false)。
+func (b *BiMap[K, V]) Get(key K) (V, bool) {
+	v, ok := b.fwd[key]
+	return v, ok
+}
+
+// GetKey 返回关联到 val 的 key。val 不存在时返回 (zero, false)。
+func (b *BiMap[K, V]) GetKey(val V) (K, bool) {
+	k, ok := b.inv[val]
+	return k, ok
+}
+
+// Contains 报告 key 是否存在。
+func (b *BiMap[K, V]) Contains(key K) bool {
+	_, ok := b.fwd[key]
+	return ok
+}
+
+// ContainsValue 报告 val 是否存在。
+func (b *BiMap[K, V]) ContainsValue(val V) bool {
+	_, ok := b.inv[val]
+	return ok
+}
+
+// Delete 删除 key 及其值,并返回该值。key 不存在时返回 (zero, false)。
+func (b *BiMap[K, V]) Delete(key K) (V, bool) {
+	v, ok := b.fwd[key]
+	if ok {
+		delete(b.fwd, key)
+		delete(b.inv, v)
+	}
+	return v, ok
+}
+
+// DeleteValue 删除 val 及其 key,并返回该 key。val 不存在时返回 (zero, false)。
+func (b *BiMap[K, V]) DeleteValue(val V) (K, bool) {
+	k, ok := b.inv[val]
+	if ok {
+		delete(b.inv, val)
+		delete(b.fwd, k)
+	}
+	return k, ok
+}
+
+// Inverse 返回 value → key 方向的视图。视图与 b 共享存储:通过任一方修改,另一方立即可见;
+// b.Inverse().Inverse() 等价于 b。
+func (b *BiMap[K, V]) Inverse() *BiMap[V, K] {
+	b.init()
+	return &BiMap[V, K]{fwd: b.inv, inv: b.fwd}
+}
+
+// Len 返回关联的个数。
+func (b *BiMap[K, V]) Len() int { return len(b.fwd) }
+
+// IsEmpty 报告 BiMap 是否为空。
+func (b *BiMap[K, V]) IsEmpty() bool { return len(b.fwd) == 0 }
+
+// Clear 删除所有关联。共享存储的 Inverse 视图也随之清空。
+func (b *BiMap[K, V]) Clear() {
+	clear(b.fwd)
+	clear(b.inv)
+}
+
+// Keys 返回所有 key 的 slice,顺序不确定。
+func (b *BiMap[K, V]) Keys() []K {
+	out := make([]K, 0, len(b.fwd))
+	for k := range b.fwd {
+		out = append(out, k)
+	}
+	return out
+}
+
+// Values 返回所有值的 slice,顺序不确定。
+func (b *BiMap[K, V]) Values() []V {
+	out := make([]V, 0, len(b.inv))
+	for v := range b.inv {
+		out = append(out, v)
+	}
+	return out
+}
+
+// KeySet 以新的 [Set] 返回所有 key。
+func (b *BiMap[K, V]) KeySet() Set[K] {
+	s := NewSetWithCap[K](len(b.fwd))
+	for k := range b.fwd {
+		s.Add(k)
+	}
+	return s
+}
+
+// ValueSet 以新的 [Set] 返回所有值。
+func (b *BiMap[K, V]) ValueSet() Set[V] {
+	s := NewSetWithCap[V](len(b.inv))
+	for v := range b.inv {
+		s.Add(v)
+	}
+	return s
+}
+
+// Clone 返回 BiMap 的独立副本(不与 b 或其 Inverse 视图共享存储)。
+func (b *BiMap[K, V]) Clone() *BiMap[K, V] {
+	out := &BiMap[K, V]{fwd: make(map[K]V, len(b.fwd)), inv: make(map[V]K, len(b.inv))}
+	for k, v := range b.fwd {
+		out.fwd[k] = v
+		out.inv[v] = k
+	}
+	return out
+}
+
+func (b *BiMap[K, V]) init() {
+	if b.fwd == nil {
+		b.fwd = make(map[K]V)
+		b.inv = make(map[V]K)
+	}
+}
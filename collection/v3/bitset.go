@@ -0,0 +1,334 @@
+package collection
+
+import (
+	"bytes"
+	"encoding/base64"
+	"encoding/binary"
+	"encoding/json"
+	"fmt"
+	"math/bits"
+)
+
+// BitSet 是一个存储非负整数的 set,每个可能的元素占 1 位。对密集的小整数 ID(权限位、
+// shard ID)而言,它比基于 map 的 [Set] 小一到两个数量级,集合代数也以 64 位字为单位进行。
+// 内存由最大元素决定而不是元素个数,稀疏的大整数请使用 [Set]。
+//
+// 方法名与 [Set] 对应:Add/Remove/Contains,Union/Intersect/Subtract/SymmetricDifference
+// 返回新 BitSet、不修改接收者;带 With 后缀的版本(UnionWith、...)就地修改接收者并返回它,
+// 避免分配。Len 是置位数(population count)。
+//
+// 零值是可用的空 BitSet。它不是 goroutine 安全的。
+type BitSet struct {
+	words []uint64
+}
+
+const bitSetMagic = "BIT1"
+
+// maxBitSetJSONValue 是 [BitSet.UnmarshalJSON] 的数组形式接受的最大元素。数组来自不可信的
+// 输入,一个巨大的值就会分配与之成正比的内存;此上限对应至多 2 MiB 的位图。
+const maxBitSetJSONValue = 1<<24 - 1
+
+// NewBitSet 创建一个 BitSet,可选地预先填入 values。
+func NewBitSet(values ...uint) *BitSet {
+	b := &BitSet{}
+	return b.Add(values...)
+}
+
+// NewBitSetWithCap 创建一个为 [0, n) 内的元素预分配空间的空 BitSet。
+func NewBitSetWithCap(n uint) *BitSet {
+	return &BitSet{words: make([]uint64, 0, (n+63)/64)}
+}
+
+// Add 将 values 加入 BitSet。重复值是 no-op。它返回接收者,以便与代数方法链式调用。
+//
+// 位图会增长到能容纳最大的值,内存约为 max(values)/8 字节:加入 1<<32 需要 512 MiB,接近
+// [math.MaxUint] 的值会耗尽内存。不要把未经检查的外部输入直接传给 Add。
+func (b *BitSet) Add(values ...uint) *BitSet {
+	for _, v := range values {
+		w := v / 64
+		if w >= uint(len(b.words)) {
+			b.words = append(b.words, make([]uint64, w+1-uint(len(b.words)))...)
+		}
+		b.words[w] |= 1 << (v % 64)
+	}
+	return b
+}
+
+// Remove 从 BitSet 中删除 v。若 v 不存在则是 no-op。
+func (b *BitSet) Remove(v uint) {
+	if w := v / 64; w < uint(len(b.words)) {
+		b.words[w] &^= 1 << (v % 64)
+	}
+}
+
+// Contains 报告 v 是否存在于 BitSet 中。
+func (b *BitSet) Contains(v uint) bool {
+	w := v / 64
+	return w < uint(len(b.words)) && b.words[w]&(1<<(v%64)) != 0
+}
+
+// Len 返回元素数量(置位数)。它是 O(字数),不是 O(1)。
+func (b *BitSet) Len() int {
+	n := 0
+	for _, w := range b.words {
+		n += bits.OnesCount64(w)
+	}
+	return n
+}
+
+// PopCount 返回置位数,与 [BitSet.Len] 相同,沿用位运算中的惯用名。
+func (b *BitSet) PopCount() int { return b.Len() }
+
+// IsEmpty 报告 BitSet 是否没有元素。
+func (b *BitSet) IsEmpty() bool {
+	for _, w := range b.words {
+		if w != 0 {
+			return false
+		}
+	}
+	return true
+}
+
+// Clear 移除所有元素。内部存储保留以便复用。
+func (b *BitSet) Clear() { b.words = b.words[:0] }
+
+// NextSet 返回不小于 i 的最小元素。没有这样的元素时返回 ok == false。
+//
+//	for i, ok := b.NextSet(0); ok; i, ok = b.NextSet(i + 1) { ... }
+func (b *BitSet) NextSet(i uint) (uint, bool) {
+	w := i / 64
+	if w >= uint(len(b.words)) {
+		return 0, false
+	}
+	if word := b.words[w] >> (i % 64); word != 0 {
+		return i + uint(bits.TrailingZeros64(word)), true
+	}
+	for w++; w < uint(len(b.words)); w++ {
+		if b.words[w] != 0 {
+			return w*64 + uint(bits.TrailingZeros64(b.words[w])), true
+		}
+	}
+	return 0, false
+}
+
+// NextClear 返回不小于 i 的最小非元素,用于分配空闲 ID。
+func (b *BitSet) NextClear(i uint) uint {
+	w := i / 64
+	if w >= uint(len(b.words)) {
+		return i
+	}
+	if word := ^b.words[w] >> (i % 64); word != 0 {
+		return i + uint(bits.TrailingZeros64(word))
+	}
+	for w++; w < uint(len(b.words)); w++ {
+		if b.words[w] != ^uint64(0) {
+			return w*64 + uint(bits.TrailingZeros64(^b.words[w]))
+		}
+	}
+	return uint(len(b.words)) * 64
+}
+
+// Max 返回最大元素。BitSet 为空时返回 ok == false。
+func (b *BitSet) Max() (uint, bool) {
+	for w := len(b.words) - 1; w >= 0; w-- {
+		if b.words[w] != 0 {
+			return uint(w)*64 + 63 - uint(bits.LeadingZeros64(b.words[w])), true
+		}
+	}
+	return 0, false
+}
+
+// Values 以升序 slice 返回所有元素;返回的 slice 是独立的。
+func (b *BitSet) Values() []uint {
+	out := make([]uint, 0, b.Len())
+	for v := range b.All() {
+		out = append(out, v)
+	}
+	return out
+}
+
+// Clone 返回 BitSet 的独立副本。末尾的全零字不会被复制。
+func (b *BitSet) Clone() *BitSet {
+	return &BitSet{words: append([]uint64(nil), b.words[:b.used()]...)}
+}
+
+// Union 返回包含 b 和 other 所有元素的新 BitSet(b ∪ other)。
+func (b *BitSet) Union(other *BitSet) *BitSet { return b.Clone().UnionWith(other) }
+
+// Intersect 返回同时存在于 b 和 other 中的元素的新 BitSet(b ∩ other)。
+func (b *BitSet) Intersect(other *BitSet) *BitSet { return b.Clone().IntersectWith(other) }
+
+// Subtract 返回在 b 中但不在 other 中的元素的新 BitSet(b \ other)。
+func (b *BitSet) Subtract(other *BitSet) *BitSet { return b.Clone().SubtractWith(other) }
+
+// SymmetricDifference 返回恰好在 b 或 other 其中之一中的元素的新 BitSet(b △ other)。
+func (b *BitSet) SymmetricDifference(other *BitSet) *BitSet {
+	return b.Clone().SymmetricDifferenceWith(other)
+}
+
+// UnionWith 就地将 other 的元素加入 b,并返回 b。
+func (b *BitSet) UnionWith(other *BitSet) *BitSet {
+	n := other.used()
+	if n > len(b.words) {
+		b.words = append(b.words, make([]uint64, n-len(b.words))...)
+	}
+	for i, w := range other.words[:n] {
+		b.words[i] |= w
+	}
+	return b
+}
+
+// IntersectWith 就地删除 b 中不在 other 里的元素,并返回 b。
+func (b *BitSet) IntersectWith(other *BitSet) *BitSet {
+	n := min(len(b.words), len(other.words))
+	for i := range n {
+		b.words[i] &= other.words[i]
+	}
+	b.words = b.words[:n]
+	return b
+}
+
+// SubtractWith 就地删除 b 中也在 other 里的元素,并返回 b。
+func (b *BitSet) SubtractWith(other *BitSet) *BitSet {
+	n := min(len(b.words), len(other.words))
+	for i := range n {
+		b.words[i] &^= other.words[i]
+	}
+	return b
+}
+
+// SymmetricDifferenceWith 就地将 b 变为 b △ other,并返回 b。
+func (b *BitSet) SymmetricDifferenceWith(other *BitSet) *BitSet {
+	n := other.used()
+	if n > len(b.words) {
+		b.words = append(b.words, make([]uint64, n-len(b.words))...)
+	}
+	for i, w := range other.words[:n] {
+		b.words[i] ^= w
+	}
+	return b
+}
+
+// IsSubset 报告 b 的每个元素是否都在 other 中(b ⊆ other)。
+func (b *BitSet) IsSubset(other *BitSet) bool {
+	for i, w := range b.words {
+		var o uint64
+		if i < len(other.words) {
+			o = other.words[i]
+		}
+		if w&^o != 0 {
+			return false
+		}
+	}
+	return true
+}
+
+// IsSuperset 报告 other 的每个元素是否都在 b 中(b ⊇ other)。
+func (b *BitSet) IsSuperset(other *BitSet) bool { return other.IsSubset(b) }
+
+// IsDisjoint 报告 b 和 other 是否没有共同元素(b ∩ other = ∅)。
+func (b *BitSet) IsDisjoint(other *BitSet) bool {
+	n := min(len(b.words), len(other.words))
+	for i := range n {
+		if b.words[i]&other.words[i] != 0 {
+			return false
+		}
+	}
+	return true
+}
+
+// Equal 报告 b 和 other 是否包含相同元素。
+func (b *BitSet) Equal(other *BitSet) bool {
+	n := b.used()
+	if n != other.used() {
+		return false
+	}
+	for i := range n {
+		if b.words[i] != other.words[i] {
+			return false
+		}
+	}
+	return true
+}
+
+// MarshalBinary 将 BitSet 编码为字节序列(小端序的 64 位字,去掉末尾的全零字),实现
+// [encoding.BinaryMarshaler]。
+func (b *BitSet) MarshalBinary() ([]byte, error) {
+	n := b.used()
+	out := make([]byte, 0, len(bitSetMagic)+8*n)
+	out = append(out, bitSetMagic...)
+	for _, w := range b.words[:n] {
+		out = binary.LittleEndian.AppendUint64(out, w)
+	}
+	return out, nil
+}
+
+// UnmarshalBinary 从 [BitSet.MarshalBinary] 的输出还原 BitSet,替换原有内容,实现
+// [encoding.BinaryUnmarshaler]。数据损坏时返回错误且 b 不变。
+func (b *BitSet) UnmarshalBinary(data []byte) error {
+	r := newSketchReader("BitSet", bitSetMagic, data)
+	if r.err == nil && len(r.data)%8 != 0 {
+		return fmt.Errorf("collection: decode BitSet: length %d is not a multiple of 8", len(r.data))
+	}
+	words := make([]uint64, len(r.data)/8)
+	for i := range words {
+		words[i] = r.uint64()
+	}
+	if err := r.done(); err != nil {
+		return err
+	}
+	b.words = words
+	return nil
+}
+
+// MarshalJSON 将 BitSet 编码为 JSON 字符串,内容是 [BitSet.MarshalBinary] 输出的标准
+// base64 —— 密集的 ID 集合比数字数组紧凑得多。值接收者使非指针的结构体字段也按此编码。
+func (b BitSet) MarshalJSON() ([]byte, error) {
+	data, err := b.MarshalBinary()
+	if err != nil {
+		return nil, err
+	}
+	return json.Marshal(data)
+}
+
+// UnmarshalJSON 从 [BitSet.MarshalJSON] 的输出解码,替换原有内容。为便于手写配置,也接受
+// 元素的 JSON 数组,例如 [1, 3, 5];数组中的元素不得超过 16777215(1<<24 - 1),否则返回错误
+// 且 BitSet 不变。base64 形式的大小由数据长度决定,不受此限制。JSON null 不修改 BitSet。
+func (b *BitSet) UnmarshalJSON(data []byte) error {
+	data = bytes.TrimSpace(data)
+	switch {
+	case bytes.Equal(data, []byte("null")):
+		return nil
+	case len(data) > 0 && data[0] == '[':
+		var values []uint
+		if err := json.Unmarshal(data, &values); err != nil {
+			return err
+		}
+		for _, v := range values {
+			if v > maxBitSetJSONValue {
+				return fmt.Errorf("collection: decode BitSet: value %d exceeds limit %d", v, maxBitSetJSONValue)
+			}
+		}
+		b.Clear()
+		b.Add(values...)
+		return nil
+	}
+	var s string
+	if err := json.Unmarshal(data, &s); err != nil {
+		return fmt.Errorf("collection: cannot unmarshal %s into BitSet", data)
+	}
+	raw, err := base64.StdEncoding.DecodeString(s)
+	if err != nil {
+		return fmt.Errorf("collection: decode BitSet: %w", err)
+	}
+	return b.UnmarshalBinary(raw)
+}
+
+// used 返回去掉末尾全零字后的字数。
+func (b *BitSet) used() int {
+	n := len(b.words)
+	for n > 0 && b.words[n-1] == 0 {
+		n--
+	}
+	return n
+}
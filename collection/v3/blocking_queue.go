@@ -0,0 +1,197 @@
+package collection
+
+import (
+	"context"
+	"errors"
+	"iter"
+	"sync"
+)
+
+// ErrQueueClosed 在 [BlockingQueue] 关闭后由 Offer 返回,以及在关闭且已排空后由 Take 返回。
+var ErrQueueClosed = errors.New("collection: queue closed")
+
+// BlockingQueue 是一个并发安全、可选有界的 FIFO queue,用作 goroutine 之间的工作队列。
+//
+// [BlockingQueue.Take] 在 queue 为空时阻塞,[BlockingQueue.Offer] 在 queue 已满时阻塞;
+// 两者都监听 ctx,ctx 结束时返回 ctx.Err()。与 channel 相比,它的 capacity 可以无界,
+// 可以查看长度,且 [BlockingQueue.Close] 之后 Offer 返回 [ErrQueueClosed] 而不是 panic。
+//
+// 关闭语义:Close 之后不再接受新元素,但已入队的元素仍可被 Take 取走;queue 排空后,Take
+// 返回 [ErrQueueClosed],消费者据此退出。
+//
+// 零值不可用,请使用 [NewBlockingQueue] 构造。
+type BlockingQueue[T any] struct {
+	mu       sync.Mutex
+	q        *Queue[T]
+	capacity int // <= 0 表示无界
+	closed   bool
+
+	// notEmpty/notFull 在状态变化时被关闭并替换,以广播唤醒等待者;只有存在等待者时才替换,
+	// 使无竞争路径不分配。
+	notEmpty, notFull chan struct{}
+	takers, offerers  int
+}
+
+// NewBlockingQueue 创建一个最多容纳 capacity 个元素的空 blocking queue。非正的 capacity
+// 表示无界:Offer 永不因满而阻塞。
+func NewBlockingQueue[T any](capacity int) *BlockingQueue[T] {
+	return &BlockingQueue[T]{
+		q:        NewQueueWithCap[T](min(max(capacity, 0), defaultCap)),
+		capacity: capacity,
+		notEmpty: make(chan struct{}),
+		notFull:  make(chan struct{}),
+	}
+}
+
+// Offer 将 v 添加到 queue 的后端,queue 已满时阻塞直到有空位。queue 已关闭时返回
+// [ErrQueueClosed];等待期间 ctx 结束时返回 ctx.Err(),v 不会入队。
+func (b *BlockingQueue[T]) Offer(ctx context.Context, v T) error {
+	for {
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+		b.mu.Lock()
+		if b.closed {
+			b.mu.Unlock()
+			return ErrQueueClosed
+		}
+		if !b.full() {
+			b.q.Enqueue(v)
+			if b.takers > 0 {
+				b.notEmpty = broadcast(b.notEmpty)
+			}
+			b.mu.Unlock()
+			return nil
+		}
+		wait := b.notFull
+		b.offerers++
+		b.mu.Unlock()
+
+		select {
+		case <-wait:
+		case <-ctx.Done():
+		}
+		b.mu.Lock()
+		b.offerers--
+		b.mu.Unlock()
+	}
+}
+
+// TryOffer 在不阻塞的情况下尝试入队,queue 已满或已关闭时返回 false。
+func (b *BlockingQueue[T]) TryOffer(v T) bool {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	if b.closed || b.full() {
+		return false
+	}
+	b.q.Enqueue(v)
+	if b.takers > 0 {
+		b.notEmpty = broadcast(b.notEmpty)
+	}
+	return true
+}
+
+// Take 移除并返回前端元素,queue 为空时阻塞直到有元素。queue 已关闭且排空时返回
+// [ErrQueueClosed];等待期间 ctx 结束时返回 ctx.Err()。
+func (b *BlockingQueue[T]) Take(ctx context.Context) (T, error) {
+	var zero T
+	for {
+		if err := ctx.Err(); err != nil {
+			return zero, err
+		}
+		b.mu.Lock()
+		if v, ok := b.dequeue(); ok {
+			b.mu.Unlock()
+			return v, nil
+		}
+		if b.closed {
+			b.mu.Unlock()
+			return zero, ErrQueueClosed
+		}
+		wait := b.notEmpty
+		b.takers++
+		b.mu.Unlock()
+
+		select {
+		case <-wait:
+		case <-ctx.Done():
+		}
+		b.mu.Lock()
+		b.takers--
+		b.mu.Unlock()
+	}
+}
+
+// TryTake 在不阻塞的情况下尝试出队,queue 为空时返回 (zero, false)。
+func (b *BlockingQueue[T]) TryTake() (T, bool) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return b.dequeue()
+}
+
+// Close 关闭 queue 并唤醒所有等待者:阻塞的 Offer 返回 [ErrQueueClosed],阻塞的 Take 在
+// queue 为空时返回 [ErrQueueClosed]。已入队的元素仍可被取走。重复调用是安全的。
+func (b *BlockingQueue[T]) Close() {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	if b.closed {
+		return
+	}
+	b.closed = true
+	b.notEmpty = broadcast(b.notEmpty)
+	b.notFull = broadcast(b.notFull)
+}
+
+// IsClosed 报告 queue 是否已关闭。
+func (b *BlockingQueue[T]) IsClosed() bool {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return b.closed
+}
+
+// Len 返回当前元素数量。
+func (b *BlockingQueue[T]) Len() int {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return b.q.Len()
+}
+
+// Cap 返回 capacity;无界 queue 返回 0。
+func (b *BlockingQueue[T]) Cap() int { return max(b.capacity, 0) }
+
+// Consume 返回一个逐个 Take 元素的 [iter.Seq],用于消费者循环:
+//
+//	for job := range q.Consume(ctx) { ... }
+//
+// queue 关闭且排空、或 ctx 结束时遍历结束;需要区分两者时检查 ctx.Err()。提前 break 出
+// range 是安全的,未被取走的元素留在 queue 中。
+func (b *BlockingQueue[T]) Consume(ctx context.Context) iter.Seq[T] {
+	return func(yield func(T) bool) {
+		for {
+			v, err := b.Take(ctx)
+			if err != nil || !yield(v) {
+				return
+			}
+		}
+	}
+}
+
+// full 报告有界 queue 是否已满。调用方须持锁。
+func (b *BlockingQueue[T]) full() bool {
+	return b.capacity > 0 && b.q.Len() >= b.capacity
+}
+
+// dequeue 出队一个元素并在有等待的 Offer 时唤醒它们。调用方须持锁。
+func (b *BlockingQueue[T]) dequeue() (T, bool) {
+	v, ok := b.q.Dequeue()
+	if ok && b.offerers > 0 {
+		b.notFull = broadcast(b.notFull)
+	}
+	return v, ok
+}
+
+// broadcast 关闭 ch 以唤醒所有等待者,并返回一个新的 channel 供之后的等待者使用。
+func broadcast(ch chan struct{}) chan struct{} {
+	close(ch)
+	return make(chan struct{})
+}
@@ -0,0 +1,182 @@
+package collection
+
+import (
+	"encoding/binary"
+	"fmt"
+	"math"
+)
+
+// BloomFilter 是一个概率成员判定结构:[BloomFilter.Test] 返回 false 时元素一定不存在,返回
+// true 时元素可能存在(假阳性率由构造参数决定)。它不支持删除,内存只与预期元素数和假阳性率
+// 有关 —— 100 万元素、1% 假阳性率约 1.2 MB,而与元素本身大小无关。
+//
+// 用于"这个请求 ID 是否见过"一类的去重预判。参数相同的 filter 可用 [BloomFilter.Union] 合并,
+// 并可经 MarshalBinary/UnmarshalBinary 在实例之间传递。它不是 goroutine 安全的。
+//
+// 零值不可用:查询方法把它当作空 filter,Add 会 panic。请使用 [NewBloomFilter]/
+// [NewBloomFilterWithSize] 构造,或以 [BloomFilter.UnmarshalBinary] 解码得到。
+type BloomFilter struct {
+	bits []uint64
+	m    uint64 // 位数
+	k    uint64 // 哈希函数个数
+	n    uint64 // 已 Add 的次数(含重复)
+}
+
+const bloomMagic = "BLM1"
+
+// maxBloomHashes 是哈希函数个数的上限。即便假阳性率取 1e-300,最优 k 也不足 1000;
+// 更大的 k 只会让每次 Add/Test 线性变慢。
+const maxBloomHashes = 1024
+
+// NewBloomFilter 按预期元素数 expected 与目标假阳性率 fpRate 创建一个空 bloom filter:
+// 位数 m = -n·ln(p)/ln(2)²,哈希函数个数 k = (m/n)·ln(2)。非正的 expected 视为 1;
+// fpRate 不在 (0, 1) 内时回退到 0.01。
+func NewBloomFilter(expected int, fpRate float64) *BloomFilter {
+	n := float64(max(expected, 1))
+	if !(fpRate > 0 && fpRate < 1) {
+		fpRate = 0.01
+	}
+	m := math.Ceil(-n * math.Log(fpRate) / (math.Ln2 * math.Ln2))
+	k := math.Round(m / n * math.Ln2)
+	return NewBloomFilterWithSize(uint64(m), uint64(k))
+}
+
+// NewBloomFilterWithSize 以 m 位、k 个哈希函数创建一个空 bloom filter。m 与 k 至少为 1,
+// k 至多为 1024。
+func NewBloomFilterWithSize(m, k uint64) *BloomFilter {
+	m, k = max(m, 1), min(max(k, 1), maxBloomHashes)
+	return &BloomFilter{bits: make([]uint64, (m+63)/64), m: m, k: k}
+}
+
+// Add 加入 data。
+func (f *BloomFilter) Add(data []byte) { f.add(sketchHash(data)) }
+
+// AddString 加入 s,不为其分配 []byte。
+func (f *BloomFilter) AddString(s string) { f.add(sketchHashString(s)) }
+
+// Test 报告 data 是否可能已加入。false 表示一定未加入。
+func (f *BloomFilter) Test(data []byte) bool { return f.test(sketchHash(data)) }
+
+// TestString 报告 s 是否可能已加入。false 表示一定未加入。
+func (f *BloomFilter) TestString(s string) bool { return f.test(sketchHashString(s)) }
+
+// TestAndAdd 加入 data,并报告它在加入之前是否可能已存在 —— 去重场景的一步操作。
+func (f *BloomFilter) TestAndAdd(data []byte) bool {
+	h := sketchHash(data)
+	seen := f.test(h)
+	f.add(h)
+	return seen
+}
+
+// TestAndAddString 是 [BloomFilter.TestAndAdd] 的 string 版本。
+func (f *BloomFilter) TestAndAddString(s string) bool {
+	h := sketchHashString(s)
+	seen := f.test(h)
+	f.add(h)
+	return seen
+}
+
+// Cap 返回位数 m。
+func (f *BloomFilter) Cap() uint64 { return f.m }
+
+// K 返回哈希函数个数。
+func (f *BloomFilter) K() uint64 { return f.k }
+
+// Count 返回 Add 的次数(含重复加入同一元素)。
+func (f *BloomFilter) Count() uint64 { return f.n }
+
+// FalsePositiveRate 按当前 Count 估算假阳性率 (1 - e^(-kn/m))^k。Count 含重复加入,
+// 因此大量重复时估算偏高。
+func (f *BloomFilter) FalsePositiveRate() float64 {
+	if f.m == 0 {
+		return 0
+	}
+	return math.Pow(1-math.Exp(-float64(f.k)*float64(f.n)/float64(f.m)), float64(f.k))
+}
+
+// Union 将 other 合并到 f:结果等价于把两边加入过的元素都加入 f。两者的位数与哈希函数个数
+// 必须相同,否则返回 [ErrSketchMismatch] 且 f 不变。
+func (f *BloomFilter) Union(other *BloomFilter) error {
+	if f.m != other.m || f.k != other.k {
+		return fmt.Errorf("%w: bloom filter m=%d k=%d vs m=%d k=%d", ErrSketchMismatch, f.m, f.k, other.m, other.k)
+	}
+	for i, w := range other.bits {
+		f.bits[i] |= w
+	}
+	f.n += other.n
+	return nil
+}
+
+// Clear 清空所有位,参数保持不变。
+func (f *BloomFilter) Clear() {
+	clear(f.bits)
+	f.n = 0
+}
+
+// Clone 返回 filter 的独立副本。
+func (f *BloomFilter) Clone() *BloomFilter {
+	out := *f
+	out.bits = append([]uint64(nil), f.bits...)
+	return &out
+}
+
+// MarshalBinary 将 filter 编码为字节序列(参数与位图,小端序),实现 [encoding.BinaryMarshaler]。
+func (f *BloomFilter) MarshalBinary() ([]byte, error) {
+	out := make([]byte, 0, len(bloomMagic)+24+8*len(f.bits))
+	out = append(out, bloomMagic...)
+	out = binary.LittleEndian.AppendUint64(out, f.m)
+	out = binary.LittleEndian.AppendUint64(out, f.k)
+	out = binary.LittleEndian.AppendUint64(out, f.n)
+	for _, w := range f.bits {
+		out = binary.LittleEndian.AppendUint64(out, w)
+	}
+	return out, nil
+}
+
+// UnmarshalBinary 从 [BloomFilter.MarshalBinary] 的输出还原 filter,实现
+// [encoding.BinaryUnmarshaler]。数据损坏时返回错误且 f 不变。
+func (f *BloomFilter) UnmarshalBinary(data []byte) error {
+	r := newSketchReader("BloomFilter", bloomMagic, data)
+	m, k, n := r.uint64(), r.uint64(), r.uint64()
+	// 先以剩余数据长度约束 m,再计算字数,避免 (m+63)/64 溢出或按损坏的头部分配内存。
+	if r.err == nil && (m == 0 || m/8 > uint64(len(r.data)) || k == 0 || k > maxBloomHashes) {
+		return fmt.Errorf("collection: decode BloomFilter: invalid m=%d k=%d", m, k)
+	}
+	words := (m + 63) / 64
+	raw := r.bytes(8 * words)
+	if err := r.done(); err != nil {
+		return err
+	}
+	bits := make([]uint64, words)
+	for i := range bits {
+		bits[i] = binary.LittleEndian.Uint64(raw[8*i:])
+	}
+	*f = BloomFilter{bits: bits, m: m, k: k, n: n}
+	return nil
+}
+
+func (f *BloomFilter) add(h uint64) {
+	if f.m == 0 {
+		panic("collection: Add on a zero-value BloomFilter; construct it with NewBloomFilter")
+	}
+	h1, h2 := doubleHash(h)
+	for i := uint64(0); i < f.k; i++ {
+		bit := (h1 + i*h2) % f.m
+		f.bits[bit/64] |= 1 << (bit % 64)
+	}
+	f.n++
+}
+
+func (f *BloomFilter) test(h uint64) bool {
+	if f.m == 0 {
+		return false
+	}
+	h1, h2 := doubleHash(h)
+	for i := uint64(0); i < f.k; i++ {
+		bit := (h1 + i*h2) % f.m
+		if f.bits[bit/64]&(1<<(bit%64)) == 0 {
+			return false
+		}
+	}
+	return true
+}
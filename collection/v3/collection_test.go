@@ -1,10 +1,25 @@
 package collection
 
 import (
+	"bytes"
+	"context"
+	"encoding/binary"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"maps"
+	"math"
+	"math/rand/v2"
+	"runtime"
 	"slices"
+	"strings"
+	"sync"
 	"testing"
+	"time"
 
 	"github.com/stretchr/testify/assert"
+	"github.com/vmihailenco/msgpack/v5"
+	"gopkg.in/yaml.v3"
 )
 
 // --- Stack ---
@@ -225,6 +240,209 @@ func TestQueue_RingBuffer_PartialWrap(t *testing.T) {
 	assert.True(t, q.IsEmpty())
 }
 
+// --- Deque ---
+
+func TestDeque_New(t *testing.T) {
+	d := NewDeque[int]()
+	assert.True(t, d.IsEmpty())
+	assert.Equal(t, 0, d.Len())
+	assert.NotNil(t, NewDequeWithCap[int](0)) // non-positive cap falls back to default
+}
+
+func TestDeque_PushPopBothEnds(t *testing.T) {
+	d := NewDeque[int]()
+	_, ok := d.PopFront()
+	assert.False(t, ok)
+	_, ok = d.PopBack()
+	assert.False(t, ok)
+
+	d.PushBack(2)
+	d.PushBack(3)
+	d.PushFront(1)
+	d.PushFront(0)
+	assert.Equal(t, []int{0, 1, 2, 3}, d.Values())
+
+	v, ok := d.PopFront()
+	assert.True(t, ok)
+	assert.Equal(t, 0, v)
+	v, ok = d.PopBack()
+	assert.True(t, ok)
+	assert.Equal(t, 3, v)
+	assert.Equal(t, []int{1, 2}, d.Values())
+}
+
+func TestDeque_PeekAndAt(t *testing.T) {
+	d := NewDeque[string]()
+	_, ok := d.PeekFront()
+	assert.False(t, ok)
+	_, ok = d.PeekBack()
+	assert.False(t, ok)
+
+	d.PushBack("b")
+	d.PushFront("a")
+	d.PushBack("c")
+	f, _ := d.PeekFront()
+	b, _ := d.PeekBack()
+	assert.Equal(t, "a", f)
+	assert.Equal(t, "c", b)
+	assert.Equal(t, 3, d.Len()) // Peek does not remove
+
+	v, ok := d.At(1)
+	assert.True(t, ok)
+	assert.Equal(t, "b", v)
+	_, ok = d.At(3)
+	assert.False(t, ok)
+	_, ok = d.At(-1)
+	assert.False(t, ok)
+}
+
+// TestDeque_WrapAndGrow pushes at the front of a small deque so the head
+// wraps below zero, then forces a grow while wrapped: order must survive both.
+func TestDeque_WrapAndGrow(t *testing.T) {
+	d := NewDequeWithCap[int](4)
+	var want []int
+	for i := 0; i < 50; i++ {
+		if i%2 == 0 {
+			d.PushFront(i)
+			want = append([]int{i}, want...)
+		} else {
+			d.PushBack(i)
+			want = append(want, i)
+		}
+	}
+	assert.Equal(t, want, d.Values())
+	for i := range want {
+		v, _ := d.At(i)
+		assert.Equal(t, want[i], v)
+	}
+}
+
+func TestDeque_PopClearsSlot(t *testing.T) {
+	d := NewDequeWithCap[*int](4)
+	x, y := 1, 2
+	d.PushBack(&x)
+	d.PushBack(&y)
+	d.PopFront()
+	d.PopBack()
+	for _, p := range d.buf {
+		assert.Nil(t, p)
+	}
+}
+
+func TestDeque_ClearAndClone(t *testing.T) {
+	d := NewDequeWithCap[int](2)
+	d.PushBack(1)
+	d.PushFront(0) // wrapped
+	c := d.Clone()
+	c.PushBack(2)
+	assert.Equal(t, []int{0, 1}, d.Values())
+	assert.Equal(t, []int{0, 1, 2}, c.Values())
+
+	d.Clear()
+	assert.True(t, d.IsEmpty())
+	d.PushBack(7)
+	assert.Equal(t, []int{7}, d.Values())
+}
+
+// --- RingBuffer ---
+
+func TestRingBuffer_New(t *testing.T) {
+	r := NewRingBuffer[int](3, RingOverwrite)
+	assert.True(t, r.IsEmpty())
+	assert.False(t, r.IsFull())
+	assert.Equal(t, 3, r.Cap())
+	assert.Equal(t, defaultCap, NewRingBuffer[int](0, RingOverwrite).Cap()) // non-positive cap falls back to default
+}
+
+func TestRingBuffer_ZeroValue(t *testing.T) {
+	var r RingBuffer[int]
+	assert.True(t, r.IsEmpty())
+	assert.False(t, r.IsFull())
+	assert.Equal(t, defaultCap, r.Cap())
+	_, ok := r.Pop()
+	assert.False(t, ok)
+	assert.Empty(t, r.Values())
+	assert.Equal(t, defaultCap, r.Clone().Cap())
+
+	// The zero value overwrites once the default capacity is reached.
+	for i := 0; i <= defaultCap; i++ {
+		assert.True(t, r.Push(i))
+	}
+	assert.True(t, r.IsFull())
+	oldest, _ := r.Oldest()
+	assert.Equal(t, 1, oldest)
+}
+
+func TestRingBuffer_Overwrite(t *testing.T) {
+	r := NewRingBuffer[int](3, RingOverwrite)
+	for i := 1; i <= 5; i++ {
+		assert.True(t, r.Push(i))
+	}
+	assert.True(t, r.IsFull())
+	assert.Equal(t, 3, r.Len())
+	assert.Equal(t, []int{3, 4, 5}, r.Values()) // the last three, oldest first
+	assert.Equal(t, 3, cap(r.buf))              // never grows
+
+	o, _ := r.Oldest()
+	n, _ := r.Newest()
+	assert.Equal(t, 3, o)
+	assert.Equal(t, 5, n)
+}
+
+func TestRingBuffer_Reject(t *testing.T) {
+	r := NewRingBuffer[int](2, RingReject)
+	assert.True(t, r.Push(1))
+	assert.True(t, r.Push(2))
+	assert.False(t, r.Push(3))
+	assert.Equal(t, []int{1, 2}, r.Values())
+
+	v, ok := r.Pop()
+	assert.True(t, ok)
+	assert.Equal(t, 1, v)
+	assert.True(t, r.Push(3)) // room again after Pop
+	assert.Equal(t, []int{2, 3}, r.Values())
+}
+
+func TestRingBuffer_PopAndAt(t *testing.T) {
+	r := NewRingBuffer[int](3, RingOverwrite)
+	_, ok := r.Pop()
+	assert.False(t, ok)
+	_, ok = r.Oldest()
+	assert.False(t, ok)
+	_, ok = r.Newest()
+	assert.False(t, ok)
+
+	for i := 0; i < 4; i++ {
+		r.Push(i)
+	}
+	v, ok := r.At(0)
+	assert.True(t, ok)
+	assert.Equal(t, 1, v)
+	v, _ = r.At(2)
+	assert.Equal(t, 3, v)
+	_, ok = r.At(3)
+	assert.False(t, ok)
+
+	v, _ = r.Pop()
+	assert.Equal(t, 1, v)
+	assert.Equal(t, []int{2, 3}, r.Values())
+}
+
+func TestRingBuffer_ClearAndClone(t *testing.T) {
+	r := NewRingBuffer[int](2, RingReject)
+	r.Push(1)
+	r.Push(2)
+	c := r.Clone()
+	c.Pop()
+	assert.Equal(t, []int{1, 2}, r.Values())
+	assert.Equal(t, []int{2}, c.Values())
+	assert.False(t, r.Clone().Push(3)) // mode is preserved
+
+	r.Clear()
+	assert.True(t, r.IsEmpty())
+	assert.Equal(t, 2, r.Cap())
+}
+
 // --- Heap ---
 
 func intLess(a, b int) bool { return a < b }
@@ -330,6 +548,183 @@ func TestMaxHeap_Strings(t *testing.T) {
 	assert.Equal(t, "a", v)
 }
 
+// --- IndexedHeap ---
+
+// checkIndexedHeap asserts the heap property and that pos mirrors data.
+func checkIndexedHeap[K comparable, T any](t *testing.T, h *IndexedHeap[K, T]) {
+	t.Helper()
+	assert.Equal(t, len(h.data), len(h.pos))
+	for i, it := range h.data {
+		assert.Equal(t, i, h.pos[it.key], "pos out of sync for %v", it.key)
+		if i > 0 {
+			assert.False(t, h.less(it.val, h.data[(i-1)/2].val), "heap property broken at %d", i)
+		}
+	}
+}
+
+func TestIndexedHeap_PushPop(t *testing.T) {
+	h := NewIndexedMinHeap[string, int]()
+	_, _, ok := h.Pop()
+	assert.False(t, ok)
+	_, _, ok = h.Peek()
+	assert.False(t, ok)
+
+	assert.True(t, h.Push("c", 3))
+	assert.True(t, h.Push("a", 1))
+	assert.True(t, h.Push("b", 2))
+	assert.Equal(t, 3, h.Len())
+
+	k, v, ok := h.Peek()
+	assert.True(t, ok)
+	assert.Equal(t, "a", k)
+	assert.Equal(t, 1, v)
+
+	var keys []string
+	for !h.IsEmpty() {
+		k, _, _ := h.Pop()
+		keys = append(keys, k)
+	}
+	assert.Equal(t, []string{"a", "b", "c"}, keys)
+	assert.Empty(t, h.pos)
+}
+
+func TestIndexedHeap_Update(t *testing.T) {
+	h := NewIndexedMinHeap[string, int]()
+	h.Push("a", 10)
+	h.Push("b", 20)
+	h.Push("c", 30)
+
+	assert.True(t, h.Update("c", 5)) // decrease-key: c moves to the top
+	k, _, _ := h.Peek()
+	assert.Equal(t, "c", k)
+	assert.True(t, h.Update("c", 25)) // increase-key: a is back on top
+	k, _, _ = h.Peek()
+	assert.Equal(t, "a", k)
+	assert.False(t, h.Update("zzz", 1))
+
+	assert.False(t, h.Push("b", 1)) // Push on an existing key updates it
+	k, v, _ := h.Peek()
+	assert.Equal(t, "b", k)
+	assert.Equal(t, 1, v)
+	checkIndexedHeap(t, h)
+}
+
+func TestIndexedHeap_RemoveGetContains(t *testing.T) {
+	h := NewIndexedMaxHeap[int, int]()
+	for i := 0; i < 10; i++ {
+		h.Push(i, i*10)
+	}
+	assert.True(t, h.Contains(4))
+	v, ok := h.Get(4)
+	assert.True(t, ok)
+	assert.Equal(t, 40, v)
+
+	v, ok = h.Remove(4)
+	assert.True(t, ok)
+	assert.Equal(t, 40, v)
+	assert.False(t, h.Contains(4))
+	_, ok = h.Get(4)
+	assert.False(t, ok)
+	_, ok = h.Remove(4)
+	assert.False(t, ok)
+
+	v, ok = h.Remove(9) // the top
+	assert.True(t, ok)
+	assert.Equal(t, 90, v)
+	k, _, _ := h.Peek()
+	assert.Equal(t, 8, k)
+	checkIndexedHeap(t, h)
+}
+
+// TestIndexedHeap_RandomOps interleaves every mutating operation against a
+// plain map and checks the heap property, the key index, and Pop's minimum
+// after each step.
+func TestIndexedHeap_RandomOps(t *testing.T) {
+	rng := rand.New(rand.NewPCG(3, 4))
+	h := NewIndexedHeapWithCap[int, int](4, intLess)
+	ref := map[int]int{}
+	for i := 0; i < 2000; i++ {
+		k, v := rng.IntN(100), rng.IntN(1000)
+		switch rng.IntN(4) {
+		case 0:
+			h.Push(k, v)
+			ref[k] = v
+		case 1:
+			_, had := ref[k]
+			assert.Equal(t, had, h.Update(k, v))
+			if had {
+				ref[k] = v
+			}
+		case 2:
+			_, had := ref[k]
+			_, ok := h.Remove(k)
+			assert.Equal(t, had, ok)
+			delete(ref, k)
+		case 3:
+			if pk, pv, ok := h.Pop(); ok {
+				for _, rv := range ref {
+					assert.LessOrEqual(t, pv, rv)
+				}
+				delete(ref, pk)
+			}
+		}
+		checkIndexedHeap(t, h)
+	}
+	assert.Equal(t, len(ref), h.Len())
+	for k, v := range ref {
+		got, ok := h.Get(k)
+		assert.True(t, ok)
+		assert.Equal(t, v, got)
+	}
+}
+
+func TestIndexedHeap_Dijkstra(t *testing.T) {
+	type edge struct {
+		to   string
+		cost int
+	}
+	graph := map[string][]edge{
+		"a": {{"b", 7}, {"c", 9}, {"f", 14}},
+		"b": {{"c", 10}, {"d", 15}},
+		"c": {{"d", 11}, {"f", 2}},
+		"d": {{"e", 6}},
+		"f": {{"e", 9}},
+	}
+	dist := map[string]int{"a": 0}
+	pq := NewIndexedMinHeap[string, int]()
+	pq.Push("a", 0)
+	for !pq.IsEmpty() {
+		u, du, _ := pq.Pop()
+		for _, e := range graph[u] {
+			if d, seen := dist[e.to]; !seen || du+e.cost < d {
+				dist[e.to] = du + e.cost
+				pq.Push(e.to, du+e.cost) // insert or decrease-key
+			}
+		}
+	}
+	assert.Equal(t, map[string]int{"a": 0, "b": 7, "c": 9, "d": 20, "e": 20, "f": 11}, dist)
+}
+
+func TestIndexedHeap_ClearClone(t *testing.T) {
+	h := NewIndexedMinHeap[string, int]()
+	h.Push("a", 1)
+	h.Push("b", 2)
+	c := h.Clone()
+	c.Update("b", 0)
+	k, _, _ := h.Peek()
+	assert.Equal(t, "a", k) // original unaffected
+	k, _, _ = c.Peek()
+	assert.Equal(t, "b", k)
+	assert.ElementsMatch(t, []string{"a", "b"}, h.Keys())
+	assert.ElementsMatch(t, []int{1, 2}, h.Values())
+
+	h.Clear()
+	assert.True(t, h.IsEmpty())
+	assert.False(t, h.Contains("a"))
+	h.Push("z", 26)
+	assert.Equal(t, 1, h.Len())
+}
+
 // --- Set ---
 
 func TestSet_New(t *testing.T) {
@@ -582,138 +977,2516 @@ func TestSet_None(t *testing.T) {
 	assert.True(t, None(NewSet[int](), func(x int) bool { return true }))
 }
 
-// --- iter.Seq integration ---
+// --- SortedMap / SortedSet ---
 
-func TestIter_Stack(t *testing.T) {
-	s := NewStack[int]()
-	s.Push(1)
-	s.Push(2)
-	s.Push(3)
-	var got []int
-	for v := range s.All() {
-		got = append(got, v)
-	}
-	assert.Equal(t, []int{1, 2, 3}, got) // bottom-to-top
-}
+func TestSortedMap_PutGetDelete(t *testing.T) {
+	m := NewSortedMapOrdered[string, int]()
+	assert.True(t, m.IsEmpty())
+	assert.True(t, m.Put("b", 2))
+	assert.True(t, m.Put("a", 1))
+	assert.True(t, m.Put("c", 3))
+	assert.False(t, m.Put("b", 20)) // replace
+	assert.Equal(t, 3, m.Len())
 
-func TestIter_Stack_EarlyBreak(t *testing.T) {
-	s := NewStack[int]()
-	for i := 0; i < 5; i++ {
-		s.Push(i)
-	}
-	n := 0
-	for range s.All() {
-		n++
-		if n == 2 {
-			break
-		}
-	}
-	assert.Equal(t, 2, n)
+	v, ok := m.Get("b")
+	assert.True(t, ok)
+	assert.Equal(t, 20, v)
+	_, ok = m.Get("z")
+	assert.False(t, ok)
+	assert.True(t, m.Contains("a"))
+
+	v, ok = m.Delete("a")
+	assert.True(t, ok)
+	assert.Equal(t, 1, v)
+	_, ok = m.Delete("a")
+	assert.False(t, ok)
+	assert.Equal(t, []string{"b", "c"}, m.Keys())
+	assert.Equal(t, []int{20, 3}, m.Values())
 }
 
-func TestIter_Queue(t *testing.T) {
-	q := NewQueue[int]()
-	q.Enqueue(1)
-	q.Enqueue(2)
-	q.Enqueue(3)
-	var got []int
-	for v := range q.All() {
-		got = append(got, v)
+func TestSortedMap_MinMaxFloorCeiling(t *testing.T) {
+	m := NewSortedMapOrdered[int, string]()
+	_, _, ok := m.Min()
+	assert.False(t, ok)
+	_, _, ok = m.Max()
+	assert.False(t, ok)
+
+	for _, k := range []int{10, 30, 20} {
+		m.Put(k, fmt.Sprint(k))
 	}
-	assert.Equal(t, []int{1, 2, 3}, got) // front-to-back
-}
+	k, v, ok := m.Min()
+	assert.True(t, ok)
+	assert.Equal(t, 10, k)
+	assert.Equal(t, "10", v)
+	k, _, _ = m.Max()
+	assert.Equal(t, 30, k)
 
-func TestIter_Heap(t *testing.T) {
-	h := NewMinHeap[int]()
-	h.Push(3)
-	h.Push(1)
-	h.Push(2)
-	got := slices.Collect(h.All()) // order is heap layout, not sorted
-	assert.ElementsMatch(t, []int{1, 2, 3}, got)
+	k, _, ok = m.Floor(25)
+	assert.True(t, ok)
+	assert.Equal(t, 20, k)
+	k, _, _ = m.Floor(20)
+	assert.Equal(t, 20, k)
+	_, _, ok = m.Floor(5)
+	assert.False(t, ok)
+
+	k, _, ok = m.Ceiling(25)
+	assert.True(t, ok)
+	assert.Equal(t, 30, k)
+	k, _, _ = m.Ceiling(10)
+	assert.Equal(t, 10, k)
+	_, _, ok = m.Ceiling(31)
+	assert.False(t, ok)
+
+	m.Delete(30)
+	k, _, _ = m.Max()
+	assert.Equal(t, 20, k) // tail follows deletion
 }
 
-func TestIter_Set(t *testing.T) {
-	s := NewSet(1, 2, 3)
-	got := slices.Collect(s.All())
-	assert.ElementsMatch(t, []int{1, 2, 3}, got)
+func TestSortedMap_RankAt(t *testing.T) {
+	m := NewSortedMapOrdered[int, int]()
+	for _, k := range []int{50, 10, 40, 20, 30} {
+		m.Put(k, k*10)
+	}
+	assert.Equal(t, 0, m.Rank(10))
+	assert.Equal(t, 2, m.Rank(30))
+	assert.Equal(t, 3, m.Rank(35)) // absent key: insertion position
+	assert.Equal(t, 5, m.Rank(99))
+
+	k, v, ok := m.At(2)
+	assert.True(t, ok)
+	assert.Equal(t, 30, k)
+	assert.Equal(t, 300, v)
+	_, _, ok = m.At(5)
+	assert.False(t, ok)
+	_, _, ok = m.At(-1)
+	assert.False(t, ok)
 }
 
-func TestIter_Set_EarlyBreak(t *testing.T) {
-	s := NewSet(1, 2, 3, 4, 5)
-	n := 0
-	for range s.All() {
-		n++
-		if n == 3 {
-			break
+func TestSortedMap_CustomLess(t *testing.T) {
+	m := NewSortedMap[string, int](func(a, b string) bool { return len(a) < len(b) })
+	m.Put("ccc", 3)
+	m.Put("a", 1)
+	m.Put("bb", 2)
+	m.Put("z", 9) // same length as "a": treated as the same key
+	assert.Equal(t, []string{"a", "bb", "ccc"}, m.Keys())
+	v, _ := m.Get("q")
+	assert.Equal(t, 9, v)
+}
+
+// TestSortedMap_MatchesSortedKeys cross-checks the skip list (including the
+// span bookkeeping behind Rank/At) against a plain map whose keys are sorted
+// by hand, across random inserts and deletes.
+func TestSortedMap_MatchesSortedKeys(t *testing.T) {
+	rng := rand.New(rand.NewPCG(1, 2))
+	m := NewSortedMapOrdered[int, int]()
+	ref := map[int]int{}
+	for i := 0; i < 5000; i++ {
+		k := rng.IntN(500)
+		if rng.IntN(3) == 0 {
+			_, had := ref[k]
+			delete(ref, k)
+			_, ok := m.Delete(k)
+			assert.Equal(t, had, ok)
+		} else {
+			ref[k] = i
+			m.Put(k, i)
 		}
 	}
-	assert.Equal(t, 3, n)
+	keys := slices.Sorted(maps.Keys(ref))
+	assert.Equal(t, keys, m.Keys())
+	for i, k := range keys {
+		assert.Equal(t, i, m.Rank(k))
+		got, v, _ := m.At(i)
+		assert.Equal(t, k, got)
+		assert.Equal(t, ref[k], v)
+	}
+	back := slices.Collect(func(yield func(int) bool) {
+		for k := range m.Backward() {
+			if !yield(k) {
+				return
+			}
+		}
+	})
+	slices.Reverse(back)
+	assert.Equal(t, keys, back)
 }
 
-// --- benchmarks ---
+func TestSortedMap_Range(t *testing.T) {
+	m := NewSortedMapOrdered[int, int]()
+	for i := 0; i < 10; i++ {
+		m.Put(i*10, i)
+	}
+	var keys []int
+	for k := range m.Range(15, 50) {
+		keys = append(keys, k)
+	}
+	assert.Equal(t, []int{20, 30, 40}, keys) // half-open [lo, hi)
 
-func BenchmarkStack_PushPop(b *testing.B) {
-	s := NewStack[int]()
-	b.ResetTimer()
-	for i := 0; i < b.N; i++ {
-		s.Push(i)
-		s.Pop()
+	n := 0
+	for range m.Range(50, 10) {
+		n++
 	}
+	assert.Equal(t, 0, n) // lo >= hi yields nothing
 }
 
-func BenchmarkQueue_EnqueueDequeue(b *testing.B) {
-	q := NewQueue[int]()
-	b.ResetTimer()
-	for i := 0; i < b.N; i++ {
-		q.Enqueue(i)
-		q.Dequeue()
+func TestSortedMap_DeleteDuringIteration(t *testing.T) {
+	m := NewSortedMapOrdered[int, int]()
+	for i := 0; i < 10; i++ {
+		m.Put(i, i)
 	}
-}
+	var seen []int
+	for k := range m.All() {
+		seen = append(seen, k)
+		m.Delete(k) // deleting the current key
+		if k == 2 {
+			m.Delete(3) // and a key not yet visited
+		}
+	}
+	assert.Equal(t, []int{0, 1, 2, 4, 5, 6, 7, 8, 9}, seen)
+	assert.True(t, m.IsEmpty())
 
-// BenchmarkQueue_LongLived models a queue that stays alive across many
-// enqueue/dequeue pairs — the exact workload v2 leaked under.
-func BenchmarkQueue_LongLived(b *testing.B) {
-	q := NewQueueWithCap[int](8)
-	b.ReportAllocs()
-	b.ResetTimer()
-	for i := 0; i < b.N; i++ {
-		q.Enqueue(i)
-		q.Dequeue()
+	for i := 0; i < 5; i++ {
+		m.Put(i, i)
+	}
+	seen = nil
+	for k := range m.Backward() {
+		seen = append(seen, k)
+		m.Delete(k)
+	}
+	assert.Equal(t, []int{4, 3, 2, 1, 0}, seen)
+}
+
+func TestSortedMap_InsertDuringIteration(t *testing.T) {
+	m := NewSortedMapOrdered[int, int]()
+	m.Put(1, 0)
+	m.Put(5, 0)
+	var seen []int
+	for k := range m.All() {
+		seen = append(seen, k)
+		if k == 1 {
+			m.Put(3, 0) // after the cursor: visited
+			m.Put(0, 0) // before the cursor: not visited
+		}
 	}
+	assert.Equal(t, []int{1, 3, 5}, seen)
 }
 
-func BenchmarkHeap_PushPop(b *testing.B) {
-	h := NewMinHeap[int]()
-	b.ResetTimer()
-	for i := 0; i < b.N; i++ {
-		h.Push(i)
-		h.Pop()
-	}
+func TestSortedMap_ClearClone(t *testing.T) {
+	m := NewSortedMapOrdered[int, int]()
+	m.Put(2, 2)
+	m.Put(1, 1)
+	c := m.Clone()
+	c.Put(3, 3)
+	assert.Equal(t, []int{1, 2}, m.Keys())
+	assert.Equal(t, []int{1, 2, 3}, c.Keys())
+
+	m.Clear()
+	assert.True(t, m.IsEmpty())
+	_, _, ok := m.Max()
+	assert.False(t, ok)
+	m.Put(7, 7)
+	assert.Equal(t, []int{7}, m.Keys())
 }
 
-func BenchmarkSet_Add(b *testing.B) {
-	s := NewSetWithCap[int](b.N)
-	b.ResetTimer()
-	for i := 0; i < b.N; i++ {
-		s.Add(i)
-	}
+func TestSortedSet(t *testing.T) {
+	s := NewSortedSetOrdered(5, 1, 3, 1)
+	assert.Equal(t, 3, s.Len())
+	assert.Equal(t, []int{1, 3, 5}, s.Values())
+	assert.False(t, s.Add(3))
+	assert.True(t, s.Add(4))
+	assert.True(t, s.Contains(4))
+	assert.True(t, s.Remove(4))
+	assert.False(t, s.Remove(4))
+
+	lo, _ := s.Min()
+	hi, _ := s.Max()
+	assert.Equal(t, 1, lo)
+	assert.Equal(t, 5, hi)
+	f, _ := s.Floor(4)
+	c, _ := s.Ceiling(4)
+	assert.Equal(t, 3, f)
+	assert.Equal(t, 5, c)
+	assert.Equal(t, 2, s.Rank(5))
+	v, ok := s.At(1)
+	assert.True(t, ok)
+	assert.Equal(t, 3, v)
+
+	assert.Equal(t, []int{3, 5}, slices.Collect(s.Range(2, 6)))
+	assert.Equal(t, []int{5, 3, 1}, slices.Collect(s.Backward()))
+
+	cl := s.Clone()
+	s.Clear()
+	assert.True(t, s.IsEmpty())
+	_, ok = s.Min()
+	assert.False(t, ok)
+	assert.Equal(t, []int{1, 3, 5}, cl.Values())
 }
 
-func BenchmarkSet_Contains(b *testing.B) {
-	s := NewSet[int]()
-	for i := 0; i < 10000; i++ {
-		s.Add(i)
-	}
-	b.ResetTimer()
-	for i := 0; i < b.N; i++ {
-		s.Contains(i % 10000)
+func TestSortedSet_CustomLess(t *testing.T) {
+	type task struct {
+		pri  int
+		name string
 	}
+	s := NewSortedSet(func(a, b task) bool { return a.pri > b.pri }, // highest priority first
+		task{1, "low"}, task{9, "urgent"}, task{5, "normal"})
+	first, _ := s.Min()
+	assert.Equal(t, "urgent", first.name)
 }
 
-func BenchmarkSet_Intersect(b *testing.B) {
-	a := NewSet[int]()
-	bb := NewSet[int]()
-	for i := 0; i < 1000; i++ {
+// --- OrderedMap ---
+
+func TestOrderedMap_InsertionOrder(t *testing.T) {
+	m := NewOrderedMap[string, int](InsertionOrder)
+	assert.Equal(t, InsertionOrder, m.Mode())
+	assert.True(t, m.Put("c", 3))
+	assert.True(t, m.Put("a", 1))
+	assert.True(t, m.Put("b", 2))
+	assert.False(t, m.Put("c", 30)) // update keeps position
+	assert.Equal(t, []string{"c", "a", "b"}, m.Keys())
+	assert.Equal(t, []int{30, 1, 2}, m.Values())
+
+	v, ok := m.Get("a") // Get does not reorder in insertion mode
+	assert.True(t, ok)
+	assert.Equal(t, 1, v)
+	assert.Equal(t, []string{"c", "a", "b"}, m.Keys())
+
+	v, ok = m.Delete("a")
+	assert.True(t, ok)
+	assert.Equal(t, 1, v)
+	_, ok = m.Delete("a")
+	assert.False(t, ok)
+	_, ok = m.Get("a")
+	assert.False(t, ok)
+	assert.Equal(t, []string{"c", "b"}, m.Keys())
+	assert.Equal(t, 2, m.Len())
+}
+
+func TestOrderedMap_AccessOrder(t *testing.T) {
+	m := NewOrderedMap[string, int](AccessOrder)
+	m.Put("a", 1)
+	m.Put("b", 2)
+	m.Put("c", 3)
+	m.Get("a")     // a becomes most recently used
+	m.Put("b", 20) // so does b
+	assert.Equal(t, []string{"c", "a", "b"}, m.Keys())
+
+	v, ok := m.Peek("c") // Peek and Contains do not reorder
+	assert.True(t, ok)
+	assert.Equal(t, 3, v)
+	assert.True(t, m.Contains("c"))
+	_, ok = m.Peek("zz")
+	assert.False(t, ok)
+	assert.Equal(t, []string{"c", "a", "b"}, m.Keys())
+
+	// LRU eviction from the front.
+	k, v, ok := m.PopFront()
+	assert.True(t, ok)
+	assert.Equal(t, "c", k)
+	assert.Equal(t, 3, v)
+	assert.Equal(t, []string{"a", "b"}, m.Keys())
+}
+
+func TestOrderedMap_FrontBackMove(t *testing.T) {
+	m := NewOrderedMap[int, string](InsertionOrder)
+	_, _, ok := m.Front()
+	assert.False(t, ok)
+	_, _, ok = m.Back()
+	assert.False(t, ok)
+	_, _, ok = m.PopFront()
+	assert.False(t, ok)
+	_, _, ok = m.PopBack()
+	assert.False(t, ok)
+
+	for i := 1; i <= 4; i++ {
+		m.Put(i, fmt.Sprint(i))
+	}
+	assert.True(t, m.MoveToFront(3))
+	assert.True(t, m.MoveToBack(1))
+	assert.True(t, m.MoveToFront(3)) // already at the front
+	assert.False(t, m.MoveToFront(9))
+	assert.False(t, m.MoveToBack(9))
+	assert.Equal(t, []int{3, 2, 4, 1}, m.Keys())
+
+	k, v, _ := m.Front()
+	assert.Equal(t, 3, k)
+	assert.Equal(t, "3", v)
+	k, _, _ = m.Back()
+	assert.Equal(t, 1, k)
+
+	k, _, ok = m.PopBack()
+	assert.True(t, ok)
+	assert.Equal(t, 1, k)
+	assert.Equal(t, []int{3, 2, 4}, m.Keys())
+	assert.False(t, m.Contains(1))
+}
+
+func TestOrderedMap_ZeroValue(t *testing.T) {
+	var m OrderedMap[string, int]
+	assert.True(t, m.IsEmpty())
+	_, ok := m.Get("x")
+	assert.False(t, ok)
+	m.Put("x", 1)
+	m.Put("y", 2)
+	assert.Equal(t, []string{"x", "y"}, m.Keys())
+}
+
+func TestOrderedMap_ClearClone(t *testing.T) {
+	m := NewOrderedMap[string, int](AccessOrder)
+	m.Put("a", 1)
+	m.Put("b", 2)
+	c := m.Clone()
+	c.Get("a")
+	assert.Equal(t, []string{"a", "b"}, m.Keys())
+	assert.Equal(t, []string{"b", "a"}, c.Keys()) // clone keeps the mode
+	m.Clear()
+	assert.True(t, m.IsEmpty())
+	_, _, ok := m.Front()
+	assert.False(t, ok)
+	m.Put("z", 26)
+	assert.Equal(t, []string{"z"}, m.Keys())
+}
+
+func TestOrderedMap_DeleteCurrentDuringIteration(t *testing.T) {
+	m := NewOrderedMap[int, int](InsertionOrder)
+	for i := 0; i < 6; i++ {
+		m.Put(i, i)
+	}
+	var seen []int
+	for k := range m.All() {
+		seen = append(seen, k)
+		if k%2 == 0 {
+			m.Delete(k)
+		}
+	}
+	assert.Equal(t, []int{0, 1, 2, 3, 4, 5}, seen)
+	assert.Equal(t, []int{1, 3, 5}, m.Keys())
+
+	seen = nil
+	for k := range m.Backward() {
+		seen = append(seen, k)
+		m.Delete(k)
+	}
+	assert.Equal(t, []int{5, 3, 1}, seen)
+	assert.True(t, m.IsEmpty())
+}
+
+func TestOrderedMap_JSONRoundTrip(t *testing.T) {
+	m := NewOrderedMap[string, any](InsertionOrder)
+	m.Put("zeta", 1)
+	m.Put("alpha", "two")
+	m.Put("mid", []int{3})
+	m.Put("q\"uote", nil)
+
+	b, err := json.Marshal(m)
+	assert.NoError(t, err)
+	assert.Equal(t, `{"zeta":1,"alpha":"two","mid":[3],"q\"uote":null}`, string(b))
+
+	var back OrderedMap[string, any]
+	assert.NoError(t, json.Unmarshal(b, &back))
+	assert.Equal(t, []string{"zeta", "alpha", "mid", "q\"uote"}, back.Keys())
+	v, _ := back.Get("zeta")
+	assert.Equal(t, float64(1), v)
+
+	empty, err := json.Marshal(NewOrderedMap[string, int](InsertionOrder))
+	assert.NoError(t, err)
+	assert.Equal(t, `{}`, string(empty))
+}
+
+func TestOrderedMap_JSONStructField(t *testing.T) {
+	type config struct {
+		Name   string                    `json:"name"`
+		Limits OrderedMap[string, int]   `json:"limits"`
+		Ports  *OrderedMap[uint16, bool] `json:"ports"`
+	}
+	const doc = `{"name":"svc","limits":{"rps":100,"burst":20,"rps":150},"ports":{"8080":true,"443":false}}`
+	var c config
+	assert.NoError(t, json.Unmarshal([]byte(doc), &c))
+	assert.Equal(t, []string{"rps", "burst"}, c.Limits.Keys()) // duplicate keeps first position
+	v, _ := c.Limits.Get("rps")
+	assert.Equal(t, 150, v) // ...and last value
+	assert.Equal(t, []uint16{8080, 443}, c.Ports.Keys())
+
+	// A value (non-pointer) field marshals in order too.
+	b, err := json.Marshal(c)
+	assert.NoError(t, err)
+	assert.Equal(t, `{"name":"svc","limits":{"rps":150,"burst":20},"ports":{"8080":true,"443":false}}`, string(b))
+}
+
+func TestOrderedMap_JSONErrors(t *testing.T) {
+	var m OrderedMap[int, int]
+	assert.Error(t, json.Unmarshal([]byte(`[1,2]`), &m))
+	assert.Error(t, json.Unmarshal([]byte(`{"x":1}`), &m))     // key is not an int
+	assert.Error(t, json.Unmarshal([]byte(`{"1":"one"}`), &m)) // value is not an int
+	assert.NoError(t, json.Unmarshal([]byte(`null`), &m))
+
+	type point struct{ X, Y int }
+	pm := NewOrderedMap[point, int](InsertionOrder)
+	pm.Put(point{1, 2}, 3)
+	_, err := json.Marshal(pm)
+	assert.Error(t, err) // struct keys are not supported, as with encoding/json
+}
+
+// --- Sync variants (run with -race) ---
+
+func TestSyncStack_Concurrent(t *testing.T) {
+	s := NewSyncStack[int]()
+	var wg sync.WaitGroup
+	for g := 0; g < 8; g++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for i := 0; i < 500; i++ {
+				s.Push(i)
+				s.Peek()
+				s.Len()
+			}
+		}()
+	}
+	wg.Wait()
+	assert.Equal(t, 4000, s.Len())
+
+	popped := 0
+	var mu sync.Mutex
+	for g := 0; g < 8; g++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for {
+				if _, ok := s.Pop(); !ok {
+					return
+				}
+				mu.Lock()
+				popped++
+				mu.Unlock()
+			}
+		}()
+	}
+	wg.Wait()
+	assert.Equal(t, 4000, popped)
+	assert.True(t, s.IsEmpty())
+}
+
+func TestSyncStack_Surface(t *testing.T) {
+	s := NewSyncStack[int]()
+	s.Push(1)
+	s.Push(2)
+	v, _ := s.Peek()
+	assert.Equal(t, 2, v)
+	assert.Equal(t, []int{1, 2}, s.Values())
+	assert.Equal(t, []int{1, 2}, slices.Collect(s.All()))
+	c := s.Clone()
+	s.Clear()
+	assert.True(t, s.IsEmpty())
+	assert.Equal(t, 2, c.Len())
+
+	// Do makes peek-then-pop atomic.
+	c.Do(func(st *Stack[int]) {
+		if top, _ := st.Peek(); top == 2 {
+			st.Pop()
+		}
+	})
+	assert.Equal(t, []int{1}, c.Values())
+}
+
+func TestSyncQueue_Concurrent(t *testing.T) {
+	q := NewSyncQueue[int]()
+	var wg sync.WaitGroup
+	for g := 0; g < 4; g++ {
+		wg.Add(1)
+		go func(g int) {
+			defer wg.Done()
+			for i := 0; i < 250; i++ {
+				q.Enqueue(g*1000 + i)
+			}
+		}(g)
+	}
+	wg.Wait()
+	assert.Equal(t, 1000, q.Len())
+
+	// Per-producer FIFO order survives concurrent enqueues.
+	last := map[int]int{0: -1, 1: -1, 2: -1, 3: -1}
+	for v := range q.All() {
+		g, i := v/1000, v%1000
+		assert.Greater(t, i, last[g])
+		last[g] = i
+	}
+
+	f, _ := q.Peek()
+	v, _ := q.Dequeue()
+	assert.Equal(t, f, v)
+	assert.Len(t, q.Values(), 999)
+	c := q.Clone()
+	q.Clear()
+	assert.True(t, q.IsEmpty())
+	assert.Equal(t, 999, c.Len())
+	c.Do(func(q *Queue[int]) { q.Clear() })
+	assert.True(t, c.IsEmpty())
+}
+
+func TestSyncQueue_Surface(t *testing.T) {
+	q := NewSyncQueue[int]()
+	q.Enqueue(1)
+	q.Enqueue(2)
+	v, _ := q.Peek()
+	assert.Equal(t, 1, v)
+	assert.Equal(t, []int{1, 2}, q.Values())
+	assert.Equal(t, []int{1, 2}, slices.Collect(q.All()))
+	c := q.Clone()
+	q.Clear()
+	assert.True(t, q.IsEmpty())
+	assert.Equal(t, 2, c.Len())
+
+	// Do makes peek-then-dequeue atomic.
+	c.Do(func(qu *Queue[int]) {
+		if front, _ := qu.Peek(); front == 1 {
+			qu.Dequeue()
+		}
+	})
+	assert.Equal(t, []int{2}, c.Values())
+	v, ok := c.Dequeue()
+	assert.True(t, ok)
+	assert.Equal(t, 2, v)
+	_, ok = c.Dequeue()
+	assert.False(t, ok)
+}
+
+func TestSyncHeap_Concurrent(t *testing.T) {
+	h := NewSyncMinHeap[int]()
+	var wg sync.WaitGroup
+	for g := 0; g < 4; g++ {
+		wg.Add(1)
+		go func(g int) {
+			defer wg.Done()
+			for i := 0; i < 100; i++ {
+				h.Push(g*100 + i)
+			}
+		}(g)
+	}
+	wg.Wait()
+	assert.Equal(t, 400, h.Len())
+	top, _ := h.Peek()
+	assert.Equal(t, 0, top)
+	assert.Len(t, slices.Collect(h.All()), 400)
+	assert.Len(t, h.Values(), 400)
+
+	c := h.Clone()
+	prev := -1
+	for {
+		v, ok := h.Pop()
+		if !ok {
+			break
+		}
+		assert.Greater(t, v, prev)
+		prev = v
+	}
+	assert.True(t, h.IsEmpty())
+	assert.Equal(t, 400, c.Len())
+	c.Clear()
+	assert.True(t, c.IsEmpty())
+
+	mh := NewSyncMaxHeap[int]()
+	mh.Push(1)
+	mh.Push(3)
+	mh.Do(func(h *Heap[int]) { h.Push(2) })
+	v, _ := mh.Pop()
+	assert.Equal(t, 3, v)
+	assert.True(t, NewSyncHeap(intLess).IsEmpty())
+}
+
+func TestSyncHeap_Surface(t *testing.T) {
+	h := NewSyncMaxHeap[int]()
+	for _, v := range []int{2, 3, 1} {
+		h.Push(v)
+	}
+	v, _ := h.Peek()
+	assert.Equal(t, 3, v)
+	assert.ElementsMatch(t, []int{1, 2, 3}, h.Values())
+	assert.ElementsMatch(t, []int{1, 2, 3}, slices.Collect(h.All()))
+	c := h.Clone()
+	h.Clear()
+	assert.True(t, h.IsEmpty())
+	assert.Equal(t, 3, c.Len())
+
+	// The clone keeps the max-heap order.
+	c.Do(func(hp *Heap[int]) {
+		if top, _ := hp.Peek(); top == 3 {
+			hp.Pop()
+		}
+	})
+	v, ok := c.Pop()
+	assert.True(t, ok)
+	assert.Equal(t, 2, v)
+	assert.Equal(t, 1, c.Len())
+
+	mh := NewSyncMinHeap[int]()
+	mh.Push(5)
+	mh.Push(4)
+	v, _ = mh.Pop()
+	assert.Equal(t, 4, v)
+}
+
+func TestSyncSet_Concurrent(t *testing.T) {
+	s := NewSyncSet[int]()
+	var (
+		wg    sync.WaitGroup
+		mu    sync.Mutex
+		added int
+	)
+	for g := 0; g < 8; g++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for i := 0; i < 100; i++ {
+				if s.AddIfAbsent(i) { // every value wins exactly once
+					mu.Lock()
+					added++
+					mu.Unlock()
+				}
+				s.Contains(i)
+			}
+		}()
+	}
+	wg.Wait()
+	assert.Equal(t, 100, added)
+	assert.Equal(t, 100, s.Len())
+
+	s.Add(100, 101)
+	s.Remove(0)
+	assert.False(t, s.Contains(0))
+	snap := s.Snapshot()
+	assert.True(t, snap.IsSubset(NewSet(slices.Collect(s.All())...)))
+	assert.Len(t, s.Values(), 101)
+
+	c := s.Clone()
+	s.Clear()
+	assert.True(t, s.IsEmpty())
+	assert.Equal(t, 101, c.Len())
+	c.Do(func(set Set[int]) { set.Remove(1) })
+	assert.False(t, c.Contains(1))
+}
+
+// TestSyncSet_IterateWhileMutating ranges over a snapshot and mutates the
+// same set inside the loop body: All must not hold the lock while yielding.
+func TestSyncSet_IterateWhileMutating(t *testing.T) {
+	s := NewSyncSet(1, 2, 3)
+	for v := range s.All() {
+		s.Remove(v)
+	}
+	assert.True(t, s.IsEmpty())
+}
+
+// --- BlockingQueue (run with -race) ---
+
+func TestBlockingQueue_OfferTake(t *testing.T) {
+	ctx := context.Background()
+	q := NewBlockingQueue[int](0) // unbounded
+	assert.Equal(t, 0, q.Cap())
+	for i := 0; i < 100; i++ {
+		assert.NoError(t, q.Offer(ctx, i))
+	}
+	assert.Equal(t, 100, q.Len())
+	for i := 0; i < 100; i++ {
+		v, err := q.Take(ctx)
+		assert.NoError(t, err)
+		assert.Equal(t, i, v)
+	}
+	_, ok := q.TryTake()
+	assert.False(t, ok)
+}
+
+func TestBlockingQueue_TakeBlocksUntilOffer(t *testing.T) {
+	q := NewBlockingQueue[string](1)
+	got := make(chan string)
+	go func() {
+		v, _ := q.Take(context.Background())
+		got <- v
+	}()
+	select {
+	case <-got:
+		t.Fatal("Take returned on an empty queue")
+	case <-time.After(10 * time.Millisecond):
+	}
+	assert.NoError(t, q.Offer(context.Background(), "job"))
+	assert.Equal(t, "job", <-got)
+}
+
+func TestBlockingQueue_OfferBlocksWhenFull(t *testing.T) {
+	q := NewBlockingQueue[int](2)
+	assert.Equal(t, 2, q.Cap())
+	assert.True(t, q.TryOffer(1))
+	assert.True(t, q.TryOffer(2))
+	assert.False(t, q.TryOffer(3))
+
+	done := make(chan error)
+	go func() { done <- q.Offer(context.Background(), 3) }()
+	select {
+	case <-done:
+		t.Fatal("Offer returned on a full queue")
+	case <-time.After(10 * time.Millisecond):
+	}
+	v, ok := q.TryTake()
+	assert.True(t, ok)
+	assert.Equal(t, 1, v)
+	assert.NoError(t, <-done)
+	assert.Equal(t, 2, q.Len())
+}
+
+func TestBlockingQueue_ContextCancel(t *testing.T) {
+	q := NewBlockingQueue[int](1)
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancel()
+	_, err := q.Take(ctx)
+	assert.ErrorIs(t, err, context.DeadlineExceeded)
+
+	q.TryOffer(1)
+	err = q.Offer(ctx, 2)
+	assert.ErrorIs(t, err, context.DeadlineExceeded)
+	assert.Equal(t, 1, q.Len()) // the cancelled Offer did not enqueue
+}
+
+func TestBlockingQueue_Close(t *testing.T) {
+	ctx := context.Background()
+	q := NewBlockingQueue[int](1)
+	assert.NoError(t, q.Offer(ctx, 1))
+
+	blocked := make(chan error)
+	go func() { blocked <- q.Offer(ctx, 2) }()
+	time.Sleep(5 * time.Millisecond)
+	q.Close()
+	q.Close() // idempotent
+	assert.True(t, q.IsClosed())
+	assert.ErrorIs(t, <-blocked, ErrQueueClosed)
+	assert.ErrorIs(t, q.Offer(ctx, 3), ErrQueueClosed)
+	assert.False(t, q.TryOffer(3))
+
+	// Elements queued before Close are still delivered, then ErrQueueClosed.
+	v, err := q.Take(ctx)
+	assert.NoError(t, err)
+	assert.Equal(t, 1, v)
+	_, err = q.Take(ctx)
+	assert.ErrorIs(t, err, ErrQueueClosed)
+}
+
+func TestBlockingQueue_CloseWakesTakers(t *testing.T) {
+	q := NewBlockingQueue[int](0)
+	var wg sync.WaitGroup
+	errs := make(chan error, 4)
+	for i := 0; i < 4; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			_, err := q.Take(context.Background())
+			errs <- err
+		}()
+	}
+	time.Sleep(5 * time.Millisecond)
+	q.Close()
+	wg.Wait()
+	close(errs)
+	for err := range errs {
+		assert.True(t, errors.Is(err, ErrQueueClosed))
+	}
+}
+
+// TestBlockingQueue_WorkQueue runs producers and consumers through a small
+// bounded queue and checks every item is delivered exactly once.
+func TestBlockingQueue_WorkQueue(t *testing.T) {
+	const producers, consumers, perProducer = 4, 4, 500
+	q := NewBlockingQueue[int](8)
+	ctx := context.Background()
+
+	var prod sync.WaitGroup
+	for p := 0; p < producers; p++ {
+		prod.Add(1)
+		go func(p int) {
+			defer prod.Done()
+			for i := 0; i < perProducer; i++ {
+				if err := q.Offer(ctx, p*perProducer+i); err != nil {
+					t.Errorf("Offer: %v", err)
+					return
+				}
+			}
+		}(p)
+	}
+
+	var (
+		cons sync.WaitGroup
+		mu   sync.Mutex
+		seen = map[int]int{}
+	)
+	for c := 0; c < consumers; c++ {
+		cons.Add(1)
+		go func() {
+			defer cons.Done()
+			for v := range q.Consume(ctx) {
+				mu.Lock()
+				seen[v]++
+				mu.Unlock()
+			}
+		}()
+	}
+
+	prod.Wait()
+	q.Close()
+	cons.Wait()
+	assert.Len(t, seen, producers*perProducer)
+	for v, n := range seen {
+		assert.Equal(t, 1, n, "item %d delivered %d times", v, n)
+	}
+	assert.Equal(t, 0, q.Len())
+}
+
+func TestBlockingQueue_ConsumeStopsOnCancel(t *testing.T) {
+	q := NewBlockingQueue[int](0)
+	q.TryOffer(1)
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	var got []int
+	for v := range q.Consume(ctx) {
+		got = append(got, v)
+		cancel()
+	}
+	assert.Equal(t, []int{1}, got)
+	assert.ErrorIs(t, ctx.Err(), context.Canceled)
+}
+
+// --- BloomFilter / CountMinSketch / HyperLogLog ---
+
+func TestBloomFilter_NoFalseNegatives(t *testing.T) {
+	f := NewBloomFilter(1000, 0.01)
+	for i := 0; i < 1000; i++ {
+		f.AddString(fmt.Sprintf("req-%d", i))
+	}
+	for i := 0; i < 1000; i++ {
+		assert.True(t, f.TestString(fmt.Sprintf("req-%d", i)))
+		assert.True(t, f.Test([]byte(fmt.Sprintf("req-%d", i))))
+	}
+	assert.Equal(t, uint64(1000), f.Count())
+}
+
+func TestBloomFilter_FalsePositiveRate(t *testing.T) {
+	f := NewBloomFilter(10000, 0.01)
+	assert.Equal(t, uint64(95851), f.Cap())
+	assert.Equal(t, uint64(7), f.K())
+	for i := 0; i < 10000; i++ {
+		f.AddString(fmt.Sprintf("in-%d", i))
+	}
+	fp := 0
+	for i := 0; i < 10000; i++ {
+		if f.TestString(fmt.Sprintf("out-%d", i)) {
+			fp++
+		}
+	}
+	// Target is 1%; allow generous slack for hash variance.
+	assert.Less(t, fp, 200)
+	assert.InDelta(t, 0.01, f.FalsePositiveRate(), 0.002)
+}
+
+func TestBloomFilter_TestAndAdd(t *testing.T) {
+	f := NewBloomFilter(100, 0.001)
+	assert.False(t, f.TestAndAddString("a"))
+	assert.True(t, f.TestAndAddString("a"))
+	assert.False(t, f.TestAndAdd([]byte("b")))
+	assert.True(t, f.TestString("b"))
+}
+
+func TestBloomFilter_InvalidParamsFallback(t *testing.T) {
+	f := NewBloomFilter(0, 2)
+	assert.Equal(t, NewBloomFilter(1, 0.01).Cap(), f.Cap())
+	g := NewBloomFilterWithSize(0, 0)
+	assert.Equal(t, uint64(1), g.Cap())
+	assert.Equal(t, uint64(1), g.K())
+	g.AddString("x")
+	assert.True(t, g.TestString("x"))
+}
+
+func TestBloomFilter_Union(t *testing.T) {
+	a, b := NewBloomFilter(100, 0.01), NewBloomFilter(100, 0.01)
+	a.AddString("a")
+	b.AddString("b")
+	assert.NoError(t, a.Union(b))
+	assert.True(t, a.TestString("a"))
+	assert.True(t, a.TestString("b"))
+	assert.Equal(t, uint64(2), a.Count())
+
+	c := NewBloomFilter(1000, 0.01)
+	err := a.Union(c)
+	assert.ErrorIs(t, err, ErrSketchMismatch)
+}
+
+func TestBloomFilter_ClearClone(t *testing.T) {
+	f := NewBloomFilter(100, 0.01)
+	f.AddString("a")
+	c := f.Clone()
+	f.Clear()
+	assert.False(t, f.TestString("a"))
+	assert.Equal(t, uint64(0), f.Count())
+	assert.True(t, c.TestString("a"))
+}
+
+func TestBloomFilter_Binary(t *testing.T) {
+	f := NewBloomFilter(500, 0.01)
+	for i := 0; i < 500; i++ {
+		f.AddString(fmt.Sprint(i))
+	}
+	data, err := f.MarshalBinary()
+	assert.NoError(t, err)
+
+	var g BloomFilter
+	assert.NoError(t, g.UnmarshalBinary(data))
+	assert.Equal(t, f.Cap(), g.Cap())
+	assert.Equal(t, f.K(), g.K())
+	assert.Equal(t, f.Count(), g.Count())
+	for i := 0; i < 500; i++ {
+		assert.True(t, g.TestString(fmt.Sprint(i)))
+	}
+	// A decoded filter merges with one built in this process.
+	assert.NoError(t, g.Union(NewBloomFilter(500, 0.01)))
+
+	assert.Error(t, g.UnmarshalBinary(nil))
+	assert.Error(t, g.UnmarshalBinary(data[:len(data)-1]))
+	assert.Error(t, g.UnmarshalBinary(append(data, 0)))
+	assert.Error(t, g.UnmarshalBinary([]byte("CMS1")))
+	assert.Equal(t, f.Count(), g.Count(), "failed decode leaves the filter unchanged")
+}
+
+func TestBloomFilter_BinaryCorruptHeader(t *testing.T) {
+	header := func(m, k uint64, words int) []byte {
+		out := []byte(bloomMagic)
+		out = binary.LittleEndian.AppendUint64(out, m)
+		out = binary.LittleEndian.AppendUint64(out, k)
+		out = binary.LittleEndian.AppendUint64(out, 0)
+		return append(out, make([]byte, 8*words)...)
+	}
+	cases := map[string][]byte{
+		"zero m":            header(0, 3, 1),
+		"zero k":            header(64, 0, 1),
+		"huge k":            header(64, math.MaxUint64, 1),
+		"k above cap":       header(64, maxBloomHashes+1, 1),
+		"m overflows words": header(math.MaxUint64, 3, 1),
+		"m beyond payload":  header(1<<40, 3, 1),
+		"short payload":     header(128, 3, 1),
+		"long payload":      header(64, 3, 2),
+	}
+	for name, data := range cases {
+		var g BloomFilter
+		assert.Error(t, g.UnmarshalBinary(data), name)
+	}
+
+	var g BloomFilter
+	assert.NoError(t, g.UnmarshalBinary(header(65, maxBloomHashes, 2)))
+	assert.Equal(t, uint64(maxBloomHashes), g.K())
+	assert.Equal(t, uint64(maxBloomHashes), NewBloomFilterWithSize(64, math.MaxUint64).K())
+}
+
+func TestCountMinSketch_NeverUnderestimates(t *testing.T) {
+	s := NewCountMinSketch(0.001, 0.01)
+	assert.Equal(t, uint64(2719), s.Width())
+	assert.Equal(t, uint64(5), s.Depth())
+	truth := map[string]uint64{}
+	r := rand.New(rand.NewPCG(1, 2))
+	for i := 0; i < 20000; i++ {
+		k := fmt.Sprintf("k%d", r.IntN(2000))
+		s.AddString(k, 1)
+		truth[k]++
+	}
+	assert.Equal(t, uint64(20000), s.Total())
+	bound := uint64(0.001*20000) + 1
+	over := 0
+	for k, n := range truth {
+		est := s.EstimateString(k)
+		assert.GreaterOrEqual(t, est, n)
+		if est > n+bound {
+			over++
+		}
+	}
+	assert.LessOrEqual(t, over, len(truth)/100+1)
+	assert.Equal(t, uint64(0), NewCountMinSketch(0.01, 0.01).Estimate([]byte("missing")))
+}
+
+func TestCountMinSketch_HeavyHitter(t *testing.T) {
+	s := NewCountMinSketchWithSize(64, 4)
+	s.Add([]byte("hot"), 1000)
+	for i := 0; i < 100; i++ {
+		s.AddString(fmt.Sprint(i), 1)
+	}
+	est := s.Estimate([]byte("hot"))
+	assert.GreaterOrEqual(t, est, uint64(1000))
+	assert.Less(t, est, uint64(1050))
+}
+
+func TestCountMinSketch_Merge(t *testing.T) {
+	a, b := NewCountMinSketch(0.01, 0.01), NewCountMinSketch(0.01, 0.01)
+	a.AddString("x", 3)
+	b.AddString("x", 4)
+	b.AddString("y", 1)
+	assert.NoError(t, a.Merge(b))
+	assert.GreaterOrEqual(t, a.EstimateString("x"), uint64(7))
+	assert.GreaterOrEqual(t, a.EstimateString("y"), uint64(1))
+	assert.Equal(t, uint64(8), a.Total())
+
+	assert.ErrorIs(t, a.Merge(NewCountMinSketchWithSize(10, 2)), ErrSketchMismatch)
+	assert.Equal(t, uint64(8), a.Total())
+}
+
+func TestCountMinSketch_ClearCloneBinary(t *testing.T) {
+	s := NewCountMinSketchWithSize(100, 3)
+	s.AddString("a", 5)
+	c := s.Clone()
+	s.Clear()
+	assert.Equal(t, uint64(0), s.EstimateString("a"))
+	assert.Equal(t, uint64(0), s.Total())
+	assert.Equal(t, uint64(5), c.EstimateString("a"))
+
+	data, err := c.MarshalBinary()
+	assert.NoError(t, err)
+	var d CountMinSketch
+	assert.NoError(t, d.UnmarshalBinary(data))
+	assert.Equal(t, uint64(5), d.EstimateString("a"))
+	assert.Equal(t, uint64(5), d.Total())
+	assert.NoError(t, d.Merge(c))
+	assert.Equal(t, uint64(10), d.EstimateString("a"))
+
+	assert.Error(t, d.UnmarshalBinary(data[:20]))
+	assert.Error(t, d.UnmarshalBinary(append(data, 1)))
+	assert.Equal(t, uint64(10), d.Total(), "failed decode leaves the sketch unchanged")
+}
+
+func TestSketches_ZeroValueNotUsable(t *testing.T) {
+	// The zero values are documented as unusable: queries treat them as empty,
+	// adding panics, and decoding into them is how a received sketch is restored.
+	var (
+		zbf  BloomFilter
+		zcms CountMinSketch
+		zhll HyperLogLog
+	)
+	assert.False(t, zbf.TestString("a"))
+	assert.Zero(t, zbf.FalsePositiveRate())
+	assert.Zero(t, zcms.EstimateString("a"))
+	assert.Zero(t, zhll.Count())
+	assert.PanicsWithValue(t, "collection: Add on a zero-value BloomFilter; construct it with NewBloomFilter",
+		func() { zbf.AddString("a") })
+	assert.PanicsWithValue(t, "collection: Add on a zero-value CountMinSketch; construct it with NewCountMinSketch",
+		func() { zcms.AddString("a", 1) })
+	assert.PanicsWithValue(t, "collection: Add on a zero-value HyperLogLog; construct it with NewHyperLogLog",
+		func() { zhll.AddString("a") })
+	assert.Zero(t, zcms.Total(), "a rejected Add leaves the sketch untouched")
+
+	// Merging a constructed sketch into a zero value reports a mismatch
+	// instead of panicking.
+	assert.ErrorIs(t, new(BloomFilter).Union(NewBloomFilter(10, 0.01)), ErrSketchMismatch)
+	assert.ErrorIs(t, new(CountMinSketch).Merge(NewCountMinSketch(0.01, 0.01)), ErrSketchMismatch)
+	assert.ErrorIs(t, new(HyperLogLog).Merge(NewHyperLogLog(14)), ErrSketchMismatch)
+
+	bf, cms, hll := NewBloomFilter(10, 0.01), NewCountMinSketch(0.01, 0.01), NewHyperLogLog(10)
+	bf.AddString("a")
+	cms.AddString("a", 2)
+	hll.AddString("a")
+	var (
+		bf2  BloomFilter
+		cms2 CountMinSketch
+		hll2 HyperLogLog
+	)
+	data, _ := bf.MarshalBinary()
+	assert.NoError(t, bf2.UnmarshalBinary(data))
+	assert.True(t, bf2.TestString("a"))
+	data, _ = cms.MarshalBinary()
+	assert.NoError(t, cms2.UnmarshalBinary(data))
+	assert.Equal(t, uint64(2), cms2.EstimateString("a"))
+	data, _ = hll.MarshalBinary()
+	assert.NoError(t, hll2.UnmarshalBinary(data))
+	assert.Equal(t, uint64(1), hll2.Count())
+}
+
+func TestHyperLogLog_Count(t *testing.T) {
+	h := NewHyperLogLog(14)
+	assert.Equal(t, uint64(0), h.Count())
+	for i := 0; i < 10; i++ {
+		h.AddString(fmt.Sprint(i))
+		h.AddString(fmt.Sprint(i)) // duplicates do not count
+	}
+	assert.Equal(t, uint64(10), h.Count())
+
+	for _, n := range []int{1000, 100000} {
+		h.Clear()
+		for i := 0; i < n; i++ {
+			h.Add([]byte(fmt.Sprintf("user-%d", i)))
+		}
+		// Standard error at p=14 is ~0.81%; allow 3%.
+		assert.InEpsilon(t, float64(n), float64(h.Count()), 0.03, "n=%d", n)
+	}
+}
+
+func TestHyperLogLog_Precision(t *testing.T) {
+	assert.Equal(t, uint8(14), NewHyperLogLog(0).Precision())
+	assert.Equal(t, uint8(14), NewHyperLogLog(19).Precision())
+	h := NewHyperLogLog(4)
+	assert.Equal(t, uint8(4), h.Precision())
+	for i := 0; i < 1000; i++ {
+		h.AddString(fmt.Sprint(i))
+	}
+	// 16 registers: error is ~26%, so only check the order of magnitude.
+	assert.InEpsilon(t, 1000.0, float64(h.Count()), 0.8)
+}
+
+func TestHyperLogLog_Merge(t *testing.T) {
+	a, b := NewHyperLogLog(12), NewHyperLogLog(12)
+	for i := 0; i < 6000; i++ {
+		a.AddString(fmt.Sprint(i))
+	}
+	for i := 4000; i < 10000; i++ {
+		b.AddString(fmt.Sprint(i))
+	}
+	assert.NoError(t, a.Merge(b))
+	assert.InEpsilon(t, 10000.0, float64(a.Count()), 0.05)
+
+	assert.ErrorIs(t, a.Merge(NewHyperLogLog(10)), ErrSketchMismatch)
+}
+
+func TestHyperLogLog_CloneBinary(t *testing.T) {
+	h := NewHyperLogLog(10)
+	for i := 0; i < 500; i++ {
+		h.AddString(fmt.Sprint(i))
+	}
+	c := h.Clone()
+	h.Clear()
+	assert.Equal(t, uint64(0), h.Count())
+
+	data, err := c.MarshalBinary()
+	assert.NoError(t, err)
+	var d HyperLogLog
+	assert.NoError(t, d.UnmarshalBinary(data))
+	assert.Equal(t, c.Precision(), d.Precision())
+	assert.Equal(t, c.Count(), d.Count())
+
+	bad := slices.Clone(data)
+	bad[4] = 30
+	assert.Error(t, d.UnmarshalBinary(bad))
+	assert.Error(t, d.UnmarshalBinary(data[:5]))
+	assert.Error(t, d.UnmarshalBinary([]byte("HLL")))
+	assert.Equal(t, c.Count(), d.Count(), "failed decode leaves the sketch unchanged")
+}
+
+// --- RadixTree ---
+
+func TestRadixTree_InsertGetDelete(t *testing.T) {
+	tr := NewRadixTree[int]()
+	assert.True(t, tr.IsEmpty())
+	assert.True(t, tr.Insert("/api/users", 1))
+	assert.True(t, tr.Insert("/api/orders", 2))
+	assert.True(t, tr.Insert("/api", 3))
+	assert.True(t, tr.Insert("", 4))
+	assert.False(t, tr.Insert("/api", 30))
+	assert.Equal(t, 4, tr.Len())
+
+	v, ok := tr.Get("/api")
+	assert.True(t, ok)
+	assert.Equal(t, 30, v)
+	v, ok = tr.Get("")
+	assert.True(t, ok)
+	assert.Equal(t, 4, v)
+	_, ok = tr.Get("/ap")
+	assert.False(t, ok, "an edge midpoint is not a key")
+	_, ok = tr.Get("/api/")
+	assert.False(t, ok, "an internal split node is not a key")
+	assert.True(t, tr.Contains("/api/orders"))
+	assert.False(t, tr.Contains("/api/orders/1"))
+
+	v, ok = tr.Delete("/api")
+	assert.True(t, ok)
+	assert.Equal(t, 30, v)
+	_, ok = tr.Delete("/api")
+	assert.False(t, ok)
+	_, ok = tr.Delete("/api/")
+	assert.False(t, ok)
+	assert.Equal(t, []string{"", "/api/orders", "/api/users"}, tr.Keys())
+	assert.Equal(t, []int{4, 2, 1}, tr.Values())
+
+	_, ok = tr.Delete("")
+	assert.True(t, ok)
+	assert.Equal(t, 2, tr.Len())
+}
+
+func TestRadixTree_ZeroValueUsable(t *testing.T) {
+	var tr RadixTree[string]
+	tr.Insert("a", "x")
+	v, ok := tr.Get("a")
+	assert.True(t, ok)
+	assert.Equal(t, "x", v)
+}
+
+// radixNodes counts the nodes below the root, to check that deletes keep the
+// tree compact.
+func radixNodes[V any](n *radixNode[V]) int {
+	c := len(n.children)
+	for _, ch := range n.children {
+		c += radixNodes(ch)
+	}
+	return c
+}
+
+func TestRadixTree_Compaction(t *testing.T) {
+	tr := NewRadixTree[int]()
+	tr.Insert("romane", 1)
+	tr.Insert("romanus", 2)
+	tr.Insert("romulus", 3)
+	tr.Insert("rubens", 4)
+	tr.Insert("ruber", 5)
+	tr.Insert("rubicon", 6)
+	tr.Insert("rubicundus", 7)
+	// r → om → (an → e, us), ulus; ub → e → (ns, r), ic → (on, undus)
+	assert.Equal(t, 13, radixNodes(&tr.root))
+
+	tr.Delete("romulus")
+	// "om" now has a single child "an" and merges with it into "oman".
+	assert.Equal(t, 11, radixNodes(&tr.root))
+	assert.Equal(t, []string{"romane", "romanus", "rubens", "ruber", "rubicon", "rubicundus"}, tr.Keys())
+
+	for _, k := range tr.Keys() {
+		tr.Delete(k)
+	}
+	assert.Equal(t, 0, radixNodes(&tr.root))
+	assert.True(t, tr.IsEmpty())
+
+	// A deleted key in the middle of a chain merges with its only child.
+	tr.Insert("a", 1)
+	tr.Insert("ab", 2)
+	tr.Insert("abc", 3)
+	tr.Delete("ab")
+	assert.Equal(t, 2, radixNodes(&tr.root))
+	assert.True(t, tr.Contains("abc"))
+	assert.False(t, tr.Contains("ab"))
+}
+
+func TestRadixTree_LongestPrefix(t *testing.T) {
+	routes := NewRadixTree[string]()
+	routes.Insert("/", "root")
+	routes.Insert("/api/", "api")
+	routes.Insert("/api/v1/", "v1")
+	routes.Insert("/api/v1/users", "users")
+
+	for path, want := range map[string]string{
+		"/api/v1/users":   "users",
+		"/api/v1/users/7": "users",
+		"/api/v1/orders":  "v1",
+		"/api/v2/":        "api",
+		"/api":            "root",
+		"/static/app.js":  "root",
+	} {
+		_, v, ok := routes.LongestPrefix(path)
+		assert.True(t, ok, path)
+		assert.Equal(t, want, v, path)
+	}
+	k, _, _ := routes.LongestPrefix("/api/v1/x")
+	assert.Equal(t, "/api/v1/", k)
+
+	_, _, ok := routes.LongestPrefix("api")
+	assert.False(t, ok)
+	_, _, ok = NewRadixTree[int]().LongestPrefix("")
+	assert.False(t, ok)
+}
+
+func TestRadixTree_WalkPrefix(t *testing.T) {
+	flags := NewRadixTree[bool]()
+	for _, k := range []string{"billing.v2", "billing.invoice.pdf", "billing", "search.fuzzy", "bill"} {
+		flags.Insert(k, true)
+	}
+	collect := func(prefix string) []string {
+		var out []string
+		for k := range flags.WalkPrefix(prefix) {
+			out = append(out, k)
+		}
+		return out
+	}
+	assert.Equal(t, []string{"billing", "billing.invoice.pdf", "billing.v2"}, collect("billing"))
+	assert.Equal(t, []string{"billing.invoice.pdf", "billing.v2"}, collect("billing."))
+	// The prefix may end in the middle of an edge.
+	assert.Equal(t, []string{"billing.invoice.pdf"}, collect("billing.inv"))
+	assert.Equal(t, []string{"bill", "billing", "billing.invoice.pdf", "billing.v2"}, collect("b"))
+	assert.Nil(t, collect("billing.x"))
+	assert.Nil(t, collect("z"))
+	assert.Equal(t, flags.Keys(), collect(""))
+
+	assert.True(t, flags.HasPrefix("search."))
+	assert.True(t, flags.HasPrefix("sea"))
+	assert.False(t, flags.HasPrefix("searches"))
+
+	var first []string
+	for k := range flags.All() {
+		first = append(first, k)
+		if len(first) == 2 {
+			break
+		}
+	}
+	assert.Equal(t, []string{"bill", "billing"}, first)
+}
+
+func TestRadixTree_CloneClear(t *testing.T) {
+	tr := NewRadixTree[int]()
+	tr.Insert("ab", 1)
+	tr.Insert("ac", 2)
+	c := tr.Clone()
+	tr.Insert("ad", 3)
+	tr.Delete("ab")
+	assert.Equal(t, []string{"ab", "ac"}, c.Keys())
+	assert.Equal(t, 2, c.Len())
+
+	tr.Clear()
+	assert.True(t, tr.IsEmpty())
+	assert.Nil(t, slices.Collect(maps.Keys(maps.Collect(tr.All()))))
+	assert.Equal(t, 2, c.Len())
+}
+
+func TestRadixTree_RandomAgainstMap(t *testing.T) {
+	r := rand.New(rand.NewPCG(7, 7))
+	tr := NewRadixTree[int]()
+	ref := map[string]int{}
+	alphabet := "abc/"
+	key := func() string {
+		b := make([]byte, r.IntN(6))
+		for i := range b {
+			b[i] = alphabet[r.IntN(len(alphabet))]
+		}
+		return string(b)
+	}
+	for i := 0; i < 5000; i++ {
+		k := key()
+		if r.IntN(3) == 0 {
+			_, want := ref[k]
+			_, got := tr.Delete(k)
+			assert.Equal(t, want, got)
+			delete(ref, k)
+		} else {
+			_, exists := ref[k]
+			assert.Equal(t, !exists, tr.Insert(k, i))
+			ref[k] = i
+		}
+	}
+	assert.Equal(t, len(ref), tr.Len())
+	assert.Equal(t, slices.Sorted(maps.Keys(ref)), tr.Keys())
+	for k, v := range ref {
+		got, ok := tr.Get(k)
+		assert.True(t, ok)
+		assert.Equal(t, v, got)
+	}
+	// Compact form: every non-root node without a value has at least two children.
+	var check func(n *radixNode[int])
+	check = func(n *radixNode[int]) {
+		for _, c := range n.children {
+			assert.True(t, c.leaf || len(c.children) >= 2, "uncompacted node %q", c.label)
+			check(c)
+		}
+	}
+	check(&tr.root)
+}
+
+// --- BitSet ---
+
+func TestBitSet_AddRemoveContains(t *testing.T) {
+	b := NewBitSet(1, 3, 64, 200)
+	assert.Equal(t, 4, b.Len())
+	assert.True(t, b.Contains(64))
+	assert.False(t, b.Contains(2))
+	assert.False(t, b.Contains(10000), "beyond storage is simply absent")
+
+	b.Add(3, 5).Remove(1)
+	b.Remove(100000) // no-op
+	assert.Equal(t, []uint{3, 5, 64, 200}, b.Values())
+
+	hi, ok := b.Max()
+	assert.True(t, ok)
+	assert.Equal(t, uint(200), hi)
+
+	b.Clear()
+	assert.True(t, b.IsEmpty())
+	assert.Equal(t, 0, b.Len())
+	_, ok = b.Max()
+	assert.False(t, ok)
+	b.Add(7)
+	assert.Equal(t, []uint{7}, b.Values(), "stale words are not resurrected after Clear")
+}
+
+func TestBitSet_PopCount(t *testing.T) {
+	var b BitSet
+	assert.Equal(t, 0, b.PopCount())
+	b.Add(0, 63, 64, 127, 128, 1000)
+	b.Add(64) // duplicate
+	assert.Equal(t, 6, b.PopCount())
+	assert.Equal(t, b.Len(), b.PopCount())
+	b.Remove(63)
+	assert.Equal(t, 5, b.PopCount())
+}
+
+func TestBitSet_ZeroValueUsable(t *testing.T) {
+	var b BitSet
+	assert.True(t, b.IsEmpty())
+	b.Add(130)
+	assert.True(t, b.Contains(130))
+	assert.Equal(t, 1, b.Len())
+}
+
+func TestBitSet_NextSetNextClear(t *testing.T) {
+	b := NewBitSet(0, 1, 2, 63, 64, 130)
+	var got []uint
+	for i, ok := b.NextSet(0); ok; i, ok = b.NextSet(i + 1) {
+		got = append(got, i)
+	}
+	assert.Equal(t, []uint{0, 1, 2, 63, 64, 130}, got)
+	i, ok := b.NextSet(65)
+	assert.True(t, ok)
+	assert.Equal(t, uint(130), i)
+	_, ok = b.NextSet(131)
+	assert.False(t, ok)
+
+	assert.Equal(t, uint(3), b.NextClear(0))
+	assert.Equal(t, uint(65), b.NextClear(63))
+	assert.Equal(t, uint(500), b.NextClear(500))
+
+	full := NewBitSet()
+	for i := uint(0); i < 128; i++ {
+		full.Add(i)
+	}
+	assert.Equal(t, uint(128), full.NextClear(0))
+}
+
+func TestBitSet_Algebra(t *testing.T) {
+	a := NewBitSet(1, 2, 3, 100)
+	b := NewBitSet(2, 3, 4)
+
+	assert.Equal(t, []uint{1, 2, 3, 4, 100}, a.Union(b).Values())
+	assert.Equal(t, []uint{2, 3}, a.Intersect(b).Values())
+	assert.Equal(t, []uint{1, 100}, a.Subtract(b).Values())
+	assert.Equal(t, []uint{1, 4, 100}, a.SymmetricDifference(b).Values())
+	assert.Equal(t, []uint{1, 2, 3, 100}, a.Values(), "copying algebra leaves the receiver untouched")
+	assert.Equal(t, []uint{1}, a.Union(b).Subtract(NewBitSet(4, 100)).Intersect(NewBitSet(1, 2)).Subtract(NewBitSet(2)).Values())
+
+	// In-place variants mutate and return the receiver.
+	c := a.Clone()
+	assert.Same(t, c, c.UnionWith(b))
+	assert.Equal(t, []uint{1, 2, 3, 4, 100}, c.Values())
+	c.IntersectWith(NewBitSet(3, 4, 5))
+	assert.Equal(t, []uint{3, 4}, c.Values())
+	c.SubtractWith(NewBitSet(4, 1000))
+	assert.Equal(t, []uint{3}, c.Values())
+	c.SymmetricDifferenceWith(NewBitSet(3, 9))
+	assert.Equal(t, []uint{9}, c.Values())
+	c.UnionWith(c)
+	assert.Equal(t, []uint{9}, c.Values())
+}
+
+func TestBitSet_Relations(t *testing.T) {
+	a := NewBitSet(1, 2)
+	b := NewBitSet(1, 2, 300)
+	assert.True(t, a.IsSubset(b))
+	assert.False(t, b.IsSubset(a))
+	assert.True(t, b.IsSuperset(a))
+	assert.True(t, a.IsDisjoint(NewBitSet(3, 300)))
+	assert.False(t, a.IsDisjoint(b))
+	assert.True(t, NewBitSet().IsSubset(a))
+
+	// Trailing empty words do not affect equality.
+	c := NewBitSet(1, 2, 500)
+	c.Remove(500)
+	assert.True(t, a.Equal(c))
+	assert.True(t, c.Equal(a))
+	assert.False(t, a.Equal(b))
+	assert.True(t, c.IsSubset(a))
+}
+
+func TestBitSet_Binary(t *testing.T) {
+	b := NewBitSet(0, 5, 64, 1000)
+	b.Add(5000)
+	b.Remove(5000)
+	data, err := b.MarshalBinary()
+	assert.NoError(t, err)
+	assert.Len(t, data, 4+8*16, "trailing empty words are dropped")
+
+	var c BitSet
+	assert.NoError(t, c.UnmarshalBinary(data))
+	assert.True(t, b.Equal(&c))
+
+	empty, _ := NewBitSet().MarshalBinary()
+	assert.NoError(t, c.UnmarshalBinary(empty))
+	assert.True(t, c.IsEmpty())
+
+	c.Add(1)
+	assert.Error(t, c.UnmarshalBinary(data[:len(data)-1]))
+	assert.Error(t, c.UnmarshalBinary([]byte("BLM1")))
+	assert.Equal(t, []uint{1}, c.Values(), "failed decode leaves the set unchanged")
+}
+
+func TestBitSet_JSON(t *testing.T) {
+	type perms struct {
+		Role string `json:"role"`
+		Bits BitSet `json:"bits"`
+	}
+	in := perms{Role: "admin"}
+	in.Bits.Add(0, 3, 70)
+	data, err := json.Marshal(in)
+	assert.NoError(t, err)
+	assert.JSONEq(t, `{"role":"admin","bits":"QklUMQkAAAAAAAAAQAAAAAAAAAA="}`, string(data))
+
+	var out perms
+	assert.NoError(t, json.Unmarshal(data, &out))
+	assert.Equal(t, []uint{0, 3, 70}, out.Bits.Values())
+
+	// Hand-written configs may list the members instead.
+	assert.NoError(t, json.Unmarshal([]byte(`{"bits":[2, 4, 4]}`), &out))
+	assert.Equal(t, []uint{2, 4}, out.Bits.Values())
+
+	assert.NoError(t, json.Unmarshal([]byte(`{"bits":null}`), &out))
+	assert.Equal(t, []uint{2, 4}, out.Bits.Values())
+
+	assert.Error(t, json.Unmarshal([]byte(`{"bits":"not base64!"}`), &out))
+	assert.Error(t, json.Unmarshal([]byte(`{"bits":[-1]}`), &out))
+	assert.Error(t, json.Unmarshal([]byte(`{"bits":{}}`), &out))
+
+	// Array values are bounded so untrusted input cannot force a huge bitmap.
+	assert.Error(t, json.Unmarshal([]byte(`{"bits":[18446744073709551615]}`), &out))
+	assert.Error(t, json.Unmarshal([]byte(`{"bits":[1, 16777216]}`), &out))
+	assert.Equal(t, []uint{2, 4}, out.Bits.Values(), "rejected array leaves the set unchanged")
+	assert.NoError(t, json.Unmarshal([]byte(`{"bits":[16777215]}`), &out))
+	assert.Equal(t, []uint{maxBitSetJSONValue}, out.Bits.Values())
+}
+
+func TestBitSet_All(t *testing.T) {
+	b := NewBitSet(1, 64, 65, 900)
+	assert.Equal(t, []uint{1, 64, 65, 900}, slices.Collect(b.All()))
+
+	var first []uint
+	for v := range b.All() {
+		first = append(first, v)
+		if len(first) == 2 {
+			break
+		}
+	}
+	assert.Equal(t, []uint{1, 64}, first)
+
+	// Removing the current element during iteration is safe.
+	for v := range b.All() {
+		b.Remove(v)
+	}
+	assert.True(t, b.IsEmpty())
+}
+
+func TestBitSet_RandomAgainstSet(t *testing.T) {
+	r := rand.New(rand.NewPCG(3, 9))
+	randomPair := func() (*BitSet, Set[uint]) {
+		b, s := NewBitSet(), NewSet[uint]()
+		for i := 0; i < 200; i++ {
+			v := uint(r.IntN(600))
+			b.Add(v)
+			s.Add(v)
+		}
+		return b, s
+	}
+	sorted := func(s Set[uint]) []uint {
+		out := slices.Sorted(s.All())
+		if out == nil {
+			out = []uint{}
+		}
+		return out
+	}
+	for i := 0; i < 20; i++ {
+		a, sa := randomPair()
+		b, sb := randomPair()
+		assert.Equal(t, sorted(sa.Union(sb)), a.Union(b).Values())
+		assert.Equal(t, sorted(sa.Intersect(sb)), a.Intersect(b).Values())
+		assert.Equal(t, sorted(sa.Subtract(sb)), a.Subtract(b).Values())
+		assert.Equal(t, sorted(sa.SymmetricDifference(sb)), a.SymmetricDifference(b).Values())
+		assert.Equal(t, sa.Len(), a.Len())
+	}
+}
+
+// --- Graph ---
+
+func TestGraph_AddNodeEdge(t *testing.T) {
+	g := NewGraph[string]()
+	assert.True(t, g.IsEmpty())
+	assert.True(t, g.AddEdge("a", "b"))
+	assert.False(t, g.AddEdge("a", "b"), "duplicate edges are kept once")
+	assert.True(t, g.AddEdge("a", "c"))
+	assert.True(t, g.AddNode("d"))
+	assert.False(t, g.AddNode("a"))
+	assert.Equal(t, 4, g.Len())
+	assert.Equal(t, 2, g.EdgeCount())
+	assert.Equal(t, []string{"a", "b", "c", "d"}, g.Nodes())
+	assert.Equal(t, []string{"b", "c"}, g.Successors("a"))
+	assert.Equal(t, []string{"a"}, g.Predecessors("c"))
+	assert.Nil(t, g.Successors("x"))
+	assert.True(t, g.HasEdge("a", "c"))
+	assert.False(t, g.HasEdge("c", "a"))
+	assert.True(t, g.HasNode("d"))
+
+	assert.True(t, g.RemoveEdge("a", "b"))
+	assert.False(t, g.RemoveEdge("a", "b"))
+	assert.False(t, g.RemoveEdge("a", "x"))
+	assert.Equal(t, 1, g.EdgeCount())
+	assert.Empty(t, g.Predecessors("b"))
+	assert.True(t, g.HasNode("b"), "removing an edge keeps its endpoints")
+
+	c := g.Clone()
+	g.AddEdge("c", "d")
+	assert.False(t, c.HasEdge("c", "d"))
+	g.Clear()
+	assert.True(t, g.IsEmpty())
+	assert.Equal(t, 0, g.EdgeCount())
+	assert.Equal(t, 4, c.Len())
+}
+
+func TestGraph_ZeroValueUsable(t *testing.T) {
+	var g Graph[int]
+	g.AddEdge(1, 2)
+	order, err := g.TopoSort()
+	assert.NoError(t, err)
+	assert.Equal(t, []int{1, 2}, order)
+}
+
+func TestGraph_TopoSort(t *testing.T) {
+	// Migrations: "a depends on b" is AddEdge(b, a).
+	g := NewGraph[string]()
+	g.AddNode("create_users")
+	g.AddNode("create_orders")
+	g.AddNode("seed")
+	g.AddEdge("create_users", "create_orders")
+	g.AddEdge("create_orders", "add_index")
+	g.AddEdge("create_users", "seed")
+	g.AddEdge("create_orders", "seed")
+
+	order, err := g.TopoSort()
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"create_users", "create_orders", "seed", "add_index"}, order)
+	pos := map[string]int{}
+	for i, k := range order {
+		pos[k] = i
+	}
+	for _, u := range g.Nodes() {
+		for _, v := range g.Successors(u) {
+			assert.Less(t, pos[u], pos[v], "%s -> %s", u, v)
+		}
+	}
+	assert.True(t, g.IsAcyclic())
+
+	empty, err := NewGraph[int]().TopoSort()
+	assert.NoError(t, err)
+	assert.Empty(t, empty)
+}
+
+func TestGraph_Layers(t *testing.T) {
+	g := NewGraph[string]()
+	g.AddEdge("config", "db")
+	g.AddEdge("config", "cache")
+	g.AddEdge("db", "api")
+	g.AddEdge("cache", "api")
+	g.AddEdge("config", "metrics")
+	g.AddNode("logger")
+
+	layers, err := g.Layers()
+	assert.NoError(t, err)
+	assert.Equal(t, [][]string{
+		{"config", "logger"},
+		{"db", "cache", "metrics"},
+		{"api"},
+	}, layers)
+}
+
+func TestGraph_CycleError(t *testing.T) {
+	g := NewGraph[string]()
+	g.AddEdge("init", "a")
+	g.AddEdge("a", "b")
+	g.AddEdge("b", "c")
+	g.AddEdge("c", "a")
+	g.AddEdge("c", "done")
+
+	order, err := g.TopoSort()
+	assert.Nil(t, order)
+	assert.ErrorIs(t, err, ErrCycle)
+	var ce *CycleError[string]
+	assert.True(t, errors.As(err, &ce))
+	assert.Equal(t, []string{"a", "b", "c", "a"}, ce.Path)
+	assert.EqualError(t, err, "collection: graph has a cycle: a -> b -> c -> a")
+
+	_, err = g.Layers()
+	assert.ErrorIs(t, err, ErrCycle)
+	assert.False(t, g.IsAcyclic())
+
+	// Breaking the cycle makes the graph sortable again.
+	g.RemoveEdge("c", "a")
+	order, err = g.TopoSort()
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"init", "a", "b", "c", "done"}, order)
+
+	self := NewGraph[int]()
+	self.AddEdge(1, 2)
+	self.AddEdge(2, 2)
+	_, err = self.TopoSort()
+	var ci *CycleError[int]
+	assert.True(t, errors.As(err, &ci))
+	assert.Equal(t, []int{2, 2}, ci.Path)
+}
+
+func TestGraph_CyclePathIsACycle(t *testing.T) {
+	r := rand.New(rand.NewPCG(5, 5))
+	for trial := 0; trial < 50; trial++ {
+		g := NewGraph[int]()
+		for i := 0; i < 40; i++ {
+			g.AddEdge(r.IntN(20), r.IntN(20))
+		}
+		_, err := g.TopoSort()
+		var ce *CycleError[int]
+		if !errors.As(err, &ce) {
+			assert.True(t, g.IsAcyclic())
+			continue
+		}
+		path := ce.Path
+		assert.GreaterOrEqual(t, len(path), 2)
+		assert.Equal(t, path[0], path[len(path)-1])
+		for i := 0; i+1 < len(path); i++ {
+			assert.True(t, g.HasEdge(path[i], path[i+1]), "%v", path)
+		}
+	}
+}
+
+func TestGraph_Reachability(t *testing.T) {
+	g := NewGraph[string]()
+	g.AddEdge("a", "b")
+	g.AddEdge("b", "c")
+	g.AddEdge("a", "d")
+	g.AddNode("e")
+
+	assert.True(t, g.Reachable("a", "c"))
+	assert.False(t, g.Reachable("c", "a"))
+	assert.True(t, g.Reachable("e", "e"))
+	assert.False(t, g.Reachable("a", "e"))
+	assert.False(t, g.Reachable("a", "missing"))
+
+	assert.Equal(t, []string{"b", "d", "c"}, g.Descendants("a"))
+	assert.Equal(t, []string{"b", "a"}, g.Ancestors("c"))
+	assert.Empty(t, g.Descendants("e"))
+	assert.Nil(t, g.Descendants("missing"))
+
+	g.AddEdge("c", "a")
+	assert.Contains(t, g.Descendants("a"), "a", "a node on a cycle reaches itself")
+}
+
+func TestGraph_StronglyConnectedComponents(t *testing.T) {
+	g := NewGraph[string]()
+	g.AddEdge("a", "b")
+	g.AddEdge("b", "c")
+	g.AddEdge("c", "a")
+	g.AddEdge("c", "d")
+	g.AddEdge("d", "e")
+	g.AddEdge("e", "d")
+	g.AddEdge("e", "f")
+	g.AddNode("g")
+
+	assert.Equal(t, [][]string{
+		{"g"},
+		{"a", "b", "c"},
+		{"d", "e"},
+		{"f"},
+	}, g.StronglyConnectedComponents())
+
+	dag := NewGraph[int]()
+	dag.AddEdge(1, 2)
+	dag.AddEdge(2, 3)
+	assert.Equal(t, [][]int{{1}, {2}, {3}}, dag.StronglyConnectedComponents())
+	assert.Nil(t, NewGraph[int]().StronglyConnectedComponents())
+}
+
+// --- MultiMap / BiMap ---
+
+func TestMultiMap_SetMode(t *testing.T) {
+	tags := NewMultiMap[string, int](MultiMapSet)
+	assert.Equal(t, MultiMapSet, tags.Mode())
+	assert.True(t, tags.Put("go", 1))
+	assert.False(t, tags.Put("go", 1), "set mode keeps a value once per key")
+	assert.Equal(t, 2, tags.PutAll("go", 2, 3, 3))
+	tags.PutAll("db", 2, 4)
+
+	assert.Equal(t, 5, tags.Len())
+	assert.Equal(t, 2, tags.KeyCount())
+	assert.Equal(t, 3, tags.Count("go"))
+	assert.Equal(t, 0, tags.Count("missing"))
+	assert.ElementsMatch(t, []int{1, 2, 3}, tags.Get("go"))
+	assert.Nil(t, tags.Get("missing"))
+	assert.True(t, tags.Contains("db", 4))
+	assert.False(t, tags.Contains("db", 1))
+
+	both := tags.GetSet("go").Intersect(tags.GetSet("db"))
+	assert.True(t, both.Equal(NewSet(2)))
+	assert.True(t, tags.GetSet("missing").IsEmpty())
+
+	assert.True(t, tags.Remove("db", 2))
+	assert.False(t, tags.Remove("db", 2))
+	assert.True(t, tags.Remove("db", 4))
+	assert.False(t, tags.ContainsKey("db"), "a key without values is dropped")
+	assert.Equal(t, 3, tags.Len())
+
+	assert.ElementsMatch(t, []int{1, 2, 3}, tags.Delete("go"))
+	assert.Nil(t, tags.Delete("go"))
+	assert.True(t, tags.IsEmpty())
+	assert.Equal(t, 0, tags.KeyCount())
+}
+
+func TestMultiMap_ListMode(t *testing.T) {
+	events := NewMultiMap[string, string](MultiMapList)
+	events.PutAll("order-1", "created", "paid", "created")
+	events.Put("order-2", "created")
+	assert.Equal(t, 4, events.Len())
+	assert.Equal(t, []string{"created", "paid", "created"}, events.Get("order-1"))
+	assert.Equal(t, []string{"created", "paid", "created"}, slices.Collect(events.ValuesOf("order-1")))
+
+	assert.True(t, events.Remove("order-1", "created"))
+	assert.Equal(t, []string{"paid", "created"}, events.Get("order-1"), "Remove drops the first occurrence")
+	assert.True(t, events.GetSet("order-1").Equal(NewSet("paid", "created")))
+
+	got := events.Get("order-1")
+	got[0] = "mutated"
+	assert.Equal(t, "paid", events.Get("order-1")[0], "Get returns a copy")
+
+	events.Remove("order-2", "created")
+	assert.False(t, events.ContainsKey("order-2"))
+	assert.Equal(t, []string{"order-1"}, events.Keys())
+	assert.Equal(t, 2, events.Len())
+}
+
+func TestMultiMap_ZeroValueAndInvalidMode(t *testing.T) {
+	var m MultiMap[string, int]
+	assert.Nil(t, m.Get("a"))
+	assert.True(t, m.Put("a", 1))
+	assert.False(t, m.Put("a", 1))
+	assert.Equal(t, MultiMapSet, m.Mode())
+
+	assert.Equal(t, MultiMapSet, NewMultiMap[int, int](MultiMapMode(9)).Mode())
+}
+
+func TestMultiMap_IterCloneInvert(t *testing.T) {
+	tags := NewMultiMap[string, int](MultiMapSet)
+	tags.PutAll("go", 1, 2)
+	tags.PutAll("db", 2)
+
+	pairs := map[string][]int{}
+	for k, v := range tags.All() {
+		pairs[k] = append(pairs[k], v)
+	}
+	assert.ElementsMatch(t, []int{1, 2}, pairs["go"])
+	assert.Equal(t, []int{2}, pairs["db"])
+	assert.ElementsMatch(t, []int{1, 2, 2}, tags.Values())
+	assert.ElementsMatch(t, []string{"go", "db"}, tags.Keys())
+
+	n := 0
+	for range tags.All() {
+		n++
+		break
+	}
+	assert.Equal(t, 1, n)
+
+	byID := tags.Invert()
+	assert.ElementsMatch(t, []string{"go", "db"}, byID.Get(2))
+	assert.Equal(t, []string{"go"}, byID.Get(1))
+	assert.Equal(t, 3, byID.Len())
+
+	c := tags.Clone()
+	tags.Put("go", 9)
+	tags.Clear()
+	assert.True(t, tags.IsEmpty())
+	assert.Equal(t, 3, c.Len())
+	assert.False(t, c.Contains("go", 9))
+}
+
+func TestBiMap_PutConflicts(t *testing.T) {
+	b := NewBiMap[int, string]()
+	assert.NoError(t, b.Put(1, "alice"))
+	assert.NoError(t, b.Put(2, "bob"))
+	assert.NoError(t, b.Put(1, "alice"), "re-putting the same pair is a no-op")
+
+	err := b.Put(1, "carol")
+	assert.ErrorIs(t, err, ErrBiMapConflict)
+	assert.EqualError(t, err, "collection: bimap conflict: key 1 is already mapped to alice")
+	err = b.Put(3, "bob")
+	assert.ErrorIs(t, err, ErrBiMapConflict)
+	assert.EqualError(t, err, "collection: bimap conflict: value bob is already mapped from 2")
+	assert.Equal(t, 2, b.Len(), "a rejected Put leaves the map unchanged")
+
+	v, ok := b.Get(1)
+	assert.True(t, ok)
+	assert.Equal(t, "alice", v)
+	k, ok := b.GetKey("bob")
+	assert.True(t, ok)
+	assert.Equal(t, 2, k)
+	assert.True(t, b.Contains(2))
+	assert.True(t, b.ContainsValue("alice"))
+	assert.False(t, b.ContainsValue("carol"))
+}
+
+func TestBiMap_ForcePutDelete(t *testing.T) {
+	b := NewBiMap[int, string]()
+	_ = b.Put(1, "alice")
+	_ = b.Put(2, "bob")
+
+	// 1 → bob steals bob from 2 and drops alice.
+	b.ForcePut(1, "bob")
+	assert.Equal(t, 1, b.Len())
+	k, _ := b.GetKey("bob")
+	assert.Equal(t, 1, k)
+	assert.False(t, b.Contains(2))
+	assert.False(t, b.ContainsValue("alice"))
+
+	v, ok := b.Delete(1)
+	assert.True(t, ok)
+	assert.Equal(t, "bob", v)
+	assert.False(t, b.ContainsValue("bob"))
+	_, ok = b.Delete(1)
+	assert.False(t, ok)
+
+	_ = b.Put(3, "carol")
+	k, ok = b.DeleteValue("carol")
+	assert.True(t, ok)
+	assert.Equal(t, 3, k)
+	assert.True(t, b.IsEmpty())
+}
+
+func TestBiMap_InverseView(t *testing.T) {
+	var b BiMap[int, string] // zero value is usable
+	inv := b.Inverse()
+	assert.NoError(t, b.Put(1, "alice"))
+	id, ok := inv.Get("alice")
+	assert.True(t, ok, "the inverse view sees writes made through the original")
+	assert.Equal(t, 1, id)
+
+	assert.NoError(t, inv.Put("bob", 2))
+	name, _ := b.Get(2)
+	assert.Equal(t, "bob", name, "and the original sees writes made through the view")
+	assert.ErrorIs(t, inv.Put("carol", 1), ErrBiMapConflict)
+
+	back := inv.Inverse()
+	v, _ := back.Get(1)
+	assert.Equal(t, "alice", v)
+
+	inv.Clear()
+	assert.True(t, b.IsEmpty())
+}
+
+func TestBiMap_SetsIterClone(t *testing.T) {
+	b := NewBiMap[int, string]()
+	_ = b.Put(1, "a")
+	_ = b.Put(2, "b")
+
+	assert.True(t, b.KeySet().Equal(NewSet(1, 2)))
+	assert.True(t, b.ValueSet().Equal(NewSet("a", "b")))
+	assert.ElementsMatch(t, []int{1, 2}, b.Keys())
+	assert.ElementsMatch(t, []string{"a", "b"}, b.Values())
+	assert.Equal(t, map[int]string{1: "a", 2: "b"}, maps.Collect(b.All()))
+
+	c := b.Clone()
+	assert.NoError(t, b.Inverse().Put("c", 3))
+	assert.Equal(t, 2, c.Len(), "a clone does not share storage")
+	assert.Equal(t, 3, b.Len())
+}
+
+// --- JSON / msgpack / YAML encoding ---
+
+// payload exercises every encodable container as a non-pointer and a pointer
+// struct field, the way config structs and cache values embed them.
+type payload struct {
+	Tags    Set[string]    `json:"tags" msgpack:"tags" yaml:"tags"`
+	History Stack[int]     `json:"history" msgpack:"history" yaml:"history"`
+	Jobs    *Queue[string] `json:"jobs" msgpack:"jobs" yaml:"jobs"`
+	Window  Deque[int]     `json:"window" msgpack:"window" yaml:"window"`
+	Ready   *Heap[int]     `json:"ready" msgpack:"ready" yaml:"ready"`
+}
+
+func newPayload() payload {
+	p := payload{Tags: NewSet("go", "db", "api"), Jobs: NewQueue[string](), Ready: NewMinHeap[int]()}
+	p.History.Push(1)
+	p.History.Push(2)
+	p.History.Push(3)
+	p.Jobs.Enqueue("a")
+	p.Jobs.Enqueue("b")
+	p.Window.PushBack(2)
+	p.Window.PushFront(1)
+	p.Window.PushBack(3)
+	for _, v := range []int{5, 1, 4, 2} {
+		p.Ready.Push(v)
+	}
+	return p
+}
+
+// assertPayload checks a decoded payload against newPayload, including that
+// each container still behaves correctly after decoding.
+func assertPayload(t *testing.T, p payload) {
+	t.Helper()
+	assert.True(t, p.Tags.Equal(NewSet("go", "db", "api")))
+	top, _ := p.History.Pop()
+	assert.Equal(t, 3, top)
+	assert.Equal(t, []int{1, 2}, p.History.Values())
+	p.Jobs.Enqueue("c")
+	assert.Equal(t, []string{"a", "b", "c"}, p.Jobs.Values())
+	p.Window.PushFront(0)
+	assert.Equal(t, []int{0, 1, 2, 3}, p.Window.Values())
+	var drained []int
+	for !p.Ready.IsEmpty() {
+		v, _ := p.Ready.Pop()
+		drained = append(drained, v)
+	}
+	assert.Equal(t, []int{1, 2, 4, 5}, drained)
+}
+
+func TestEncoding_JSON(t *testing.T) {
+	data, err := json.Marshal(newPayload())
+	assert.NoError(t, err)
+	assert.JSONEq(t, `{
+		"tags": ["api", "db", "go"],
+		"history": [1, 2, 3],
+		"jobs": ["a", "b"],
+		"window": [1, 2, 3],
+		"ready": [1, 2, 4, 5]
+	}`, string(data))
+
+	out := payload{Ready: NewMinHeap[int]()}
+	assert.NoError(t, json.Unmarshal(data, &out))
+	assertPayload(t, out)
+}
+
+func TestEncoding_Msgpack(t *testing.T) {
+	// msgpack.Marshal/Unmarshal is exactly what cache/v3's codec does.
+	data, err := msgpack.Marshal(newPayload())
+	assert.NoError(t, err)
+	out := payload{Ready: NewMinHeap[int]()}
+	assert.NoError(t, msgpack.Unmarshal(data, &out))
+	assertPayload(t, out)
+
+	// Containers are also valid top-level cache values.
+	b, err := msgpack.Marshal(NewSet(3, 1, 2))
+	assert.NoError(t, err)
+	var s Set[int]
+	assert.NoError(t, msgpack.Unmarshal(b, &s))
+	assert.True(t, s.Equal(NewSet(1, 2, 3)))
+
+	var vals []int
+	assert.NoError(t, msgpack.Unmarshal(b, &vals), "a set is a plain msgpack array")
+	assert.Equal(t, []int{1, 2, 3}, vals)
+}
+
+func TestEncoding_YAML(t *testing.T) {
+	data, err := yaml.Marshal(newPayload())
+	assert.NoError(t, err)
+	assert.Equal(t, `tags:
+    - api
+    - db
+    - go
+history:
+    - 1
+    - 2
+    - 3
+jobs:
+    - a
+    - b
+window:
+    - 1
+    - 2
+    - 3
+ready:
+    - 1
+    - 2
+    - 4
+    - 5
+`, string(data))
+
+	out := payload{Ready: NewMinHeap[int]()}
+	assert.NoError(t, yaml.Unmarshal(data, &out))
+	assertPayload(t, out)
+}
+
+func TestEncoding_SetIsDeterministic(t *testing.T) {
+	vals := make([]int, 100)
+	for i := range vals {
+		vals[i] = i * 7 % 101
+	}
+	first, _ := json.Marshal(NewSet(vals...))
+	for i := 0; i < 10; i++ {
+		slices.Reverse(vals)
+		again, _ := json.Marshal(NewSet(vals...))
+		assert.Equal(t, string(first), string(again))
+	}
+	type point struct{ X, Y int }
+	data, err := json.Marshal(NewSet(point{2, 1}, point{1, 2}))
+	assert.NoError(t, err)
+	assert.Equal(t, `[{"X":1,"Y":2},{"X":2,"Y":1}]`, string(data))
+}
+
+func TestEncoding_EmptyAndNull(t *testing.T) {
+	var s Set[int]
+	var st Stack[int]
+	var q Queue[int]
+	var d Deque[int]
+	h := NewMaxHeap[int]()
+	for _, v := range []any{s, st, q, d, h} {
+		data, err := json.Marshal(v)
+		assert.NoError(t, err)
+		assert.Equal(t, "[]", string(data), "%T", v)
+	}
+
+	// An empty array yields a usable empty container.
+	assert.NoError(t, json.Unmarshal([]byte(`[]`), &q))
+	q.Enqueue(1)
+	assert.Equal(t, []int{1}, q.Values())
+	assert.NoError(t, json.Unmarshal([]byte(`[]`), &s))
+	s.Add(1)
+	assert.True(t, s.Contains(1))
+
+	// null leaves the container untouched.
+	assert.NoError(t, json.Unmarshal([]byte(`null`), &q))
+	assert.Equal(t, []int{1}, q.Values())
+	assert.NoError(t, json.Unmarshal([]byte(`null`), &s))
+	assert.Equal(t, 1, s.Len())
+
+	// YAML null behaves the same, both through the yaml package and when
+	// UnmarshalYAML is handed a null node directly.
+	assert.NoError(t, yaml.Unmarshal([]byte(`null`), &q))
+	assert.Equal(t, []int{1}, q.Values())
+	var cfg struct {
+		Jobs Queue[int] `yaml:"jobs"`
+	}
+	cfg.Jobs.Enqueue(1)
+	assert.NoError(t, yaml.Unmarshal([]byte("jobs: ~"), &cfg))
+	assert.Equal(t, []int{1}, cfg.Jobs.Values())
+	var null yaml.Node
+	assert.NoError(t, yaml.Unmarshal([]byte(`null`), &null))
+	assert.NoError(t, q.UnmarshalYAML(null.Content[0]))
+	assert.Equal(t, []int{1}, q.Values())
+	assert.NoError(t, s.UnmarshalYAML(null.Content[0]))
+	assert.Equal(t, 1, s.Len())
+
+	// So does msgpack nil when DecodeMsgpack sees it.
+	nilData, _ := msgpack.Marshal(nil)
+	assert.NoError(t, q.DecodeMsgpack(msgpack.NewDecoder(bytes.NewReader(nilData))))
+	assert.Equal(t, []int{1}, q.Values())
+	mh := NewMinHeap[int]()
+	mh.Push(1)
+	assert.NoError(t, json.Unmarshal([]byte(`null`), mh))
+	assert.NoError(t, mh.UnmarshalYAML(null.Content[0]))
+	assert.NoError(t, mh.DecodeMsgpack(msgpack.NewDecoder(bytes.NewReader(nilData))))
+	assert.Equal(t, 1, mh.Len())
+
+	// Decoding replaces previous contents.
+	assert.NoError(t, json.Unmarshal([]byte(`[7, 8]`), &q))
+	assert.Equal(t, []int{7, 8}, q.Values())
+	assert.NoError(t, json.Unmarshal([]byte(`[1, 1, 2]`), &s))
+	assert.True(t, s.Equal(NewSet(1, 2)))
+}
+
+func TestEncoding_HeapRequiresLess(t *testing.T) {
+	var h Heap[int]
+	assert.ErrorContains(t, json.Unmarshal([]byte(`[1, 2]`), &h), "without less")
+	assert.Error(t, yaml.Unmarshal([]byte("[1, 2]"), &h))
+	b, _ := msgpack.Marshal([]int{1, 2})
+	assert.Error(t, msgpack.Unmarshal(b, &h))
+
+	// Input order does not matter: the heap is rebuilt with its own less.
+	mh := NewMaxHeap[int]()
+	mh.Push(100)
+	assert.NoError(t, json.Unmarshal([]byte(`[3, 9, 1, 7]`), mh))
+	assert.Equal(t, 4, mh.Len())
+	top, _ := mh.Peek()
+	assert.Equal(t, 9, top)
+	data, _ := json.Marshal(mh)
+	assert.Equal(t, "[9,7,3,1]", string(data))
+}
+
+func TestEncoding_MinMaxHeapZeroValueFields(t *testing.T) {
+	type config struct {
+		Low  MinHeap[int]    `json:"low" msgpack:"low" yaml:"low"`
+		High MaxHeap[string] `json:"high" msgpack:"high" yaml:"high"`
+	}
+	drain := func(c config) ([]int, []string) {
+		var low []int
+		for !c.Low.IsEmpty() {
+			v, _ := c.Low.Pop()
+			low = append(low, v)
+		}
+		var high []string
+		for !c.High.IsEmpty() {
+			v, _ := c.High.Pop()
+			high = append(high, v)
+		}
+		return low, high
+	}
+
+	// Zero-value fields decode without being constructed first, in every codec.
+	var fromJSON config
+	assert.NoError(t, json.Unmarshal([]byte(`{"low":[3,1,2],"high":["a","c","b"]}`), &fromJSON))
+	var fromYAML config
+	assert.NoError(t, yaml.Unmarshal([]byte("low: [3, 1, 2]\nhigh: [a, c, b]\n"), &fromYAML))
+	var in config
+	for _, v := range []int{3, 1, 2} {
+		in.Low.Push(v)
+	}
+	for _, v := range []string{"a", "c", "b"} {
+		in.High.Push(v)
+	}
+	data, err := json.Marshal(in)
+	assert.NoError(t, err)
+	assert.JSONEq(t, `{"low":[1,2,3],"high":["c","b","a"]}`, string(data))
+	b, err := msgpack.Marshal(in)
+	assert.NoError(t, err)
+	var fromMsgpack config
+	assert.NoError(t, msgpack.Unmarshal(b, &fromMsgpack))
+
+	for _, c := range []config{fromJSON, fromYAML, fromMsgpack, in} {
+		low, high := drain(c)
+		assert.Equal(t, []int{1, 2, 3}, low)
+		assert.Equal(t, []string{"c", "b", "a"}, high)
+	}
+
+	// msgpack nil resets the field to its zero value, which is still usable.
+	nilData, _ := msgpack.Marshal(map[string]any{"low": nil})
+	assert.NoError(t, msgpack.Unmarshal(nilData, &in))
+	assert.True(t, in.Low.IsEmpty())
+	in.Low.Push(5)
+	in.Low.Push(4)
+	top, _ := in.Low.Peek()
+	assert.Equal(t, 4, top)
+}
+
+func TestEncoding_Errors(t *testing.T) {
+	var s Set[int]
+	assert.Error(t, json.Unmarshal([]byte(`{"a":1}`), &s))
+	assert.Error(t, json.Unmarshal([]byte(`["x"]`), &s))
+	var st Stack[int]
+	assert.Error(t, yaml.Unmarshal([]byte("a: 1"), &st))
+	var q Queue[int]
+	b, _ := msgpack.Marshal("not an array")
+	assert.Error(t, msgpack.Unmarshal(b, &q))
+
+	// A corrupt array header claiming 2^32-1 elements fails on the missing data
+	// instead of allocating for the declared length first.
+	huge := []byte{0xdd, 0xff, 0xff, 0xff, 0xff, 0x01}
+	assert.Error(t, msgpack.Unmarshal(huge, &q))
+	assert.Error(t, msgpack.Unmarshal(huge, &st))
+	var before, after runtime.MemStats
+	runtime.ReadMemStats(&before)
+	assert.Error(t, msgpack.Unmarshal(huge, &s))
+	runtime.ReadMemStats(&after)
+	assert.Less(t, after.TotalAlloc-before.TotalAlloc, uint64(1<<20))
+}
+
+// --- iter.Seq integration ---
+
+func TestIter_Stack(t *testing.T) {
+	s := NewStack[int]()
+	s.Push(1)
+	s.Push(2)
+	s.Push(3)
+	var got []int
+	for v := range s.All() {
+		got = append(got, v)
+	}
+	assert.Equal(t, []int{1, 2, 3}, got) // bottom-to-top
+}
+
+func TestIter_Stack_EarlyBreak(t *testing.T) {
+	s := NewStack[int]()
+	for i := 0; i < 5; i++ {
+		s.Push(i)
+	}
+	n := 0
+	for range s.All() {
+		n++
+		if n == 2 {
+			break
+		}
+	}
+	assert.Equal(t, 2, n)
+}
+
+func TestIter_Queue(t *testing.T) {
+	q := NewQueue[int]()
+	q.Enqueue(1)
+	q.Enqueue(2)
+	q.Enqueue(3)
+	var got []int
+	for v := range q.All() {
+		got = append(got, v)
+	}
+	assert.Equal(t, []int{1, 2, 3}, got) // front-to-back
+}
+
+func TestIter_Heap(t *testing.T) {
+	h := NewMinHeap[int]()
+	h.Push(3)
+	h.Push(1)
+	h.Push(2)
+	got := slices.Collect(h.All()) // order is heap layout, not sorted
+	assert.ElementsMatch(t, []int{1, 2, 3}, got)
+}
+
+func TestIter_Set(t *testing.T) {
+	s := NewSet(1, 2, 3)
+	got := slices.Collect(s.All())
+	assert.ElementsMatch(t, []int{1, 2, 3}, got)
+}
+
+func TestIter_Set_EarlyBreak(t *testing.T) {
+	s := NewSet(1, 2, 3, 4, 5)
+	n := 0
+	for range s.All() {
+		n++
+		if n == 3 {
+			break
+		}
+	}
+	assert.Equal(t, 3, n)
+}
+
+func TestIter_Deque(t *testing.T) {
+	d := NewDequeWithCap[int](2)
+	d.PushBack(2)
+	d.PushFront(1)
+	d.PushBack(3)
+	assert.Equal(t, []int{1, 2, 3}, slices.Collect(d.All()))      // front-to-back
+	assert.Equal(t, []int{3, 2, 1}, slices.Collect(d.Backward())) // back-to-front
+
+	n := 0
+	for range d.Backward() {
+		n++
+		if n == 2 {
+			break
+		}
+	}
+	assert.Equal(t, 2, n)
+}
+
+func TestIter_RingBuffer(t *testing.T) {
+	r := NewRingBuffer[int](3, RingOverwrite)
+	for i := 0; i < 5; i++ {
+		r.Push(i)
+	}
+	assert.Equal(t, []int{2, 3, 4}, slices.Collect(r.All())) // oldest-to-newest
+}
+
+func TestIter_SortedMap(t *testing.T) {
+	m := NewSortedMapOrdered[string, int]()
+	m.Put("b", 2)
+	m.Put("a", 1)
+	m.Put("c", 3)
+	var keys []string
+	var vals []int
+	for k, v := range m.All() {
+		keys = append(keys, k)
+		vals = append(vals, v)
+	}
+	assert.Equal(t, []string{"a", "b", "c"}, keys) // ascending
+	assert.Equal(t, []int{1, 2, 3}, vals)
+
+	n := 0
+	for range m.All() {
+		n++
+		if n == 2 {
+			break
+		}
+	}
+	assert.Equal(t, 2, n)
+}
+
+func TestIter_SortedSet(t *testing.T) {
+	s := NewSortedSetOrdered(3, 1, 2)
+	assert.Equal(t, []int{1, 2, 3}, slices.Collect(s.All()))
+}
+
+func TestIter_IndexedHeap(t *testing.T) {
+	h := NewIndexedMinHeap[string, int]()
+	h.Push("a", 1)
+	h.Push("b", 2)
+	got := map[string]int{}
+	for k, v := range h.All() { // order is heap layout
+		got[k] = v
+	}
+	assert.Equal(t, map[string]int{"a": 1, "b": 2}, got)
+}
+
+func TestIter_OrderedMap(t *testing.T) {
+	m := NewOrderedMap[string, int](InsertionOrder)
+	m.Put("b", 2)
+	m.Put("a", 1)
+	var keys []string
+	for k := range m.All() {
+		keys = append(keys, k)
+	}
+	assert.Equal(t, []string{"b", "a"}, keys) // insertion order
+	keys = nil
+	for k := range m.Backward() {
+		keys = append(keys, k)
+		break
+	}
+	assert.Equal(t, []string{"a"}, keys)
+}
+
+// --- benchmarks ---
+
+func BenchmarkStack_PushPop(b *testing.B) {
+	s := NewStack[int]()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		s.Push(i)
+		s.Pop()
+	}
+}
+
+func BenchmarkQueue_EnqueueDequeue(b *testing.B) {
+	q := NewQueue[int]()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		q.Enqueue(i)
+		q.Dequeue()
+	}
+}
+
+// BenchmarkQueue_LongLived models a queue that stays alive across many
+// enqueue/dequeue pairs — the exact workload v2 leaked under.
+func BenchmarkQueue_LongLived(b *testing.B) {
+	q := NewQueueWithCap[int](8)
+	b.ReportAllocs()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		q.Enqueue(i)
+		q.Dequeue()
+	}
+}
+
+func BenchmarkHeap_PushPop(b *testing.B) {
+	h := NewMinHeap[int]()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		h.Push(i)
+		h.Pop()
+	}
+}
+
+func BenchmarkSet_Add(b *testing.B) {
+	s := NewSetWithCap[int](b.N)
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		s.Add(i)
+	}
+}
+
+func BenchmarkSet_Contains(b *testing.B) {
+	s := NewSet[int]()
+	for i := 0; i < 10000; i++ {
+		s.Add(i)
+	}
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		s.Contains(i % 10000)
+	}
+}
+
+func BenchmarkSet_Intersect(b *testing.B) {
+	a := NewSet[int]()
+	bb := NewSet[int]()
+	for i := 0; i < 1000; i++ {
 		a.Add(i)
 		bb.Add(i + 500)
 	}
@@ -722,3 +3495,241 @@ func BenchmarkSet_Intersect(b *testing.B) {
 		a.Intersect(bb)
 	}
 }
+
+func BenchmarkDeque_PushPopFront(b *testing.B) {
+	d := NewDeque[int]()
+	b.ReportAllocs()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		d.PushFront(i)
+		d.PopBack()
+	}
+}
+
+func BenchmarkRingBuffer_Overwrite(b *testing.B) {
+	r := NewRingBuffer[int](128, RingOverwrite)
+	b.ReportAllocs()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		r.Push(i)
+	}
+}
+
+func BenchmarkSortedMap_Put(b *testing.B) {
+	m := NewSortedMapOrdered[int, int]()
+	rng := rand.New(rand.NewPCG(1, 2))
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		m.Put(rng.IntN(1<<20), i)
+	}
+}
+
+func BenchmarkSortedMap_Get(b *testing.B) {
+	m := NewSortedMapOrdered[int, int]()
+	for i := 0; i < 10000; i++ {
+		m.Put(i, i)
+	}
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		m.Get(i % 10000)
+	}
+}
+
+// BenchmarkSortedMap_Range and BenchmarkMap_SortedKeysRange compare a range
+// query of 100 keys out of 10000 against the sort-the-map-keys-by-hand
+// approach it replaces.
+func BenchmarkSortedMap_Range(b *testing.B) {
+	m := NewSortedMapOrdered[int, int]()
+	for i := 0; i < 10000; i++ {
+		m.Put(i, i)
+	}
+	b.ReportAllocs()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		sum := 0
+		for _, v := range m.Range(5000, 5100) {
+			sum += v
+		}
+	}
+}
+
+func BenchmarkMap_SortedKeysRange(b *testing.B) {
+	m := make(map[int]int, 10000)
+	for i := 0; i < 10000; i++ {
+		m[i] = i
+	}
+	b.ReportAllocs()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		sum := 0
+		for _, k := range slices.Sorted(maps.Keys(m)) {
+			if k >= 5000 && k < 5100 {
+				sum += m[k]
+			}
+		}
+	}
+}
+
+func BenchmarkSortedMap_Delete(b *testing.B) {
+	m := NewSortedMapOrdered[int, int]()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		b.StopTimer()
+		m.Put(i, i)
+		b.StartTimer()
+		m.Delete(i)
+	}
+}
+
+func BenchmarkIndexedHeap_Update(b *testing.B) {
+	h := NewIndexedMinHeap[int, int]()
+	for i := 0; i < 10000; i++ {
+		h.Push(i, i)
+	}
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		h.Update(i%10000, (i*7919)%10000)
+	}
+}
+
+func BenchmarkBlockingQueue_OfferTake(b *testing.B) {
+	q := NewBlockingQueue[int](128)
+	ctx := context.Background()
+	b.ReportAllocs()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		_ = q.Offer(ctx, i)
+		_, _ = q.Take(ctx)
+	}
+}
+
+func BenchmarkBlockingQueue_ProducerConsumer(b *testing.B) {
+	q := NewBlockingQueue[int](128)
+	ctx := context.Background()
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		for range q.Consume(ctx) {
+		}
+	}()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		_ = q.Offer(ctx, i)
+	}
+	q.Close()
+	<-done
+}
+
+func BenchmarkBloomFilter_AddTest(b *testing.B) {
+	f := NewBloomFilter(b.N+1, 0.01)
+	keys := make([]string, 1024)
+	for i := range keys {
+		keys[i] = fmt.Sprintf("request-%d", i)
+	}
+	b.ReportAllocs()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		f.TestAndAddString(keys[i%len(keys)])
+	}
+}
+
+func BenchmarkCountMinSketch_Add(b *testing.B) {
+	s := NewCountMinSketch(0.001, 0.01)
+	keys := make([]string, 1024)
+	for i := range keys {
+		keys[i] = fmt.Sprintf("key-%d", i)
+	}
+	b.ReportAllocs()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		s.AddString(keys[i%len(keys)], 1)
+	}
+}
+
+func BenchmarkHyperLogLog_Add(b *testing.B) {
+	h := NewHyperLogLog(14)
+	keys := make([]string, 1024)
+	for i := range keys {
+		keys[i] = fmt.Sprintf("user-%d", i)
+	}
+	b.ReportAllocs()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		h.AddString(keys[i%len(keys)])
+	}
+}
+
+func BenchmarkRadixTree_LongestPrefix(b *testing.B) {
+	tr := NewRadixTree[int]()
+	for i := 0; i < 1000; i++ {
+		tr.Insert(fmt.Sprintf("/api/v%d/resource%d/", i%10, i), i)
+	}
+	b.ReportAllocs()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		tr.LongestPrefix("/api/v3/resource503/items/42")
+	}
+}
+
+// BenchmarkLinearPrefixScan is the baseline RadixTree.LongestPrefix replaces.
+func BenchmarkLinearPrefixScan(b *testing.B) {
+	prefixes := make([]string, 1000)
+	for i := range prefixes {
+		prefixes[i] = fmt.Sprintf("/api/v%d/resource%d/", i%10, i)
+	}
+	b.ReportAllocs()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		best := ""
+		for _, p := range prefixes {
+			if len(p) > len(best) && strings.HasPrefix("/api/v3/resource503/items/42", p) {
+				best = p
+			}
+		}
+		_ = best
+	}
+}
+
+func BenchmarkBitSet_Intersect(b *testing.B) {
+	x, y := NewBitSet(), NewBitSet()
+	for i := uint(0); i < 4096; i += 2 {
+		x.Add(i)
+	}
+	for i := uint(0); i < 4096; i += 3 {
+		y.Add(i)
+	}
+	b.ReportAllocs()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		x.Clone().IntersectWith(y)
+	}
+}
+
+// BenchmarkSet_IntersectDense is the map-backed baseline for the same data.
+func BenchmarkSet_IntersectDense(b *testing.B) {
+	x, y := NewSet[uint](), NewSet[uint]()
+	for i := uint(0); i < 4096; i += 2 {
+		x.Add(i)
+	}
+	for i := uint(0); i < 4096; i += 3 {
+		y.Add(i)
+	}
+	b.ReportAllocs()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		x.Intersect(y)
+	}
+}
+
+func BenchmarkGraph_TopoSort(b *testing.B) {
+	g := NewGraph[int]()
+	for i := 0; i < 10000; i++ {
+		g.AddEdge(i, i+1)
+		g.AddEdge(i, i+7)
+	}
+	b.ReportAllocs()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		_, _ = g.TopoSort()
+	}
+}
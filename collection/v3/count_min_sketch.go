@@ -0,0 +1,151 @@
+package collection
+
+import (
+	"encoding/binary"
+	"fmt"
+	"math"
+)
+
+// CountMinSketch 是一个频次估算结构:[CountMinSketch.Estimate] 返回的计数不小于真实值,且
+// 以概率 1-delta 不超过真实值 + epsilon·Total。内存为 width×depth 个计数器,与不同元素的
+// 数量无关,适合热点 key、按用户限频等只需近似频次的场景。
+//
+// 参数相同的 sketch 可用 [CountMinSketch.Merge] 合并,并可经 MarshalBinary/UnmarshalBinary
+// 在实例之间传递。它不是 goroutine 安全的。
+//
+// 零值不可用:查询方法把它当作空 sketch,Add 会 panic。请使用 [NewCountMinSketch]/
+// [NewCountMinSketchWithSize] 构造,或以 [CountMinSketch.UnmarshalBinary] 解码得到。
+type CountMinSketch struct {
+	counts []uint64 // depth 行 × width 列,按行存放
+	width  uint64
+	depth  uint64
+	total  uint64
+}
+
+const countMinMagic = "CMS1"
+
+// NewCountMinSketch 按误差 epsilon 与失败概率 delta 创建一个空 sketch:width = ⌈e/epsilon⌉,
+// depth = ⌈ln(1/delta)⌉。epsilon 不在 (0, 1) 内时回退到 0.001,delta 不在 (0, 1) 内时回退到
+// 0.01。
+func NewCountMinSketch(epsilon, delta float64) *CountMinSketch {
+	if !(epsilon > 0 && epsilon < 1) {
+		epsilon = 0.001
+	}
+	if !(delta > 0 && delta < 1) {
+		delta = 0.01
+	}
+	width := math.Ceil(math.E / epsilon)
+	depth := math.Ceil(math.Log(1 / delta))
+	return NewCountMinSketchWithSize(uint64(width), uint64(depth))
+}
+
+// NewCountMinSketchWithSize 以 width 列、depth 行创建一个空 sketch。width 与 depth 至少为 1。
+func NewCountMinSketchWithSize(width, depth uint64) *CountMinSketch {
+	width, depth = max(width, 1), max(depth, 1)
+	return &CountMinSketch{counts: make([]uint64, width*depth), width: width, depth: depth}
+}
+
+// Add 将 data 的计数增加 count。
+func (s *CountMinSketch) Add(data []byte, count uint64) { s.add(sketchHash(data), count) }
+
+// AddString 将 s 的计数增加 count,不为其分配 []byte。
+func (s *CountMinSketch) AddString(str string, count uint64) { s.add(sketchHashString(str), count) }
+
+// Estimate 返回 data 计数的估算值,不小于真实值。
+func (s *CountMinSketch) Estimate(data []byte) uint64 { return s.estimate(sketchHash(data)) }
+
+// EstimateString 返回 s 计数的估算值,不小于真实值。
+func (s *CountMinSketch) EstimateString(str string) uint64 {
+	return s.estimate(sketchHashString(str))
+}
+
+// Total 返回所有 Add 的 count 之和。
+func (s *CountMinSketch) Total() uint64 { return s.total }
+
+// Width 返回列数。
+func (s *CountMinSketch) Width() uint64 { return s.width }
+
+// Depth 返回行数。
+func (s *CountMinSketch) Depth() uint64 { return s.depth }
+
+// Merge 将 other 的计数累加到 s:结果等价于在 s 上重放 other 的全部 Add。两者的 width 与
+// depth 必须相同,否则返回 [ErrSketchMismatch] 且 s 不变。
+func (s *CountMinSketch) Merge(other *CountMinSketch) error {
+	if s.width != other.width || s.depth != other.depth {
+		return fmt.Errorf("%w: count-min sketch %dx%d vs %dx%d", ErrSketchMismatch, s.width, s.depth, other.width, other.depth)
+	}
+	for i, c := range other.counts {
+		s.counts[i] += c
+	}
+	s.total += other.total
+	return nil
+}
+
+// Clear 将所有计数归零,参数保持不变。
+func (s *CountMinSketch) Clear() {
+	clear(s.counts)
+	s.total = 0
+}
+
+// Clone 返回 sketch 的独立副本。
+func (s *CountMinSketch) Clone() *CountMinSketch {
+	out := *s
+	out.counts = append([]uint64(nil), s.counts...)
+	return &out
+}
+
+// MarshalBinary 将 sketch 编码为字节序列(参数与计数器,小端序),实现 [encoding.BinaryMarshaler]。
+func (s *CountMinSketch) MarshalBinary() ([]byte, error) {
+	out := make([]byte, 0, len(countMinMagic)+24+8*len(s.counts))
+	out = append(out, countMinMagic...)
+	out = binary.LittleEndian.AppendUint64(out, s.width)
+	out = binary.LittleEndian.AppendUint64(out, s.depth)
+	out = binary.LittleEndian.AppendUint64(out, s.total)
+	for _, c := range s.counts {
+		out = binary.LittleEndian.AppendUint64(out, c)
+	}
+	return out, nil
+}
+
+// UnmarshalBinary 从 [CountMinSketch.MarshalBinary] 的输出还原 sketch,实现
+// [encoding.BinaryUnmarshaler]。数据损坏时返回错误且 s 不变。
+func (s *CountMinSketch) UnmarshalBinary(data []byte) error {
+	r := newSketchReader("CountMinSketch", countMinMagic, data)
+	width, depth, total := r.uint64(), r.uint64(), r.uint64()
+	if r.err == nil && (width == 0 || depth == 0 || width > uint64(len(data))/depth) {
+		return fmt.Errorf("collection: decode CountMinSketch: invalid size %dx%d", width, depth)
+	}
+	raw := r.bytes(8 * width * depth)
+	if err := r.done(); err != nil {
+		return err
+	}
+	counts := make([]uint64, width*depth)
+	for i := range counts {
+		counts[i] = binary.LittleEndian.Uint64(raw[8*i:])
+	}
+	*s = CountMinSketch{counts: counts, width: width, depth: depth, total: total}
+	return nil
+}
+
+func (s *CountMinSketch) add(h, count uint64) {
+	if s.width == 0 {
+		panic("collection: Add on a zero-value CountMinSketch; construct it with NewCountMinSketch")
+	}
+	h1, h2 := doubleHash(h)
+	for row := uint64(0); row < s.depth; row++ {
+		s.counts[row*s.width+(h1+row*h2)%s.width] += count
+	}
+	s.total += count
+}
+
+func (s *CountMinSketch) estimate(h uint64) uint64 {
+	if s.depth == 0 {
+		return 0
+	}
+	h1, h2 := doubleHash(h)
+	est := uint64(math.MaxUint64)
+	for row := uint64(0); row < s.depth; row++ {
+		est = min(est, s.counts[row*s.width+(h1+row*h2)%s.width])
+	}
+	return est
+}
@@ -0,0 +1,132 @@
+package collection
+
+// Deque 是一个基于 ring buffer 的双端 queue:两端的 Push/Pop 都是均摊 O(1),
+// 按下标随机访问 [Deque.At] 是 O(1)。
+//
+// 与 [Queue] 一样,出队的槽位会被回收并清零,buffer 填满时扩容到 2× 并将环绕的内容
+// 重新线性化到前端,因此长期两端进出的 deque 内存保持有界。
+type Deque[T any] struct {
+	buf   []T
+	head  int // 前端元素的索引
+	count int // 元素数量
+}
+
+// NewDeque 创建一个使用默认初始 capacity 的空 deque。
+func NewDeque[T any]() *Deque[T] {
+	return NewDequeWithCap[T](defaultCap)
+}
+
+// NewDequeWithCap 创建一个为 cap 个元素预分配大小的空 deque。非正的 cap 会回退到默认 capacity。
+func NewDequeWithCap[T any](cap int) *Deque[T] {
+	if cap <= 0 {
+		cap = defaultCap
+	}
+	return &Deque[T]{buf: make([]T, cap)}
+}
+
+// PushFront 将 v 添加到 deque 的前端。均摊 O(1)。
+func (d *Deque[T]) PushFront(v T) {
+	if d.count == len(d.buf) {
+		d.grow()
+	}
+	d.head = (d.head - 1 + len(d.buf)) % len(d.buf)
+	d.buf[d.head] = v
+	d.count++
+}
+
+// PushBack 将 v 添加到 deque 的后端。均摊 O(1)。
+func (d *Deque[T]) PushBack(v T) {
+	if d.count == len(d.buf) {
+		d.grow()
+	}
+	d.buf[d.index(d.count)] = v
+	d.count++
+}
+
+// PopFront 移除并返回前端元素。当 deque 为空时返回 (zero, false)。出队的槽位会被清零,
+// 以便 GC 回收它持有的任何引用。
+func (d *Deque[T]) PopFront() (T, bool) {
+	var zero T
+	if d.count == 0 {
+		return zero, false
+	}
+	v := d.buf[d.head]
+	d.buf[d.head] = zero
+	d.head = (d.head + 1) % len(d.buf)
+	d.count--
+	return v, true
+}
+
+// PopBack 移除并返回后端元素。当 deque 为空时返回 (zero, false)。出队的槽位会被清零。
+func (d *Deque[T]) PopBack() (T, bool) {
+	var zero T
+	if d.count == 0 {
+		return zero, false
+	}
+	i := d.index(d.count - 1)
+	v := d.buf[i]
+	d.buf[i] = zero
+	d.count--
+	return v, true
+}
+
+// PeekFront 返回前端元素但不移除它。当 deque 为空时返回 (zero, false)。
+func (d *Deque[T]) PeekFront() (T, bool) {
+	return d.At(0)
+}
+
+// PeekBack 返回后端元素但不移除它。当 deque 为空时返回 (zero, false)。
+func (d *Deque[T]) PeekBack() (T, bool) {
+	return d.At(d.count - 1)
+}
+
+// At 返回从前端数起第 i 个元素(0 为前端)。i 越界时返回 (zero, false)。O(1)。
+func (d *Deque[T]) At(i int) (T, bool) {
+	var zero T
+	if i < 0 || i >= d.count {
+		return zero, false
+	}
+	return d.buf[d.index(i)], true
+}
+
+// Len 返回元素数量。
+func (d *Deque[T]) Len() int { return d.count }
+
+// IsEmpty 报告 deque 是否没有元素。
+func (d *Deque[T]) IsEmpty() bool { return d.count == 0 }
+
+// Clear 移除所有元素并清零 buffer。buffer 的 capacity 保留以便复用。
+func (d *Deque[T]) Clear() {
+	clear(d.buf)
+	d.head, d.count = 0, 0
+}
+
+// Values 返回按从前到后顺序排列的元素副本。返回的 slice 是独立的;修改它不会影响 deque。
+func (d *Deque[T]) Values() []T {
+	out := make([]T, d.count)
+	for i := range out {
+		out[i] = d.buf[d.index(i)]
+	}
+	return out
+}
+
+// Clone 返回 deque 的独立副本。即便源 deque 发生环绕,副本仍线性排列(head=0)。
+func (d *Deque[T]) Clone() *Deque[T] {
+	out := make([]T, max(d.count, defaultCap))
+	copy(out, d.Values())
+	return &Deque[T]{buf: out, count: d.count}
+}
+
+// index 将逻辑下标 i(0 为前端)映射为 buf 中的物理下标。
+func (d *Deque[T]) index(i int) int {
+	return (d.head + i) % len(d.buf)
+}
+
+// grow 将 buffer 翻倍并线性化环绕的内容,使 head=0。仅在 buffer 填满时调用。
+func (d *Deque[T]) grow() {
+	next := make([]T, max(len(d.buf)*2, defaultCap))
+	n := copy(next, d.buf[d.head:])
+	copy(next[n:], d.buf[:d.count-n])
+	d.buf = next
+	d.head = 0
+}
@@ -1,23 +1,53 @@
 // Package collection 提供 generic、对分配感知的数据结构：
-// [Stack]、[Queue]、[Heap] 和 [Set]。
+// [Stack]、[Queue]、[Deque]、[RingBuffer]、[Heap]、[IndexedHeap]、[Set]、[BitSet],有序的
+// [SortedMap] 与 [SortedSet],保持插入/访问顺序的 [OrderedMap],按 string 前缀查找的
+// [RadixTree],key → 多值的 [MultiMap] 与双向一一映射 [BiMap],以及用于依赖排序的
+// 有向图 [Graph]。
 //
-// V3 是 collection 模块的从头重写,与 v2 不兼容。设计遵循三个目标 —— 合理、高效、易用 —— 通过以下方式实现：
+// V3 是 collection 模块的从头重写,与 v2 不兼容。设计遵循三个目标 —— 合理、高效、
+// 易用 —— 通过以下方式实现：
 //
 //   - 更好的数据结构：[Queue] 是 ring buffer(修复了 v2 的 slice[1:] 内存
-//     泄漏);[Heap] 为 [cmp.Ordered] 类型暴露 [NewMinHeap]/[NewMaxHeap];
-//     [Set] 将其 backing map 隐藏在 struct 之后,使布局可演进而不破坏调用方。
+//     泄漏);[IndexedHeap] 按 key 索引元素,支持 O(log n) 的改优先级与取消;
+//     [Deque] 在同样的 ring buffer 上支持两端进出;[RingBuffer] 固定
+//     capacity,填满时覆盖最旧元素或拒绝新元素;[SortedMap]/[SortedSet] 以
+//     skip list 按 key 排序,提供 Floor/Ceiling/Rank 与区间遍历;[Heap] 为
+//     [cmp.Ordered] 类型暴露 [NewMinHeap]/[NewMaxHeap];[Set] 将其 backing map
+//     隐藏在 struct 之后,使布局可演进而不破坏调用方。
 //   - 更好的惯用法：每个容器都暴露 [Stack.All]/[Queue.All]/
 //     [Heap.All]/[Set.All] 返回 [iter.Seq],因此 `for v := range s.All()` 可用,
-//     且容器可与标准库的 [slices]/[maps] 包组合使用。[Stack.Pop]/[Queue.Dequeue]/[Heap.Peek]
-//     返回 (T, bool);零值会被清零,以便 GC 回收引用。
+//     且容器可与标准库的 [slices]/[maps] 包组合使用。[Stack.Pop]/
+//     [Queue.Dequeue]/[Heap.Peek] 返回 (T, bool);零值会被清零,以便 GC 回收引用。
 //   - 更易使用：[Set] 支持方法链式代数
-//     (`a.Union(b).Intersect(c)`) 并搭配自由函数别名,[Heap] 有 cmp.Ordered 构造器,因此调用方几乎不必手写 `less` 函数。
+//     (`a.Union(b).Intersect(c)`) 并搭配自由函数别名,[Heap]、[SortedMap]、
+//     [SortedSet] 有 cmp.Ordered 构造器,因此调用方几乎不必手写 `less` 函数。
 //
 // 模块边界:
 //
 //	保序去重 / 成员判定 → functional/v3.OrderedSet。
-//	无序集合代数(union/intersect/diff)→ 本包的 Set。
+//	保序映射(插入序 / 访问序,确定的 JSON 输出)→ 本包的 OrderedMap。
+//	按 key 大小排序的映射 → 本包的 SortedMap。
+//	按 string 前缀查找(最长前缀匹配、前缀遍历)→ 本包的 RadixTree。
+//	无序集合代数(union/intersect/diff)→ 本包的 Set;密集的小整数 ID → 本包的 BitSet。
+//	key → 多个值 → 本包的 MultiMap;双向一一映射(ID ↔ 名称)→ 本包的 BiMap。
+//	依赖排序(拓扑序、分层并行、环检测)→ 本包的 Graph。
 //	slice 级 FP(Map/Filter/Reduce on []T)→ functional/v3。
 //
 // 所有操作都是指针接收者方法;若需共享状态,请在修改前用 Clone 复制容器。
+//
+// 上述容器都不是 goroutine 安全的。跨 goroutine 共享时使用 Sync 版本 —— [SyncStack]、
+// [SyncQueue]、[SyncHeap]、[SyncSet] —— 它们用一把锁包住对应容器,复合操作经各自的 Do
+// 方法在锁内执行。goroutine 之间的工作队列使用 [BlockingQueue]:Take/Offer 按 ctx 阻塞,
+// 可设 capacity 上限,Close 后排空再以 [ErrQueueClosed] 结束消费者。
+//
+// [Set]、[Stack]、[Queue]、[Deque]、[Heap] 实现 JSON、msgpack(github.com/vmihailenco/msgpack/v5,
+// 与 cache/v3 的 codec 兼容)与 YAML(gopkg.in/yaml.v3)编解码,编码为元素数组,可直接用作
+// 配置结构体字段、API 载荷与缓存值。Heap 的 less 不随数据编码,解码前须先构造:零值的
+// Heap 字段(以及解码器为 nil *Heap 分配的新值)无法解码,会返回错误。有序元素的字段
+// 请使用 [MinHeap]/[MaxHeap],它们的零值即可解码;自定义 less 时以 NewHeap 初始化该字段。
+//
+// 概率结构以固定内存换取近似答案:[BloomFilter] 判定成员(无假阴性,假阳性率按构造参数)、
+// [CountMinSketch] 估算频次(只会高估)、[HyperLogLog] 估算基数。三者使用固定种子的哈希,
+// 参数相同的实例可以合并(Union/Merge),状态经 MarshalBinary/UnmarshalBinary 在进程间传递;
+// 参数不同时合并返回 [ErrSketchMismatch]。
 package collection
@@ -0,0 +1,377 @@
+package collection
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"slices"
+
+	"github.com/vmihailenco/msgpack/v5"
+	"gopkg.in/yaml.v3"
+)
+
+// 本文件为 [Set]、[Stack]、[Queue]、[Deque]、[Heap](及 [MinHeap]/[MaxHeap])实现 JSON、
+// msgpack 与 YAML 编解码,使它们可以出现在配置结构体、API 载荷与缓存 blob 中。三种格式都编码
+// 为元素数组:
+//
+//   - Set:元素按其 JSON 编码的字节序排列,相同内容的 set 总是得到相同的输出。
+//   - Stack:从底部到顶部,解码后 Pop 的顺序与编码前相同。
+//   - Queue / Deque:从前端到后端。
+//   - Heap:按优先级顺序(Pop 的顺序)。
+//
+// 编码方法使用值接收者,使非指针的结构体字段也按此编码;解码方法替换原有内容,遇到 JSON null、
+// YAML null 与 msgpack nil 时都不修改容器。例外:msgpack.Unmarshal 在读到 nil 时由库自身
+// 将目标置为零值,不会调用 DecodeMsgpack(Heap 因此也失去 less;零值可用的 MinHeap/MaxHeap
+// 不受影响)。零值 Heap 无法解码,结构体字段请优先使用 MinHeap/MaxHeap。msgpack 编解码基于
+// github.com/vmihailenco/msgpack/v5,与 cache/v3 的 codec 兼容,容器可以直接作为缓存的值。
+
+// errHeapNoLess 在解码到没有 less 函数的 Heap(零值)时返回。
+var errHeapNoLess = errors.New("collection: cannot decode into a Heap without less; construct it with NewHeap/NewMinHeap/NewMaxHeap first")
+
+// MarshalJSON 将 set 编码为 JSON 数组,元素按其 JSON 编码排序。
+func (s Set[T]) MarshalJSON() ([]byte, error) {
+	return json.Marshal(s.canonical())
+}
+
+// UnmarshalJSON 从 JSON 数组解码,替换 set 原有的内容;重复的元素只保留一个。
+func (s *Set[T]) UnmarshalJSON(data []byte) error {
+	vals, err := unmarshalJSONArray[T](data)
+	if err != nil || vals == nil {
+		return err
+	}
+	*s = NewSet(vals...)
+	return nil
+}
+
+// EncodeMsgpack 将 set 编码为 msgpack 数组,顺序同 [Set.MarshalJSON],实现 [msgpack.CustomEncoder]。
+func (s Set[T]) EncodeMsgpack(enc *msgpack.Encoder) error {
+	return enc.Encode(s.canonical())
+}
+
+// DecodeMsgpack 从 msgpack 数组解码,替换 set 原有的内容,实现 [msgpack.CustomDecoder]。
+func (s *Set[T]) DecodeMsgpack(dec *msgpack.Decoder) error {
+	vals, err := decodeMsgpackArray[T](dec)
+	if err != nil || vals == nil {
+		return err
+	}
+	*s = NewSet(vals...)
+	return nil
+}
+
+// MarshalYAML 将 set 编码为 YAML 序列,顺序同 [Set.MarshalJSON],实现 [yaml.Marshaler]。
+func (s Set[T]) MarshalYAML() (any, error) {
+	return s.canonical(), nil
+}
+
+// UnmarshalYAML 从 YAML 序列解码,替换 set 原有的内容,实现 [yaml.Unmarshaler]。
+func (s *Set[T]) UnmarshalYAML(node *yaml.Node) error {
+	vals, err := decodeYAMLSeq[T](node)
+	if err != nil || vals == nil {
+		return err
+	}
+	*s = NewSet(vals...)
+	return nil
+}
+
+// MarshalJSON 将 stack 编码为 JSON 数组,从底部到顶部。
+func (s Stack[T]) MarshalJSON() ([]byte, error) {
+	return json.Marshal(nonNil(s.data))
+}
+
+// UnmarshalJSON 从 JSON 数组解码(第一个元素在底部),替换 stack 原有的内容。
+func (s *Stack[T]) UnmarshalJSON(data []byte) error {
+	vals, err := unmarshalJSONArray[T](data)
+	if err != nil || vals == nil {
+		return err
+	}
+	s.data = vals
+	return nil
+}
+
+// EncodeMsgpack 将 stack 编码为 msgpack 数组,从底部到顶部。
+func (s Stack[T]) EncodeMsgpack(enc *msgpack.Encoder) error {
+	return enc.Encode(nonNil(s.data))
+}
+
+// DecodeMsgpack 从 msgpack 数组解码(第一个元素在底部),替换 stack 原有的内容。
+func (s *Stack[T]) DecodeMsgpack(dec *msgpack.Decoder) error {
+	vals, err := decodeMsgpackArray[T](dec)
+	if err != nil || vals == nil {
+		return err
+	}
+	s.data = vals
+	return nil
+}
+
+// MarshalYAML 将 stack 编码为 YAML 序列,从底部到顶部。
+func (s Stack[T]) MarshalYAML() (any, error) {
+	return nonNil(s.data), nil
+}
+
+// UnmarshalYAML 从 YAML 序列解码(第一个元素在底部),替换 stack 原有的内容。
+func (s *Stack[T]) UnmarshalYAML(node *yaml.Node) error {
+	vals, err := decodeYAMLSeq[T](node)
+	if err != nil || vals == nil {
+		return err
+	}
+	s.data = vals
+	return nil
+}
+
+// MarshalJSON 将 queue 编码为 JSON 数组,从前端到后端。
+func (q Queue[T]) MarshalJSON() ([]byte, error) {
+	return json.Marshal(q.Values())
+}
+
+// UnmarshalJSON 从 JSON 数组解码(第一个元素在前端),替换 queue 原有的内容。
+func (q *Queue[T]) UnmarshalJSON(data []byte) error {
+	vals, err := unmarshalJSONArray[T](data)
+	if err != nil || vals == nil {
+		return err
+	}
+	*q = Queue[T]{buf: vals, count: len(vals)}
+	return nil
+}
+
+// EncodeMsgpack 将 queue 编码为 msgpack 数组,从前端到后端。
+func (q Queue[T]) EncodeMsgpack(enc *msgpack.Encoder) error {
+	return enc.Encode(q.Values())
+}
+
+// DecodeMsgpack 从 msgpack 数组解码(第一个元素在前端),替换 queue 原有的内容。
+func (q *Queue[T]) DecodeMsgpack(dec *msgpack.Decoder) error {
+	vals, err := decodeMsgpackArray[T](dec)
+	if err != nil || vals == nil {
+		return err
+	}
+	*q = Queue[T]{buf: vals, count: len(vals)}
+	return nil
+}
+
+// MarshalYAML 将 queue 编码为 YAML 序列,从前端到后端。
+func (q Queue[T]) MarshalYAML() (any, error) {
+	return q.Values(), nil
+}
+
+// UnmarshalYAML 从 YAML 序列解码(第一个元素在前端),替换 queue 原有的内容。
+func (q *Queue[T]) UnmarshalYAML(node *yaml.Node) error {
+	vals, err := decodeYAMLSeq[T](node)
+	if err != nil || vals == nil {
+		return err
+	}
+	*q = Queue[T]{buf: vals, count: len(vals)}
+	return nil
+}
+
+// MarshalJSON 将 deque 编码为 JSON 数组,从前端到后端。
+func (d Deque[T]) MarshalJSON() ([]byte, error) {
+	return json.Marshal(d.Values())
+}
+
+// UnmarshalJSON 从 JSON 数组解码(第一个元素在前端),替换 deque 原有的内容。
+func (d *Deque[T]) UnmarshalJSON(data []byte) error {
+	vals, err := unmarshalJSONArray[T](data)
+	if err != nil || vals == nil {
+		return err
+	}
+	*d = Deque[T]{buf: vals, count: len(vals)}
+	return nil
+}
+
+// EncodeMsgpack 将 deque 编码为 msgpack 数组,从前端到后端。
+func (d Deque[T]) EncodeMsgpack(enc *msgpack.Encoder) error {
+	return enc.Encode(d.Values())
+}
+
+// DecodeMsgpack 从 msgpack 数组解码(第一个元素在前端),替换 deque 原有的内容。
+func (d *Deque[T]) DecodeMsgpack(dec *msgpack.Decoder) error {
+	vals, err := decodeMsgpackArray[T](dec)
+	if err != nil || vals == nil {
+		return err
+	}
+	*d = Deque[T]{buf: vals, count: len(vals)}
+	return nil
+}
+
+// MarshalYAML 将 deque 编码为 YAML 序列,从前端到后端。
+func (d Deque[T]) MarshalYAML() (any, error) {
+	return d.Values(), nil
+}
+
+// UnmarshalYAML 从 YAML 序列解码(第一个元素在前端),替换 deque 原有的内容。
+func (d *Deque[T]) UnmarshalYAML(node *yaml.Node) error {
+	vals, err := decodeYAMLSeq[T](node)
+	if err != nil || vals == nil {
+		return err
+	}
+	*d = Deque[T]{buf: vals, count: len(vals)}
+	return nil
+}
+
+// MarshalJSON 将 heap 编码为 JSON 数组,按优先级顺序(最先出堆的在前)。
+func (h Heap[T]) MarshalJSON() ([]byte, error) {
+	return json.Marshal(h.sorted())
+}
+
+// UnmarshalJSON 从 JSON 数组解码,替换 heap 原有的内容;元素顺序不限,按 heap 的 less 重新
+// 建堆(O(n))。less 不随数据编码,因此 heap 必须先由 NewHeap/NewMinHeap/NewMaxHeap 构造,
+// 对零值 Heap 解码会返回错误。
+func (h *Heap[T]) UnmarshalJSON(data []byte) error {
+	vals, err := unmarshalJSONArray[T](data)
+	if err != nil || vals == nil {
+		return err
+	}
+	return h.reset(vals)
+}
+
+// EncodeMsgpack 将 heap 编码为 msgpack 数组,按优先级顺序。
+func (h Heap[T]) EncodeMsgpack(enc *msgpack.Encoder) error {
+	return enc.Encode(h.sorted())
+}
+
+// DecodeMsgpack 从 msgpack 数组解码并重新建堆,规则同 [Heap.UnmarshalJSON]。注意
+// msgpack.Unmarshal 读到 nil 时不会调用本方法,而是由库把 Heap 置为零值 —— less 随之丢失,
+// 与"null 不修改容器"的约定不同;[MinHeap]/[MaxHeap] 的零值仍可用,不受影响。
+func (h *Heap[T]) DecodeMsgpack(dec *msgpack.Decoder) error {
+	vals, err := decodeMsgpackArray[T](dec)
+	if err != nil || vals == nil {
+		return err
+	}
+	return h.reset(vals)
+}
+
+// MarshalYAML 将 heap 编码为 YAML 序列,按优先级顺序。
+func (h Heap[T]) MarshalYAML() (any, error) {
+	return h.sorted(), nil
+}
+
+// UnmarshalYAML 从 YAML 序列解码并重新建堆,规则同 [Heap.UnmarshalJSON]。
+func (h *Heap[T]) UnmarshalYAML(node *yaml.Node) error {
+	vals, err := decodeYAMLSeq[T](node)
+	if err != nil || vals == nil {
+		return err
+	}
+	return h.reset(vals)
+}
+
+// UnmarshalJSON 从 JSON 数组解码并按自然顺序重新建堆;零值 MinHeap 也可解码。
+func (h *MinHeap[T]) UnmarshalJSON(data []byte) error { return h.init().UnmarshalJSON(data) }
+
+// DecodeMsgpack 从 msgpack 数组解码并按自然顺序重新建堆;零值 MinHeap 也可解码。
+func (h *MinHeap[T]) DecodeMsgpack(dec *msgpack.Decoder) error { return h.init().DecodeMsgpack(dec) }
+
+// UnmarshalYAML 从 YAML 序列解码并按自然顺序重新建堆;零值 MinHeap 也可解码。
+func (h *MinHeap[T]) UnmarshalYAML(node *yaml.Node) error { return h.init().UnmarshalYAML(node) }
+
+// UnmarshalJSON 从 JSON 数组解码并按逆自然顺序重新建堆;零值 MaxHeap 也可解码。
+func (h *MaxHeap[T]) UnmarshalJSON(data []byte) error { return h.init().UnmarshalJSON(data) }
+
+// DecodeMsgpack 从 msgpack 数组解码并按逆自然顺序重新建堆;零值 MaxHeap 也可解码。
+func (h *MaxHeap[T]) DecodeMsgpack(dec *msgpack.Decoder) error { return h.init().DecodeMsgpack(dec) }
+
+// UnmarshalYAML 从 YAML 序列解码并按逆自然顺序重新建堆;零值 MaxHeap 也可解码。
+func (h *MaxHeap[T]) UnmarshalYAML(node *yaml.Node) error { return h.init().UnmarshalYAML(node) }
+
+// canonical 返回按 JSON 编码字节序排列的元素,使 map 的随机遍历顺序不影响编码结果。有元素
+// 无法编码为 JSON 时按遍历顺序返回,由调用方的编码器报告错误。
+func (s Set[T]) canonical() []T {
+	type keyed struct {
+		key []byte
+		val T
+	}
+	items := make([]keyed, 0, len(s.m))
+	for v := range s.m {
+		key, err := json.Marshal(v)
+		if err != nil {
+			return s.Values()
+		}
+		items = append(items, keyed{key, v})
+	}
+	slices.SortFunc(items, func(a, b keyed) int { return bytes.Compare(a.key, b.key) })
+	out := make([]T, len(items))
+	for i, it := range items {
+		out[i] = it.val
+	}
+	return out
+}
+
+// sorted 返回按优先级排列的元素副本。
+func (h *Heap[T]) sorted() []T {
+	out := slices.Clone(nonNil(h.data))
+	if h.less != nil {
+		slices.SortStableFunc(out, func(a, b T) int {
+			switch {
+			case h.less(a, b):
+				return -1
+			case h.less(b, a):
+				return 1
+			}
+			return 0
+		})
+	}
+	return out
+}
+
+// reset 以 vals 替换 heap 的内容并自底向上建堆。
+func (h *Heap[T]) reset(vals []T) error {
+	if h.less == nil {
+		return errHeapNoLess
+	}
+	h.data = vals
+	for i := len(vals)/2 - 1; i >= 0; i-- {
+		h.siftDown(i)
+	}
+	return nil
+}
+
+// unmarshalJSONArray 解码 JSON 数组;JSON null 返回 nil,空数组返回非 nil 的空 slice。
+func unmarshalJSONArray[T any](data []byte) ([]T, error) {
+	if bytes.Equal(bytes.TrimSpace(data), []byte("null")) {
+		return nil, nil
+	}
+	var vals []T
+	if err := json.Unmarshal(data, &vals); err != nil {
+		return nil, err
+	}
+	return nonNil(vals), nil
+}
+
+// maxMsgpackPrealloc 是按 msgpack 数组头部声明的长度预分配的元素数上限。长度来自不可信
+// 的输入,超出部分随实际读到的元素逐步增长,损坏或恶意的头部不会触发巨大的分配。
+const maxMsgpackPrealloc = 1024
+
+// decodeYAMLSeq 解码 YAML 序列;null 返回 nil,空序列返回非 nil 的空 slice。
+func decodeYAMLSeq[T any](node *yaml.Node) ([]T, error) {
+	if node.Kind == yaml.ScalarNode && node.ShortTag() == "!!null" {
+		return nil, nil
+	}
+	var vals []T
+	if err := node.Decode(&vals); err != nil {
+		return nil, err
+	}
+	return nonNil(vals), nil
+}
+
+// decodeMsgpackArray 解码 msgpack 数组;nil 返回 nil,空数组返回非 nil 的空 slice。
+func decodeMsgpackArray[T any](dec *msgpack.Decoder) ([]T, error) {
+	n, err := dec.DecodeArrayLen()
+	if err != nil || n < 0 {
+		return nil, err
+	}
+	vals := make([]T, 0, min(n, maxMsgpackPrealloc))
+	for range n {
+		var v T
+		if err := dec.Decode(&v); err != nil {
+			return nil, err
+		}
+		vals = append(vals, v)
+	}
+	return vals, nil
+}
+
+func nonNil[T any](s []T) []T {
+	if s == nil {
+		return []T{}
+	}
+	return s
+}
@@ -1,8 +1,16 @@
-// Example: collection/v3 — Stack, Queue (ring buffer), Heap, and Set.
+// Example: collection/v3 — Stack, Queue (ring buffer), Deque, RingBuffer,
+// Heap, IndexedHeap, Set, BitSet, SortedMap/SortedSet, OrderedMap,
+// RadixTree, MultiMap/BiMap, Graph, the concurrent variants, the
+// probabilistic sketches, and JSON/YAML encoding.
 package main
 
 import (
+	"context"
+	"encoding/json"
+	"errors"
 	"fmt"
+	"slices"
+	"sync"
 
 	"github.com/tenz-io/gokit/collection/v3"
 )
@@ -10,8 +18,20 @@ import (
 func main() {
 	stack()
 	queue()
+	deque()
+	ringBuffer()
 	heap()
+	indexedHeap()
 	setAlgebra()
+	bitSet()
+	sorted()
+	orderedMap()
+	radixTree()
+	multiMaps()
+	graph()
+	workQueue()
+	sketches()
+	encoding()
 }
 
 func stack() {
@@ -56,6 +76,45 @@ func queue() {
 	fmt.Printf("Queue:   drained %d enqueue/dequeue pairs, len=%d\n", 1_000_000, q.Len())
 }
 
+func deque() {
+	// Sliding-window maximum: the deque keeps indices of a decreasing run.
+	nums := []int{1, 3, -1, -3, 5, 3, 6, 7}
+	const k = 3
+	d := collection.NewDeque[int]()
+	var maxes []int
+	for i, v := range nums {
+		for back, ok := d.PeekBack(); ok && nums[back] <= v; back, ok = d.PeekBack() {
+			d.PopBack()
+		}
+		d.PushBack(i)
+		if front, _ := d.PeekFront(); front <= i-k {
+			d.PopFront()
+		}
+		if i >= k-1 {
+			front, _ := d.PeekFront()
+			maxes = append(maxes, nums[front])
+		}
+	}
+	fmt.Printf("Deque:   window-%d maxima of %v -> %v\n", k, nums, maxes)
+}
+
+func ringBuffer() {
+	// Keep only the last 4 latencies; older samples are overwritten.
+	latencies := collection.NewRingBuffer[int](4, collection.RingOverwrite)
+	for _, ms := range []int{12, 15, 11, 40, 38, 13} {
+		latencies.Push(ms)
+	}
+	sum := 0
+	for ms := range latencies.All() {
+		sum += ms
+	}
+	fmt.Printf("Ring:    last %d latencies %v avg=%dms\n", latencies.Len(), latencies.Values(), sum/latencies.Len())
+
+	// A bounded inbox that refuses new work when full.
+	inbox := collection.NewRingBuffer[string](2, collection.RingReject)
+	fmt.Printf("         reject mode: %v %v %v\n", inbox.Push("a"), inbox.Push("b"), inbox.Push("c"))
+}
+
 func heap() {
 	// Min-heap over ints: drains smallest-first.
 	h := collection.NewMinHeap[int]()
@@ -85,6 +144,25 @@ func mustTop(h *collection.Heap[string]) string {
 	return v
 }
 
+func indexedHeap() {
+	// A scheduler keyed by job ID: reschedule and cancel pending jobs.
+	sched := collection.NewIndexedMinHeap[string, int]() // value = run-at second
+	sched.Push("backup", 300)
+	sched.Push("report", 120)
+	sched.Push("cleanup", 60)
+	sched.Update("backup", 30) // run the backup sooner
+	sched.Remove("cleanup")    // cancelled
+	var order []string
+	for {
+		id, _, ok := sched.Pop()
+		if !ok {
+			break
+		}
+		order = append(order, id)
+	}
+	fmt.Printf("Indexed: run order %v\n", order)
+}
+
 func setAlgebra() {
 	// Chained set algebra: roles of users.
 	devs := collection.NewSet("alice", "bob", "carol")
@@ -111,3 +189,200 @@ func setAlgebra() {
 	}
 	fmt.Printf("         stopped after %d elements\n", n)
 }
+
+func bitSet() {
+	// Permission bits: what a role may do, and the first unassigned shard ID.
+	const (
+		read uint = iota
+		write
+		admin
+		billing
+	)
+	role := collection.NewBitSet(read, write, billing)
+	required := collection.NewBitSet(read, admin)
+	fmt.Printf("BitSet:  missing permissions = %v\n", required.Subtract(role).Values())
+
+	shards := collection.NewBitSet(0, 1, 2, 4, 5)
+	data, _ := json.Marshal(shards)
+	fmt.Printf("         next free shard = %d, json = %s\n", shards.NextClear(0), data)
+}
+
+func sorted() {
+	// Latency histogram buckets keyed by upper bound (ms): find the bucket for
+	// a sample with Ceiling, and report a key range without sorting by hand.
+	buckets := collection.NewSortedMapOrdered[int, int]()
+	for _, ub := range []int{10, 50, 100, 500, 1000} {
+		buckets.Put(ub, 0)
+	}
+	for _, ms := range []int{3, 42, 42, 97, 180, 730} {
+		if ub, n, ok := buckets.Ceiling(ms); ok {
+			buckets.Put(ub, n+1)
+		}
+	}
+	fmt.Printf("Sorted:  ")
+	for ub, n := range buckets.Range(50, 1000) {
+		fmt.Printf("<=%dms:%d ", ub, n)
+	}
+	fmt.Println()
+
+	// Leaderboard rank with a SortedSet.
+	scores := collection.NewSortedSetOrdered(88, 95, 72, 61)
+	fmt.Printf("         score 88 beats %d others; top = %v\n", scores.Rank(88), mustMax(scores))
+}
+
+func mustMax(s *collection.SortedSet[int]) int {
+	v, _ := s.Max()
+	return v
+}
+
+func orderedMap() {
+	// Deterministic JSON: keys come out in insertion order.
+	m := collection.NewOrderedMap[string, int](collection.InsertionOrder)
+	m.Put("zeta", 1)
+	m.Put("alpha", 2)
+	m.Put("mid", 3)
+	b, _ := json.Marshal(m)
+	fmt.Printf("Ordered: %s\n", b)
+
+	// Access order: a "recently viewed" list capped at 3 entries.
+	recent := collection.NewOrderedMap[string, struct{}](collection.AccessOrder)
+	for _, page := range []string{"home", "docs", "blog", "home", "pricing"} {
+		recent.Put(page, struct{}{})
+		if recent.Len() > 3 {
+			recent.PopFront()
+		}
+	}
+	fmt.Printf("         recently viewed (oldest first) = %v\n", recent.Keys())
+}
+
+func radixTree() {
+	// Longest-prefix routing and namespace listing without scanning every key.
+	routes := collection.NewRadixTree[string]()
+	routes.Insert("/", "static")
+	routes.Insert("/api/", "api")
+	routes.Insert("/api/v1/users", "users")
+	label := "Radix:  "
+	for _, path := range []string{"/api/v1/users/7", "/api/v2/orders", "/index.html"} {
+		prefix, handler, _ := routes.LongestPrefix(path)
+		fmt.Printf("%s %-16s -> %-6s (matched %q)\n", label, path, handler, prefix)
+		label = "        "
+	}
+
+	flags := collection.NewRadixTree[bool]()
+	flags.Insert("billing.invoice.pdf", true)
+	flags.Insert("billing.v2", false)
+	flags.Insert("search.fuzzy", true)
+	var billing []string
+	for k := range flags.WalkPrefix("billing.") {
+		billing = append(billing, k)
+	}
+	fmt.Printf("         billing.* flags = %v\n", billing)
+}
+
+func multiMaps() {
+	// Tags → article IDs; which articles carry both tags?
+	tags := collection.NewMultiMap[string, int](collection.MultiMapSet)
+	tags.PutAll("go", 1, 2, 3)
+	tags.PutAll("db", 2, 3, 4)
+	both := slices.Sorted(tags.GetSet("go").Intersect(tags.GetSet("db")).All())
+	fmt.Printf("Multi:   articles tagged go and db = %v\n", both)
+
+	// ID ↔ username: reverse lookups, and a rename that would collide is refused.
+	users := collection.NewBiMap[int, string]()
+	_ = users.Put(1, "alice")
+	_ = users.Put(2, "bob")
+	id, _ := users.Inverse().Get("bob")
+	err := users.Put(3, "alice")
+	fmt.Printf("BiMap:   bob -> %d; %v\n", id, err)
+}
+
+func graph() {
+	// Service start-up order: independent services in a layer start together.
+	deps := collection.NewGraph[string]()
+	deps.AddEdge("config", "db")
+	deps.AddEdge("config", "cache")
+	deps.AddEdge("db", "api")
+	deps.AddEdge("cache", "api")
+	layers, _ := deps.Layers()
+	fmt.Printf("Graph:   start-up layers = %v\n", layers)
+
+	deps.AddEdge("api", "config") // a bad edit introduces a cycle
+	if _, err := deps.TopoSort(); errors.Is(err, collection.ErrCycle) {
+		fmt.Printf("         %v\n", err)
+	}
+}
+
+func workQueue() {
+	// Three workers drain a bounded BlockingQueue; a SyncSet records which
+	// worker handled at least one job.
+	ctx := context.Background()
+	jobs := collection.NewBlockingQueue[int](4)
+	workers := collection.NewSyncSet[int]()
+	var (
+		wg    sync.WaitGroup
+		mu    sync.Mutex
+		total int
+	)
+	for w := 0; w < 3; w++ {
+		wg.Add(1)
+		go func(w int) {
+			defer wg.Done()
+			for job := range jobs.Consume(ctx) {
+				workers.Add(w)
+				mu.Lock()
+				total += job
+				mu.Unlock()
+			}
+		}(w)
+	}
+	for i := 1; i <= 100; i++ {
+		_ = jobs.Offer(ctx, i) // blocks while 4 jobs are pending
+	}
+	jobs.Close()
+	wg.Wait()
+	fmt.Printf("Work:    3 workers summed jobs 1..100 = %d (active workers: %d)\n", total, workers.Len())
+}
+
+func sketches() {
+	// Two "instances" each count their own traffic; the states are shipped
+	// as bytes and merged into a global view.
+	a, b := collection.NewHyperLogLog(14), collection.NewHyperLogLog(14)
+	for i := 0; i < 6000; i++ {
+		a.AddString(fmt.Sprintf("user-%d", i))
+	}
+	for i := 4000; i < 10000; i++ {
+		b.AddString(fmt.Sprintf("user-%d", i))
+	}
+	data, _ := b.MarshalBinary()
+	var remote collection.HyperLogLog
+	_ = remote.UnmarshalBinary(data)
+	_ = a.Merge(&remote)
+	fmt.Printf("Sketch:  ~%d distinct users across both instances (exact: 10000)\n", a.Count())
+
+	seen := collection.NewBloomFilter(1000, 0.01)
+	fmt.Printf("         first req-1 seen before? %v; again? %v\n",
+		seen.TestAndAddString("req-1"), seen.TestAndAddString("req-1"))
+
+	hot := collection.NewCountMinSketch(0.01, 0.01)
+	hot.AddString("/api/login", 500)
+	hot.AddString("/api/logout", 20)
+	fmt.Printf("         /api/login hits >= %d\n", hot.EstimateString("/api/login"))
+}
+
+func encoding() {
+	// Containers as config/payload fields; the heap is constructed first so
+	// decoding knows its ordering.
+	type rollout struct {
+		Regions collection.Set[string]    `json:"regions"`
+		Steps   *collection.Queue[string] `json:"steps"`
+		Pending *collection.Heap[int]     `json:"pending"`
+	}
+	r := rollout{Pending: collection.NewMinHeap[int]()}
+	in := `{"regions":["us","eu","us"],"steps":["canary","half","full"],"pending":[3,1,2]}`
+	if err := json.Unmarshal([]byte(in), &r); err != nil {
+		panic(err)
+	}
+	next, _ := r.Steps.Dequeue()
+	out, _ := json.Marshal(r)
+	fmt.Printf("JSON:    first step %q, re-encoded %s\n", next, out)
+}
@@ -0,0 +1,386 @@
+package collection
+
+import (
+	"errors"
+	"fmt"
+	"slices"
+	"strings"
+)
+
+// ErrCycle 在有环的 [Graph] 上做拓扑排序时返回;具体的环见 [CycleError]。
+var ErrCycle = errors.New("collection: graph has a cycle")
+
+// CycleError 报告拓扑排序遇到的一个环。Path 从环上的某个节点出发、沿边走回该节点,首尾相同,
+// 例如 [a b c a] 表示 a→b→c→a;自环为 [a a]。errors.Is(err, [ErrCycle]) 为 true。
+type CycleError[K comparable] struct {
+	Path []K
+}
+
+func (e *CycleError[K]) Error() string {
+	parts := make([]string, len(e.Path))
+	for i, k := range e.Path {
+		parts[i] = fmt.Sprint(k)
+	}
+	return "collection: graph has a cycle: " + strings.Join(parts, " -> ")
+}
+
+// Unwrap 返回 [ErrCycle]。
+func (e *CycleError[K]) Unwrap() error { return ErrCycle }
+
+// Graph 是一个有向图,节点为 comparable 的 K,用于依赖排序(初始化步骤、迁移顺序、作业
+// DAG)。边 from→to 表示 from 必须排在 to 之前 —— "a 依赖 b"即 AddEdge(b, a)。
+//
+// 节点和边按加入顺序保存,存在多个合法顺序时,[Graph.TopoSort]、[Graph.Layers] 等按节点
+// 加入顺序决定先后,因此结果只取决于构造过程,不受 map 遍历顺序影响。重复的边只保留一条。
+//
+// 零值是可用的空图。它不是 goroutine 安全的。
+type Graph[K comparable] struct {
+	index map[K]int
+	nodes []K
+	out   [][]int // out[i]:i 的后继下标,按加边顺序
+	in    [][]int // in[i]:i 的前驱下标,按加边顺序
+	edges int
+}
+
+// NewGraph 创建一个空图。
+func NewGraph[K comparable]() *Graph[K] {
+	return &Graph[K]{index: make(map[K]int)}
+}
+
+// AddNode 加入节点 k。k 已存在时是 no-op 并返回 false。没有边的节点也参与排序。
+func (g *Graph[K]) AddNode(k K) bool {
+	if _, ok := g.index[k]; ok {
+		return false
+	}
+	g.node(k)
+	return true
+}
+
+// AddEdge 加入边 from→to,不存在的端点会被自动加入。边已存在时是 no-op 并返回 false。
+// from == to 的自环是合法的,它使图成为有环图。
+func (g *Graph[K]) AddEdge(from, to K) bool {
+	u, v := g.node(from), g.node(to)
+	if slices.Contains(g.out[u], v) {
+		return false
+	}
+	g.out[u] = append(g.out[u], v)
+	g.in[v] = append(g.in[v], u)
+	g.edges++
+	return true
+}
+
+// RemoveEdge 删除边 from→to,节点保留。边不存在时返回 false。
+func (g *Graph[K]) RemoveEdge(from, to K) bool {
+	u, ok1 := g.index[from]
+	v, ok2 := g.index[to]
+	if !ok1 || !ok2 {
+		return false
+	}
+	i := slices.Index(g.out[u], v)
+	if i < 0 {
+		return false
+	}
+	g.out[u] = slices.Delete(g.out[u], i, i+1)
+	j := slices.Index(g.in[v], u)
+	g.in[v] = slices.Delete(g.in[v], j, j+1)
+	g.edges--
+	return true
+}
+
+// HasNode 报告节点 k 是否存在。
+func (g *Graph[K]) HasNode(k K) bool {
+	_, ok := g.index[k]
+	return ok
+}
+
+// HasEdge 报告边 from→to 是否存在。
+func (g *Graph[K]) HasEdge(from, to K) bool {
+	u, ok1 := g.index[from]
+	v, ok2 := g.index[to]
+	return ok1 && ok2 && slices.Contains(g.out[u], v)
+}
+
+// Successors 返回 k 的直接后继(k→x 的 x),按加边顺序。k 不存在时返回 nil。
+func (g *Graph[K]) Successors(k K) []K {
+	if u, ok := g.index[k]; ok {
+		return g.keys(g.out[u])
+	}
+	return nil
+}
+
+// Predecessors 返回 k 的直接前驱(x→k 的 x),按加边顺序。k 不存在时返回 nil。
+func (g *Graph[K]) Predecessors(k K) []K {
+	if v, ok := g.index[k]; ok {
+		return g.keys(g.in[v])
+	}
+	return nil
+}
+
+// Len 返回节点数量。
+func (g *Graph[K]) Len() int { return len(g.nodes) }
+
+// EdgeCount 返回边的数量。
+func (g *Graph[K]) EdgeCount() int { return g.edges }
+
+// IsEmpty 报告图是否没有节点。
+func (g *Graph[K]) IsEmpty() bool { return len(g.nodes) == 0 }
+
+// Clear 删除所有节点和边。
+func (g *Graph[K]) Clear() { *g = Graph[K]{} }
+
+// Nodes 按加入顺序返回所有节点的副本。
+func (g *Graph[K]) Nodes() []K { return slices.Clone(g.nodes) }
+
+// Clone 返回图的独立副本。
+func (g *Graph[K]) Clone() *Graph[K] {
+	out := &Graph[K]{
+		index: make(map[K]int, len(g.index)),
+		nodes: slices.Clone(g.nodes),
+		out:   make([][]int, len(g.out)),
+		in:    make([][]int, len(g.in)),
+		edges: g.edges,
+	}
+	for k, i := range g.index {
+		out.index[k] = i
+	}
+	for i := range g.nodes {
+		out.out[i] = slices.Clone(g.out[i])
+		out.in[i] = slices.Clone(g.in[i])
+	}
+	return out
+}
+
+// TopoSort 返回一个拓扑序:每条边 from→to 中 from 都排在 to 之前。图有环时返回 nil 与
+// *[CycleError],其中包含一个环的完整路径。O(V + E)。
+func (g *Graph[K]) TopoSort() ([]K, error) {
+	layers, err := g.layers()
+	if err != nil {
+		return nil, err
+	}
+	out := make([]K, 0, len(g.nodes))
+	for _, layer := range layers {
+		out = append(out, g.keys(layer)...)
+	}
+	return out, nil
+}
+
+// Layers 返回分层的拓扑序:第 0 层是没有前驱的节点,第 i 层的节点的所有前驱都在前 i 层中。
+// 同一层的节点互不依赖,可以并行执行;层数即关键路径上的节点数。图有环时返回 nil 与
+// *[CycleError]。O(V + E)。
+func (g *Graph[K]) Layers() ([][]K, error) {
+	layers, err := g.layers()
+	if err != nil {
+		return nil, err
+	}
+	out := make([][]K, len(layers))
+	for i, layer := range layers {
+		out[i] = g.keys(layer)
+	}
+	return out, nil
+}
+
+// IsAcyclic 报告图是否无环(DAG)。
+func (g *Graph[K]) IsAcyclic() bool {
+	_, err := g.layers()
+	return err == nil
+}
+
+// Reachable 报告是否存在从 from 到 to 的路径。每个存在的节点都可以到达自身。
+func (g *Graph[K]) Reachable(from, to K) bool {
+	u, ok1 := g.index[from]
+	v, ok2 := g.index[to]
+	if !ok1 || !ok2 {
+		return false
+	}
+	if u == v {
+		return true
+	}
+	found := false
+	g.bfs(u, g.out, func(i int) bool {
+		found = i == v
+		return !found
+	})
+	return found
+}
+
+// Descendants 返回从 k 出发可到达的所有节点(不含 k 本身,除非 k 在环上),按 BFS 顺序 ——
+// 即改动 k 后受影响的全部节点。k 不存在时返回 nil。
+func (g *Graph[K]) Descendants(k K) []K {
+	return g.collect(k, g.out)
+}
+
+// Ancestors 返回可到达 k 的所有节点(不含 k 本身,除非 k 在环上),按 BFS 顺序 —— 即 k 的
+// 全部直接与间接依赖。k 不存在时返回 nil。
+func (g *Graph[K]) Ancestors(k K) []K {
+	return g.collect(k, g.in)
+}
+
+// StronglyConnectedComponents 返回图的强连通分量:同一分量内的节点两两可达。分量按拓扑序
+// 排列(若分量 A 有边到分量 B,A 在 B 之前),分量内按节点加入顺序。DAG 的每个分量都只有
+// 一个节点;多于一个节点的分量(或带自环的单节点)就是图中的环。Tarjan 算法,O(V + E)。
+func (g *Graph[K]) StronglyConnectedComponents() [][]K {
+	n := len(g.nodes)
+	index := make([]int, n) // 访问序号 + 1;0 表示未访问
+	low := make([]int, n)
+	onStack := make([]bool, n)
+	var stack []int
+	var comps [][]K
+	counter := 0
+
+	var visit func(u int)
+	visit = func(u int) {
+		counter++
+		index[u], low[u] = counter, counter
+		stack = append(stack, u)
+		onStack[u] = true
+		for _, v := range g.out[u] {
+			if index[v] == 0 {
+				visit(v)
+				low[u] = min(low[u], low[v])
+			} else if onStack[v] {
+				low[u] = min(low[u], index[v])
+			}
+		}
+		if low[u] != index[u] {
+			return
+		}
+		var comp []int
+		for {
+			v := stack[len(stack)-1]
+			stack = stack[:len(stack)-1]
+			onStack[v] = false
+			comp = append(comp, v)
+			if v == u {
+				break
+			}
+		}
+		slices.Sort(comp)
+		comps = append(comps, g.keys(comp))
+	}
+	for u := range n {
+		if index[u] == 0 {
+			visit(u)
+		}
+	}
+	// Tarjan 按逆拓扑序产出分量。
+	slices.Reverse(comps)
+	return comps
+}
+
+// node 返回 k 的下标,k 不存在时先加入。
+func (g *Graph[K]) node(k K) int {
+	if i, ok := g.index[k]; ok {
+		return i
+	}
+	if g.index == nil {
+		g.index = make(map[K]int)
+	}
+	i := len(g.nodes)
+	g.index[k] = i
+	g.nodes = append(g.nodes, k)
+	g.out = append(g.out, nil)
+	g.in = append(g.in, nil)
+	return i
+}
+
+func (g *Graph[K]) keys(idx []int) []K {
+	out := make([]K, len(idx))
+	for i, j := range idx {
+		out[i] = g.nodes[j]
+	}
+	return out
+}
+
+// layers 是按层推进的 Kahn 算法,返回各层的节点下标;有节点始终无法入层时说明有环。
+func (g *Graph[K]) layers() ([][]int, error) {
+	indeg := make([]int, len(g.nodes))
+	var cur []int
+	for v := range g.nodes {
+		if indeg[v] = len(g.in[v]); indeg[v] == 0 {
+			cur = append(cur, v)
+		}
+	}
+	var layers [][]int
+	placed := 0
+	for len(cur) > 0 {
+		layers = append(layers, cur)
+		placed += len(cur)
+		var next []int
+		for _, u := range cur {
+			for _, v := range g.out[u] {
+				if indeg[v]--; indeg[v] == 0 {
+					next = append(next, v)
+				}
+			}
+		}
+		slices.Sort(next)
+		cur = next
+	}
+	if placed < len(g.nodes) {
+		return nil, g.cycle(indeg)
+	}
+	return layers, nil
+}
+
+// cycle 在 Kahn 算法剩下的节点(indeg > 0)中找一个环。剩下的每个节点都至少有一个同样剩下
+// 的前驱,因此沿前驱回溯必然回到走过的节点。
+func (g *Graph[K]) cycle(indeg []int) error {
+	start := slices.IndexFunc(indeg, func(d int) bool { return d > 0 })
+	seen := make(map[int]int) // 节点 → 在 walk 中的位置
+	var walk []int
+	u := start
+	for {
+		if at, ok := seen[u]; ok {
+			walk = walk[at:]
+			break
+		}
+		seen[u] = len(walk)
+		walk = append(walk, u)
+		for _, p := range g.in[u] {
+			if indeg[p] > 0 {
+				u = p
+				break
+			}
+		}
+	}
+	// walk 是沿前驱的方向,反转后即沿边的方向;再从下标最小的节点开始,使结果确定。
+	slices.Reverse(walk)
+	first := slices.Index(walk, slices.Min(walk))
+	walk = slices.Concat(walk[first:], walk[:first], walk[first:first+1])
+	path := g.keys(walk)
+	return &CycleError[K]{Path: path}
+}
+
+// bfs 从 start 沿 adj 做广度优先遍历,对每个可到达的节点(start 本身只在环上时)调用 visit;
+// visit 返回 false 时停止。
+func (g *Graph[K]) bfs(start int, adj [][]int, visit func(int) bool) {
+	seen := make([]bool, len(g.nodes))
+	queue := []int{start}
+	for len(queue) > 0 {
+		u := queue[0]
+		queue = queue[1:]
+		for _, v := range adj[u] {
+			if seen[v] {
+				continue
+			}
+			seen[v] = true
+			if !visit(v) {
+				return
+			}
+			queue = append(queue, v)
+		}
+	}
+}
+
+func (g *Graph[K]) collect(k K, adj [][]int) []K {
+	u, ok := g.index[k]
+	if !ok {
+		return nil
+	}
+	out := []K{}
+	g.bfs(u, adj, func(i int) bool {
+		out = append(out, g.nodes[i])
+		return true
+	})
+	return out
+}
@@ -0,0 +1,139 @@
+package collection
+
+import (
+	"fmt"
+	"math"
+	"math/bits"
+)
+
+// HyperLogLog 是一个基数(不同元素个数)估算结构。精度 p 时使用 2^p 个 1 字节寄存器,标准误差
+// 约 1.04/√(2^p):默认 p=14 占 16 KB,误差约 0.81%,可估算到数十亿的基数。
+//
+// 用于"今天有多少不同用户"一类的统计。精度相同的实例可用 [HyperLogLog.Merge] 合并(结果等价于
+// 对两边元素的并集计数),并可经 MarshalBinary/UnmarshalBinary 在实例之间传递。它不是 goroutine
+// 安全的。
+//
+// 零值不可用:Count 把它当作空实例,Add 会 panic。请使用 [NewHyperLogLog] 构造,或以
+// [HyperLogLog.UnmarshalBinary] 解码得到。
+type HyperLogLog struct {
+	regs []uint8
+	p    uint8
+}
+
+const (
+	hllMagic            = "HLL1"
+	hllMinPrecision     = 4
+	hllMaxPrecision     = 18
+	hllDefaultPrecision = 14
+)
+
+// NewHyperLogLog 以精度 precision(寄存器数为 2^precision)创建一个空 HyperLogLog。precision
+// 不在 [4, 18] 内时回退到 14。
+func NewHyperLogLog(precision uint8) *HyperLogLog {
+	if precision < hllMinPrecision || precision > hllMaxPrecision {
+		precision = hllDefaultPrecision
+	}
+	return &HyperLogLog{regs: make([]uint8, 1<<precision), p: precision}
+}
+
+// Add 加入 data。
+func (h *HyperLogLog) Add(data []byte) { h.add(sketchHash(data)) }
+
+// AddString 加入 s,不为其分配 []byte。
+func (h *HyperLogLog) AddString(s string) { h.add(sketchHashString(s)) }
+
+// Count 返回不同元素个数的估算值。小基数时使用 linear counting 修正,因此空实例返回 0,
+// 少量元素的估算接近精确。
+func (h *HyperLogLog) Count() uint64 {
+	if len(h.regs) == 0 {
+		return 0
+	}
+	m := float64(len(h.regs))
+	sum, zeros := 0.0, 0
+	for _, r := range h.regs {
+		sum += math.Ldexp(1, -int(r))
+		if r == 0 {
+			zeros++
+		}
+	}
+	est := hllAlpha(len(h.regs)) * m * m / sum
+	if est <= 2.5*m && zeros > 0 {
+		est = m * math.Log(m/float64(zeros))
+	}
+	return uint64(math.Round(est))
+}
+
+// Precision 返回精度 p。
+func (h *HyperLogLog) Precision() uint8 { return h.p }
+
+// Merge 将 other 合并到 h,结果估算两边元素并集的基数。两者的精度必须相同,否则返回
+// [ErrSketchMismatch] 且 h 不变。
+func (h *HyperLogLog) Merge(other *HyperLogLog) error {
+	if h.p != other.p {
+		return fmt.Errorf("%w: hyperloglog precision %d vs %d", ErrSketchMismatch, h.p, other.p)
+	}
+	for i, r := range other.regs {
+		h.regs[i] = max(h.regs[i], r)
+	}
+	return nil
+}
+
+// Clear 将所有寄存器归零,精度保持不变。
+func (h *HyperLogLog) Clear() { clear(h.regs) }
+
+// Clone 返回 HyperLogLog 的独立副本。
+func (h *HyperLogLog) Clone() *HyperLogLog {
+	return &HyperLogLog{regs: append([]uint8(nil), h.regs...), p: h.p}
+}
+
+// MarshalBinary 将 HyperLogLog 编码为字节序列(精度与寄存器),实现 [encoding.BinaryMarshaler]。
+func (h *HyperLogLog) MarshalBinary() ([]byte, error) {
+	out := make([]byte, 0, len(hllMagic)+1+len(h.regs))
+	out = append(out, hllMagic...)
+	out = append(out, h.p)
+	return append(out, h.regs...), nil
+}
+
+// UnmarshalBinary 从 [HyperLogLog.MarshalBinary] 的输出还原 HyperLogLog,实现
+// [encoding.BinaryUnmarshaler]。数据损坏时返回错误且 h 不变。
+func (h *HyperLogLog) UnmarshalBinary(data []byte) error {
+	r := newSketchReader("HyperLogLog", hllMagic, data)
+	p := r.bytes(1)
+	if r.err == nil && (p[0] < hllMinPrecision || p[0] > hllMaxPrecision) {
+		return fmt.Errorf("collection: decode HyperLogLog: invalid precision %d", p[0])
+	}
+	var regs []byte
+	if r.err == nil {
+		regs = r.bytes(1 << p[0])
+	}
+	if err := r.done(); err != nil {
+		return err
+	}
+	*h = HyperLogLog{regs: append([]uint8(nil), regs...), p: p[0]}
+	return nil
+}
+
+// add 用哈希的高 p 位选择寄存器,其余位中首个 1 的位置(从 1 起)更新该寄存器的最大值。
+func (h *HyperLogLog) add(x uint64) {
+	if h.p == 0 {
+		panic("collection: Add on a zero-value HyperLogLog; construct it with NewHyperLogLog")
+	}
+	idx := x >> (64 - h.p)
+	w := x<<h.p | 1<<(h.p-1) // 哨兵位限制 rank 的上限
+	rank := uint8(bits.LeadingZeros64(w)) + 1
+	h.regs[idx] = max(h.regs[idx], rank)
+}
+
+// hllAlpha 是 HyperLogLog 估算式的偏差修正常数。
+func hllAlpha(m int) float64 {
+	switch m {
+	case 16:
+		return 0.673
+	case 32:
+		return 0.697
+	case 64:
+		return 0.709
+	default:
+		return 0.7213 / (1 + 1.079/float64(m))
+	}
+}
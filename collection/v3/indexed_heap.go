@@ -0,0 +1,237 @@
+package collection
+
+import "cmp"
+
+// IndexedHeap 是一个按 key 索引的二叉堆 priority queue:每个元素都有唯一的 key,因此
+// 排队中的元素可以被改变优先级([IndexedHeap.Update])或取消([IndexedHeap.Remove])——
+// 这正是调度器与 Dijkstra 式 decrease-key 需要而 [Heap] 做不到的。
+//
+// 顺序与 [Heap] 相同,由 less 定义:less(a, b) == true 表示 a 的优先级高于 b。有序类型
+// 有专用构造器 [NewIndexedMinHeap] 和 [NewIndexedMaxHeap]。
+//
+// Push/Pop/Update/Remove 是 O(log n);Peek/Get/Contains/Len 是 O(1)。
+type IndexedHeap[K comparable, T any] struct {
+	data []indexedItem[K, T]
+	pos  map[K]int // key → data 中的索引
+	less func(a, b T) bool
+}
+
+type indexedItem[K comparable, T any] struct {
+	key K
+	val T
+}
+
+// NewIndexedHeap 创建一个按 less 排序、使用默认 capacity 的空 indexed heap。
+// less(a, b) == true 表示 a 的优先级高于 b。
+func NewIndexedHeap[K comparable, T any](less func(a, b T) bool) *IndexedHeap[K, T] {
+	return NewIndexedHeapWithCap[K, T](defaultCap, less)
+}
+
+// NewIndexedHeapWithCap 创建一个按 less 排序、为 cap 个元素预分配大小的空 indexed heap。
+// 非正的 cap 会回退到默认 capacity。
+func NewIndexedHeapWithCap[K comparable, T any](cap int, less func(a, b T) bool) *IndexedHeap[K, T] {
+	if cap <= 0 {
+		cap = defaultCap
+	}
+	return &IndexedHeap[K, T]{
+		data: make([]indexedItem[K, T], 0, cap),
+		pos:  make(map[K]int, cap),
+		less: less,
+	}
+}
+
+// NewIndexedMinHeap 创建一个基于有序类型的空 indexed min-heap:最小值具有最高优先级。
+func NewIndexedMinHeap[K comparable, T cmp.Ordered]() *IndexedHeap[K, T] {
+	return NewIndexedHeap[K, T](func(a, b T) bool { return cmp.Compare(a, b) < 0 })
+}
+
+// NewIndexedMaxHeap 创建一个基于有序类型的空 indexed max-heap:最大值具有最高优先级。
+func NewIndexedMaxHeap[K comparable, T cmp.Ordered]() *IndexedHeap[K, T] {
+	return NewIndexedHeap[K, T](func(a, b T) bool { return cmp.Compare(a, b) > 0 })
+}
+
+// Push 以 key 加入 v 并返回 true。key 已存在时等同于 [IndexedHeap.Update],返回 false。
+// O(log n)。
+func (h *IndexedHeap[K, T]) Push(key K, v T) bool {
+	if i, ok := h.pos[key]; ok {
+		h.set(i, v)
+		return false
+	}
+	h.data = append(h.data, indexedItem[K, T]{key: key, val: v})
+	h.pos[key] = len(h.data) - 1
+	h.siftUp(len(h.data) - 1)
+	return true
+}
+
+// Update 将 key 的值改为 v 并恢复 heap 性质,优先级可升可降。key 不存在时返回 false。
+// O(log n)。
+func (h *IndexedHeap[K, T]) Update(key K, v T) bool {
+	i, ok := h.pos[key]
+	if !ok {
+		return false
+	}
+	h.set(i, v)
+	return true
+}
+
+// Remove 移除 key 并返回其值。key 不存在时返回 (zero, false)。O(log n)。
+func (h *IndexedHeap[K, T]) Remove(key K) (T, bool) {
+	i, ok := h.pos[key]
+	if !ok {
+		var zero T
+		return zero, false
+	}
+	return h.removeAt(i).val, true
+}
+
+// Pop 移除并返回最高优先级的元素及其 key。当 heap 为空时返回 ok == false。O(log n)。
+func (h *IndexedHeap[K, T]) Pop() (K, T, bool) {
+	if len(h.data) == 0 {
+		var (
+			zk K
+			zv T
+		)
+		return zk, zv, false
+	}
+	it := h.removeAt(0)
+	return it.key, it.val, true
+}
+
+// Peek 返回最高优先级的元素及其 key 但不移除它。当 heap 为空时返回 ok == false。O(1)。
+func (h *IndexedHeap[K, T]) Peek() (K, T, bool) {
+	if len(h.data) == 0 {
+		var (
+			zk K
+			zv T
+		)
+		return zk, zv, false
+	}
+	return h.data[0].key, h.data[0].val, true
+}
+
+// Get 返回 key 的当前值。key 不存在时返回 (zero, false)。O(1)。
+func (h *IndexedHeap[K, T]) Get(key K) (T, bool) {
+	i, ok := h.pos[key]
+	if !ok {
+		var zero T
+		return zero, false
+	}
+	return h.data[i].val, true
+}
+
+// Contains 报告 key 是否在 heap 中。O(1)。
+func (h *IndexedHeap[K, T]) Contains(key K) bool {
+	_, ok := h.pos[key]
+	return ok
+}
+
+// Len 返回元素数量。
+func (h *IndexedHeap[K, T]) Len() int { return len(h.data) }
+
+// IsEmpty 报告 heap 是否没有元素。
+func (h *IndexedHeap[K, T]) IsEmpty() bool { return len(h.data) == 0 }
+
+// Clear 移除所有元素并清零 backing array,以便 GC 回收持有的引用。capacity 保留以便复用。
+func (h *IndexedHeap[K, T]) Clear() {
+	clear(h.data)
+	h.data = h.data[:0]
+	clear(h.pos)
+}
+
+// Keys 返回所有 key 的副本,顺序是 heap 的内部布局。
+func (h *IndexedHeap[K, T]) Keys() []K {
+	out := make([]K, len(h.data))
+	for i, it := range h.data {
+		out[i] = it.key
+	}
+	return out
+}
+
+// Values 返回所有值的副本。顺序是 heap 的内部布局,而非优先级顺序 —— 若要按优先级顺序排空,
+// 请调用 Pop 直到为空。返回的 slice 是独立的。
+func (h *IndexedHeap[K, T]) Values() []T {
+	out := make([]T, len(h.data))
+	for i, it := range h.data {
+		out[i] = it.val
+	}
+	return out
+}
+
+// Clone 返回 heap 的独立副本,共享相同的排序方式。
+func (h *IndexedHeap[K, T]) Clone() *IndexedHeap[K, T] {
+	data := make([]indexedItem[K, T], len(h.data), max(len(h.data), defaultCap))
+	copy(data, h.data)
+	pos := make(map[K]int, len(h.pos))
+	for k, i := range h.pos {
+		pos[k] = i
+	}
+	return &IndexedHeap[K, T]{data: data, pos: pos, less: h.less}
+}
+
+// set 将索引 i 处的值改为 v,并按需上移或下移。
+func (h *IndexedHeap[K, T]) set(i int, v T) {
+	h.data[i].val = v
+	if !h.siftUp(i) {
+		h.siftDown(i)
+	}
+}
+
+// removeAt 移除索引 i 处的元素:用末尾元素填补空位后恢复 heap 性质。
+func (h *IndexedHeap[K, T]) removeAt(i int) indexedItem[K, T] {
+	it := h.data[i]
+	n := len(h.data) - 1
+	if i != n {
+		h.swap(i, n)
+	}
+	h.data[n] = indexedItem[K, T]{} // 让 GC 回收被移除元素的引用
+	h.data = h.data[:n]
+	delete(h.pos, it.key)
+	if i < n {
+		if !h.siftUp(i) {
+			h.siftDown(i)
+		}
+	}
+	return it
+}
+
+// swap 交换两个元素并同步它们的索引。
+func (h *IndexedHeap[K, T]) swap(i, j int) {
+	h.data[i], h.data[j] = h.data[j], h.data[i]
+	h.pos[h.data[i].key] = i
+	h.pos[h.data[j].key] = j
+}
+
+// siftUp 将索引 i 处的元素向上移动,直到恢复 heap 性质,并报告它是否移动过。O(log n)。
+func (h *IndexedHeap[K, T]) siftUp(i int) bool {
+	start := i
+	for i > 0 {
+		parent := (i - 1) / 2
+		if !h.less(h.data[i].val, h.data[parent].val) {
+			break
+		}
+		h.swap(i, parent)
+		i = parent
+	}
+	return i != start
+}
+
+// siftDown 将索引 i 处的元素向下移动,直到恢复 heap 性质。O(log n)。
+func (h *IndexedHeap[K, T]) siftDown(i int) {
+	n := len(h.data)
+	for {
+		left := 2*i + 1
+		right := 2*i + 2
+		best := i
+		if left < n && h.less(h.data[left].val, h.data[best].val) {
+			best = left
+		}
+		if right < n && h.less(h.data[right].val, h.data[best].val) {
+			best = right
+		}
+		if best == i {
+			break
+		}
+		h.swap(i, best)
+		i = best
+	}
+}
@@ -38,6 +38,18 @@ func (h *Heap[T]) All() iter.Seq[T] {
 	}
 }
 
+// All 返回一个遍历 indexed heap 元素的 [iter.Seq2],产出 (key, 值)。顺序是内部 heap 布局,
+// 而非优先级顺序。遍历期间不要修改 heap。提前 break 出 range 是安全的。
+func (h *IndexedHeap[K, T]) All() iter.Seq2[K, T] {
+	return func(yield func(K, T) bool) {
+		for _, it := range h.data {
+			if !yield(it.key, it.val) {
+				return
+			}
+		}
+	}
+}
+
 // All 返回一个遍历 set 元素的 [iter.Seq]。顺序不确定(map 迭代顺序)。提前 break 出 range 是安全的。
 func (s Set[T]) All() iter.Seq[T] {
 	return func(yield func(T) bool) {
@@ -48,3 +60,196 @@ func (s Set[T]) All() iter.Seq[T] {
 		}
 	}
 }
+
+// All 返回一个遍历 deque 元素的 [iter.Seq],顺序从前端到后端。提前 break 出 range 是安全的。
+func (d *Deque[T]) All() iter.Seq[T] {
+	return func(yield func(T) bool) {
+		for i := 0; i < d.count; i++ {
+			if !yield(d.buf[d.index(i)]) {
+				return
+			}
+		}
+	}
+}
+
+// Backward 返回一个遍历 deque 元素的 [iter.Seq],顺序从后端到前端。提前 break 出 range 是安全的。
+func (d *Deque[T]) Backward() iter.Seq[T] {
+	return func(yield func(T) bool) {
+		for i := d.count - 1; i >= 0; i-- {
+			if !yield(d.buf[d.index(i)]) {
+				return
+			}
+		}
+	}
+}
+
+// All 返回一个遍历 ring buffer 元素的 [iter.Seq],顺序从最旧到最新。提前 break 出 range 是安全的。
+func (r *RingBuffer[T]) All() iter.Seq[T] {
+	return func(yield func(T) bool) {
+		n := len(r.buf)
+		for i := 0; i < r.count; i++ {
+			if !yield(r.buf[(r.head+i)%n]) {
+				return
+			}
+		}
+	}
+}
+
+// All 返回一个按 key 升序遍历 sorted map 的 [iter.Seq2]。遍历期间可以安全地 Delete 任意 key,
+// 规则见 [SortedMap]。提前 break 出 range 是安全的。
+//
+//	for k, v := range m.All() { ... }
+func (m *SortedMap[K, V]) All() iter.Seq2[K, V] {
+	return func(yield func(K, V) bool) {
+		m.sl.ascend(m.sl.first(), nil, func(n *skipNode[K, V]) bool { return yield(n.key, n.val) })
+	}
+}
+
+// Backward 返回一个按 key 降序遍历 sorted map 的 [iter.Seq2]。提前 break 出 range 是安全的。
+func (m *SortedMap[K, V]) Backward() iter.Seq2[K, V] {
+	return func(yield func(K, V) bool) {
+		m.sl.descend(m.sl.tail, func(n *skipNode[K, V]) bool { return yield(n.key, n.val) })
+	}
+}
+
+// Range 返回一个按升序遍历半开区间 [lo, hi) 内 key 的 [iter.Seq2]。lo 不小于 hi 时不产生
+// 任何元素。提前 break 出 range 是安全的。
+func (m *SortedMap[K, V]) Range(lo, hi K) iter.Seq2[K, V] {
+	return func(yield func(K, V) bool) {
+		stop := func(k K) bool { return !m.sl.less(k, hi) }
+		m.sl.ascend(m.sl.ceiling(lo), stop, func(n *skipNode[K, V]) bool { return yield(n.key, n.val) })
+	}
+}
+
+// All 返回一个按升序遍历 sorted set 的 [iter.Seq]。遍历期间可以安全地 Remove 任意元素。
+// 提前 break 出 range 是安全的。
+func (s *SortedSet[T]) All() iter.Seq[T] {
+	return func(yield func(T) bool) {
+		s.sl.ascend(s.sl.first(), nil, func(n *skipNode[T, struct{}]) bool { return yield(n.key) })
+	}
+}
+
+// Backward 返回一个按降序遍历 sorted set 的 [iter.Seq]。提前 break 出 range 是安全的。
+func (s *SortedSet[T]) Backward() iter.Seq[T] {
+	return func(yield func(T) bool) {
+		s.sl.descend(s.sl.tail, func(n *skipNode[T, struct{}]) bool { return yield(n.key) })
+	}
+}
+
+// Range 返回一个按升序遍历半开区间 [lo, hi) 内元素的 [iter.Seq]。lo 不小于 hi 时不产生
+// 任何元素。提前 break 出 range 是安全的。
+func (s *SortedSet[T]) Range(lo, hi T) iter.Seq[T] {
+	return func(yield func(T) bool) {
+		stop := func(v T) bool { return !s.sl.less(v, hi) }
+		s.sl.ascend(s.sl.ceiling(lo), stop, func(n *skipNode[T, struct{}]) bool { return yield(n.key) })
+	}
+}
+
+// All 返回一个按当前顺序(前端到后端)遍历 ordered map 的 [iter.Seq2]。遍历期间可以 Delete
+// 当前 key,规则见 [OrderedMap]。提前 break 出 range 是安全的。
+func (o *OrderedMap[K, V]) All() iter.Seq2[K, V] {
+	return func(yield func(K, V) bool) {
+		for n := o.head; n != nil; {
+			next := n.next
+			if !yield(n.key, n.val) {
+				return
+			}
+			n = next
+		}
+	}
+}
+
+// Backward 返回一个从后端到前端遍历 ordered map 的 [iter.Seq2]。提前 break 出 range 是安全的。
+func (o *OrderedMap[K, V]) Backward() iter.Seq2[K, V] {
+	return func(yield func(K, V) bool) {
+		for n := o.tail; n != nil; {
+			prev := n.prev
+			if !yield(n.key, n.val) {
+				return
+			}
+			n = prev
+		}
+	}
+}
+
+// All 返回一个按字典序遍历 radix tree 的 [iter.Seq2]。遍历期间不得修改树。提前 break 出
+// range 是安全的。
+func (t *RadixTree[V]) All() iter.Seq2[string, V] {
+	return t.WalkPrefix("")
+}
+
+// WalkPrefix 返回一个按字典序遍历所有以 prefix 开头的 key 的 [iter.Seq2];prefix 为空时
+// 等同 [RadixTree.All]。遍历期间不得修改树。提前 break 出 range 是安全的。
+//
+//	for k, v := range flags.WalkPrefix("billing.") { ... }
+func (t *RadixTree[V]) WalkPrefix(prefix string) iter.Seq2[string, V] {
+	return func(yield func(string, V) bool) {
+		n, path := t.seek(prefix)
+		if n == nil {
+			return
+		}
+		n.walk([]byte(path), yield)
+	}
+}
+
+// All 返回一个按升序遍历 BitSet 元素的 [iter.Seq]。遍历期间可以 Add/Remove 当前元素之后的
+// 元素,结果会反映到后续遍历中。提前 break 出 range 是安全的。
+func (b *BitSet) All() iter.Seq[uint] {
+	return func(yield func(uint) bool) {
+		for i, ok := b.NextSet(0); ok; i, ok = b.NextSet(i + 1) {
+			if !yield(i) {
+				return
+			}
+		}
+	}
+}
+
+// All 返回一个遍历所有 (key, value) 对的 [iter.Seq2];同一 key 的值相邻产出,key 的顺序
+// 不确定。提前 break 出 range 是安全的。
+func (m *MultiMap[K, V]) All() iter.Seq2[K, V] {
+	return func(yield func(K, V) bool) {
+		for k, l := range m.lists {
+			for _, v := range l {
+				if !yield(k, v) {
+					return
+				}
+			}
+		}
+		for k, s := range m.sets {
+			for v := range s.m {
+				if !yield(k, v) {
+					return
+				}
+			}
+		}
+	}
+}
+
+// ValuesOf 返回一个遍历 key 下所有值的 [iter.Seq],顺序同 [MultiMap.Get],但不复制。遍历期间
+// 不得修改该 key 下的值。提前 break 出 range 是安全的。
+func (m *MultiMap[K, V]) ValuesOf(key K) iter.Seq[V] {
+	return func(yield func(V) bool) {
+		for _, v := range m.lists[key] {
+			if !yield(v) {
+				return
+			}
+		}
+		for v := range m.sets[key].m {
+			if !yield(v) {
+				return
+			}
+		}
+	}
+}
+
+// All 返回一个遍历 BiMap 所有 (key, value) 对的 [iter.Seq2],顺序不确定。提前 break 出
+// range 是安全的。
+func (b *BiMap[K, V]) All() iter.Seq2[K, V] {
+	return func(yield func(K, V) bool) {
+		for k, v := range b.fwd {
+			if !yield(k, v) {
+				return
+			}
+		}
+	}
+}
@@ -0,0 +1,233 @@
+package collection
+
+import "slices"
+
+// MultiMapMode 决定 [MultiMap] 每个 key 下的值如何保存。
+type MultiMapMode int
+
+const (
+	// MultiMapSet 将每个 key 下的值保存为 [Set]:同一值只保存一次,值的顺序不确定。
+	// 用于 tag → ID 一类的关系。
+	MultiMapSet MultiMapMode = iota
+	// MultiMapList 将每个 key 下的值保存为 slice:允许重复,保持加入顺序。
+	MultiMapList
+)
+
+// MultiMap 是一个 key → 多个值的映射。空的值集合不会保留:key 的最后一个值被删除时,key
+// 也随之删除,因此 ContainsKey 与 KeyCount 只反映至少有一个值的 key。
+//
+// Len 是 (key, value) 对的总数,KeyCount 是 key 的个数。零值是可用的 [MultiMapSet] 模式
+// 空 multimap。它不是 goroutine 安全的。
+type MultiMap[K, V comparable] struct {
+	mode  MultiMapMode
+	sets  map[K]Set[V] // MultiMapSet
+	lists map[K][]V    // MultiMapList
+	n     int
+}
+
+// NewMultiMap 创建一个按 mode 保存值的空 multimap。
+func NewMultiMap[K, V comparable](mode MultiMapMode) *MultiMap[K, V] {
+	m := &MultiMap[K, V]{mode: mode}
+	if mode == MultiMapList {
+		m.lists = make(map[K][]V)
+	} else {
+		m.mode = MultiMapSet
+		m.sets = make(map[K]Set[V])
+	}
+	return m
+}
+
+// Put 在 key 下加入 v。[MultiMapSet] 模式下 v 已存在时是 no-op 并返回 false;
+// [MultiMapList] 模式下总是追加并返回 true。
+func (m *MultiMap[K, V]) Put(key K, v V) bool {
+	if m.lists == nil && m.sets == nil {
+		*m = *NewMultiMap[K, V](m.mode)
+	}
+	if m.mode == MultiMapList {
+		m.lists[key] = append(m.lists[key], v)
+		m.n++
+		return true
+	}
+	s, ok := m.sets[key]
+	if !ok {
+		s = NewSet[V]()
+		m.sets[key] = s
+	} else if s.Contains(v) {
+		return false
+	}
+	s.Add(v)
+	m.n++
+	return true
+}
+
+// PutAll 在 key 下依次加入 values,返回实际加入的个数。
+func (m *MultiMap[K, V]) PutAll(key K, values ...V) int {
+	added := 0
+	for _, v := range values {
+		if m.Put(key, v) {
+			added++
+		}
+	}
+	return added
+}
+
+// Get 返回 key 下所有值的副本:[MultiMapList] 模式按加入顺序,[MultiMapSet] 模式顺序不确定。
+// key 不存在时返回 nil。
+func (m *MultiMap[K, V]) Get(key K) []V {
+	if m.mode == MultiMapList {
+		return slices.Clone(m.lists[key])
+	}
+	if s, ok := m.sets[key]; ok {
+		return s.Values()
+	}
+	return nil
+}
+
+// GetSet 以新的 [Set] 返回 key 下的所有值,便于与其他 key 的值做集合代数:
+//
+//	both := m.GetSet("go").Intersect(m.GetSet("db"))
+//
+// key 不存在时返回空 Set。
+func (m *MultiMap[K, V]) GetSet(key K) Set[V] {
+	if m.mode == MultiMapList {
+		return NewSet(m.lists[key]...)
+	}
+	if s, ok := m.sets[key]; ok {
+		return s.Clone()
+	}
+	return NewSet[V]()
+}
+
+// Count 返回 key 下值的个数。
+func (m *MultiMap[K, V]) Count(key K) int {
+	if m.mode == MultiMapList {
+		return len(m.lists[key])
+	}
+	return m.sets[key].Len()
+}
+
+// Contains 报告 key 下是否有值 v。
+func (m *MultiMap[K, V]) Contains(key K, v V) bool {
+	if m.mode == MultiMapList {
+		return slices.Contains(m.lists[key], v)
+	}
+	s, ok := m.sets[key]
+	return ok && s.Contains(v)
+}
+
+// ContainsKey 报告 key 下是否至少有一个值。
+func (m *MultiMap[K, V]) ContainsKey(key K) bool {
+	if m.mode == MultiMapList {
+		_, ok := m.lists[key]
+		return ok
+	}
+	_, ok := m.sets[key]
+	return ok
+}
+
+// Remove 从 key 下删除 v;[MultiMapList] 模式只删除第一次出现的 v。v 不存在时返回 false。
+func (m *MultiMap[K, V]) Remove(key K, v V) bool {
+	if m.mode == MultiMapList {
+		l := m.lists[key]
+		i := slices.Index(l, v)
+		if i < 0 {
+			return false
+		}
+		if l = slices.Delete(l, i, i+1); len(l) == 0 {
+			delete(m.lists, key)
+		} else {
+			m.lists[key] = l
+		}
+		m.n--
+		return true
+	}
+	s, ok := m.sets[key]
+	if !ok || !s.Contains(v) {
+		return false
+	}
+	s.Remove(v)
+	if s.IsEmpty() {
+		delete(m.sets, key)
+	}
+	m.n--
+	return true
+}
+
+// Delete 删除 key 及其所有值,并返回这些值(顺序同 [MultiMap.Get])。key 不存在时返回 nil。
+func (m *MultiMap[K, V]) Delete(key K) []V {
+	vals := m.Get(key)
+	if m.mode == MultiMapList {
+		delete(m.lists, key)
+	} else {
+		delete(m.sets, key)
+	}
+	m.n -= len(vals)
+	return vals
+}
+
+// Mode 返回值的保存方式。
+func (m *MultiMap[K, V]) Mode() MultiMapMode { return m.mode }
+
+// Len 返回 (key, value) 对的总数。
+func (m *MultiMap[K, V]) Len() int { return m.n }
+
+// KeyCount 返回 key 的个数。
+func (m *MultiMap[K, V]) KeyCount() int {
+	if m.mode == MultiMapList {
+		return len(m.lists)
+	}
+	return len(m.sets)
+}
+
+// IsEmpty 报告 multimap 是否没有值。
+func (m *MultiMap[K, V]) IsEmpty() bool { return m.n == 0 }
+
+// Clear 删除所有 key 和值。
+func (m *MultiMap[K, V]) Clear() {
+	clear(m.lists)
+	clear(m.sets)
+	m.n = 0
+}
+
+// Keys 返回所有 key 的 slice,顺序不确定。
+func (m *MultiMap[K, V]) Keys() []K {
+	out := make([]K, 0, m.KeyCount())
+	for k := range m.lists {
+		out = append(out, k)
+	}
+	for k := range m.sets {
+		out = append(out, k)
+	}
+	return out
+}
+
+// Values 返回所有值的 slice(跨 key,可能重复),顺序不确定。
+func (m *MultiMap[K, V]) Values() []V {
+	out := make([]V, 0, m.n)
+	for _, v := range m.All() {
+		out = append(out, v)
+	}
+	return out
+}
+
+// Clone 返回 multimap 的独立副本。
+func (m *MultiMap[K, V]) Clone() *MultiMap[K, V] {
+	out := NewMultiMap[K, V](m.mode)
+	for k, l := range m.lists {
+		out.lists[k] = slices.Clone(l)
+	}
+	for k, s := range m.sets {
+		out.sets[k] = s.Clone()
+	}
+	out.n = m.n
+	return out
+}
+
+// Invert 返回 value → key 的新 multimap(模式相同),例如由 tag → ID 得到 ID → tag。
+func (m *MultiMap[K, V]) Invert() *MultiMap[V, K] {
+	out := NewMultiMap[V, K](m.mode)
+	for k, v := range m.All() {
+		out.Put(v, k)
+	}
+	return out
+}
@@ -0,0 +1,378 @@
+package collection
+
+import (
+	"bytes"
+	"encoding"
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"strconv"
+)
+
+// OrderMode 决定 [OrderedMap] 的遍历顺序。
+type OrderMode int
+
+const (
+	// InsertionOrder 按 key 首次插入的顺序排列;更新已有 key 的值不改变其位置。
+	InsertionOrder OrderMode = iota
+	// AccessOrder 按最近访问排列:Get 与 Put 命中已有 key 时将其移到后端,因此前端是最久
+	// 未使用的 key,适合"最近使用"视图与 LRU 淘汰([OrderedMap.PopFront])。
+	AccessOrder
+)
+
+// OrderedMap 是一个保持 key 顺序的哈希表(LinkedHashMap):map 提供 O(1) 查找,双向链表
+// 记录顺序。顺序由 [OrderMode] 决定,也可用 [OrderedMap.MoveToFront]/[OrderedMap.MoveToBack]
+// 显式调整。
+//
+// 它实现了 [json.Marshaler] 与 [json.Unmarshaler],按顺序输出与读入 key,因此序列化结果是
+// 确定的。零值是可用的 insertion-order 空 map,可直接作为结构体字段解码。
+//
+// 遍历期间可以 Delete 当前 key;其他修改(插入、移动、其他 key 的删除,以及 AccessOrder
+// 模式下会移动元素的 Get)的遍历结果未定义 —— 遍历中读取请用 [OrderedMap.Peek]。
+type OrderedMap[K comparable, V any] struct {
+	m          map[K]*omNode[K, V]
+	head, tail *omNode[K, V]
+	mode       OrderMode
+}
+
+type omNode[K comparable, V any] struct {
+	key        K
+	val        V
+	prev, next *omNode[K, V]
+}
+
+// NewOrderedMap 创建一个按 mode 排序的空 ordered map。
+func NewOrderedMap[K comparable, V any](mode OrderMode) *OrderedMap[K, V] {
+	return &OrderedMap[K, V]{m: make(map[K]*omNode[K, V]), mode: mode}
+}
+
+// Put 将 key 关联到 val。新 key 追加到后端并返回 true;已有 key 替换其值并返回 false,
+// 在 AccessOrder 模式下还会将其移到后端。
+func (o *OrderedMap[K, V]) Put(key K, val V) bool {
+	if n, ok := o.m[key]; ok {
+		n.val = val
+		o.touch(n)
+		return false
+	}
+	if o.m == nil {
+		o.m = make(map[K]*omNode[K, V])
+	}
+	n := &omNode[K, V]{key: key, val: val}
+	o.m[key] = n
+	o.pushBack(n)
+	return true
+}
+
+// Get 返回 key 关联的值;在 AccessOrder 模式下命中的 key 会被移到后端。key 不存在时返回
+// (zero, false)。
+func (o *OrderedMap[K, V]) Get(key K) (V, bool) {
+	n, ok := o.m[key]
+	if !ok {
+		var zero V
+		return zero, false
+	}
+	o.touch(n)
+	return n.val, true
+}
+
+// Peek 返回 key 关联的值,在任何模式下都不改变顺序。key 不存在时返回 (zero, false)。
+func (o *OrderedMap[K, V]) Peek(key K) (V, bool) {
+	n, ok := o.m[key]
+	if !ok {
+		var zero V
+		return zero, false
+	}
+	return n.val, true
+}
+
+// Contains 报告 key 是否存在,不改变顺序。
+func (o *OrderedMap[K, V]) Contains(key K) bool {
+	_, ok := o.m[key]
+	return ok
+}
+
+// Delete 删除 key 并返回其值。key 不存在时返回 (zero, false)。
+func (o *OrderedMap[K, V]) Delete(key K) (V, bool) {
+	n, ok := o.m[key]
+	if !ok {
+		var zero V
+		return zero, false
+	}
+	delete(o.m, key)
+	o.unlink(n)
+	return n.val, true
+}
+
+// Front 返回前端(最早插入或最久未使用)的 key 及其值。map 为空时返回 ok == false。
+func (o *OrderedMap[K, V]) Front() (K, V, bool) {
+	return o.head.entry()
+}
+
+// Back 返回后端(最新插入或最近使用)的 key 及其值。map 为空时返回 ok == false。
+func (o *OrderedMap[K, V]) Back() (K, V, bool) {
+	return o.tail.entry()
+}
+
+// PopFront 移除并返回前端的 key 及其值;在 AccessOrder 模式下即淘汰最久未使用的 key。
+// map 为空时返回 ok == false。
+func (o *OrderedMap[K, V]) PopFront() (K, V, bool) {
+	n := o.head
+	if n != nil {
+		delete(o.m, n.key)
+		o.unlink(n)
+	}
+	return n.entry()
+}
+
+// PopBack 移除并返回后端的 key 及其值。map 为空时返回 ok == false。
+func (o *OrderedMap[K, V]) PopBack() (K, V, bool) {
+	n := o.tail
+	if n != nil {
+		delete(o.m, n.key)
+		o.unlink(n)
+	}
+	return n.entry()
+}
+
+// MoveToFront 将 key 移到前端。key 不存在时返回 false。
+func (o *OrderedMap[K, V]) MoveToFront(key K) bool {
+	n, ok := o.m[key]
+	if !ok {
+		return false
+	}
+	if n != o.head {
+		o.unlink(n)
+		o.pushFront(n)
+	}
+	return true
+}
+
+// MoveToBack 将 key 移到后端。key 不存在时返回 false。
+func (o *OrderedMap[K, V]) MoveToBack(key K) bool {
+	n, ok := o.m[key]
+	if !ok {
+		return false
+	}
+	if n != o.tail {
+		o.unlink(n)
+		o.pushBack(n)
+	}
+	return true
+}
+
+// Mode 返回排序模式。
+func (o *OrderedMap[K, V]) Mode() OrderMode { return o.mode }
+
+// Len 返回 key 的数量。
+func (o *OrderedMap[K, V]) Len() int { return len(o.m) }
+
+// IsEmpty 报告 map 是否没有 key。
+func (o *OrderedMap[K, V]) IsEmpty() bool { return len(o.m) == 0 }
+
+// Clear 移除所有 key。
+func (o *OrderedMap[K, V]) Clear() {
+	clear(o.m)
+	o.head, o.tail = nil, nil
+}
+
+// Keys 返回按当前顺序排列的 key 副本。
+func (o *OrderedMap[K, V]) Keys() []K {
+	out := make([]K, 0, len(o.m))
+	for n := o.head; n != nil; n = n.next {
+		out = append(out, n.key)
+	}
+	return out
+}
+
+// Values 返回按当前顺序排列的值副本。
+func (o *OrderedMap[K, V]) Values() []V {
+	out := make([]V, 0, len(o.m))
+	for n := o.head; n != nil; n = n.next {
+		out = append(out, n.val)
+	}
+	return out
+}
+
+// Clone 返回 map 的独立副本(浅拷贝 key 与值),顺序与模式相同。
+func (o *OrderedMap[K, V]) Clone() *OrderedMap[K, V] {
+	out := NewOrderedMap[K, V](o.mode)
+	for n := o.head; n != nil; n = n.next {
+		out.Put(n.key, n.val)
+	}
+	return out
+}
+
+// touch 在 AccessOrder 模式下将 n 移到后端。
+func (o *OrderedMap[K, V]) touch(n *omNode[K, V]) {
+	if o.mode == AccessOrder && n != o.tail {
+		o.unlink(n)
+		o.pushBack(n)
+	}
+}
+
+func (o *OrderedMap[K, V]) pushBack(n *omNode[K, V]) {
+	n.prev, n.next = o.tail, nil
+	if o.tail != nil {
+		o.tail.next = n
+	} else {
+		o.head = n
+	}
+	o.tail = n
+}
+
+func (o *OrderedMap[K, V]) pushFront(n *omNode[K, V]) {
+	n.prev, n.next = nil, o.head
+	if o.head != nil {
+		o.head.prev = n
+	} else {
+		o.tail = n
+	}
+	o.head = n
+}
+
+// unlink 将 n 从链表中摘下。n.next 保留,使遍历到 n 时删除它仍能继续。
+func (o *OrderedMap[K, V]) unlink(n *omNode[K, V]) {
+	if n.prev != nil {
+		n.prev.next = n.next
+	} else {
+		o.head = n.next
+	}
+	if n.next != nil {
+		n.next.prev = n.prev
+	} else {
+		o.tail = n.prev
+	}
+	n.prev = nil
+}
+
+// entry 将节点拆为 (key, val, ok);nil 节点返回零值与 false。
+func (n *omNode[K, V]) entry() (K, V, bool) {
+	if n == nil {
+		var (
+			k K
+			v V
+		)
+		return k, v, false
+	}
+	return n.key, n.val, true
+}
+
+// MarshalJSON 将 map 编码为 JSON 对象,key 按当前顺序输出。key 的编码规则与
+// encoding/json 对 map key 的规则一致:字符串、整数,或实现 [encoding.TextMarshaler] 的类型。
+// 值接收者使非指针的结构体字段也按此编码。
+func (o OrderedMap[K, V]) MarshalJSON() ([]byte, error) {
+	var buf bytes.Buffer
+	buf.WriteByte('{')
+	for n := o.head; n != nil; n = n.next {
+		if n != o.head {
+			buf.WriteByte(',')
+		}
+		ks, err := marshalKey(n.key)
+		if err != nil {
+			return nil, err
+		}
+		kb, err := json.Marshal(ks)
+		if err != nil {
+			return nil, err
+		}
+		vb, err := json.Marshal(n.val)
+		if err != nil {
+			return nil, err
+		}
+		buf.Write(kb)
+		buf.WriteByte(':')
+		buf.Write(vb)
+	}
+	buf.WriteByte('}')
+	return buf.Bytes(), nil
+}
+
+// UnmarshalJSON 从 JSON 对象解码,按 key 在文档中出现的顺序插入,替换 map 原有的内容;
+// 重复的 key 以最后一次的值为准,位置取第一次出现处。JSON null 不修改 map。模式保持不变。
+func (o *OrderedMap[K, V]) UnmarshalJSON(data []byte) error {
+	dec := json.NewDecoder(bytes.NewReader(data))
+	tok, err := dec.Token()
+	if err != nil {
+		return err
+	}
+	if tok == nil {
+		return nil
+	}
+	if d, ok := tok.(json.Delim); !ok || d != '{' {
+		return fmt.Errorf("collection: cannot unmarshal %v into OrderedMap", tok)
+	}
+
+	o.Clear()
+	for dec.More() {
+		tok, err := dec.Token()
+		if err != nil {
+			return err
+		}
+		key, err := unmarshalKey[K](tok.(string))
+		if err != nil {
+			return err
+		}
+		var val V
+		if err := dec.Decode(&val); err != nil {
+			return err
+		}
+		if o.mode == AccessOrder {
+			// 解码不是访问:保持文档顺序。
+			if n, ok := o.m[key]; ok {
+				n.val = val
+				continue
+			}
+		}
+		o.Put(key, val)
+	}
+	_, err = dec.Token() // 消费 '}'
+	return err
+}
+
+// marshalKey 按 encoding/json 的 map key 规则将 k 编码为字符串。
+func marshalKey[K comparable](k K) (string, error) {
+	if tm, ok := any(k).(encoding.TextMarshaler); ok {
+		b, err := tm.MarshalText()
+		return string(b), err
+	}
+	rv := reflect.ValueOf(k)
+	switch rv.Kind() {
+	case reflect.String:
+		return rv.String(), nil
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return strconv.FormatInt(rv.Int(), 10), nil
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr:
+		return strconv.FormatUint(rv.Uint(), 10), nil
+	}
+	return "", fmt.Errorf("collection: unsupported OrderedMap key type %T", k)
+}
+
+// unmarshalKey 按 encoding/json 的 map key 规则将字符串 s 解码为 K。
+func unmarshalKey[K comparable](s string) (K, error) {
+	var k K
+	if tu, ok := any(&k).(encoding.TextUnmarshaler); ok {
+		err := tu.UnmarshalText([]byte(s))
+		return k, err
+	}
+	rv := reflect.ValueOf(&k).Elem()
+	switch rv.Kind() {
+	case reflect.String:
+		rv.SetString(s)
+		return k, nil
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		n, err := strconv.ParseInt(s, 10, rv.Type().Bits())
+		if err != nil {
+			return k, fmt.Errorf("collection: OrderedMap key %q: %w", s, err)
+		}
+		rv.SetInt(n)
+		return k, nil
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr:
+		n, err := strconv.ParseUint(s, 10, rv.Type().Bits())
+		if err != nil {
+			return k, fmt.Errorf("collection: OrderedMap key %q: %w", s, err)
+		}
+		rv.SetUint(n)
+		return k, nil
+	}
+	return k, fmt.Errorf("collection: unsupported OrderedMap key type %T", k)
+}
@@ -9,7 +9,9 @@ import "cmp"
 // [NewMinHeap] 和 [NewMaxHeap],因此调用方几乎不必手写 less 函数。对于自定义排序
 // (多字段、计算优先级)请使用 [NewHeap] 并显式传入 less。
 //
-// Push 和 Pop 是 O(log n);Peek 和 Len 是 O(1)。
+// Push 和 Pop 是 O(log n);Peek 和 Len 是 O(1)。零值 Heap 没有 less,不可直接使用,
+// 也不能作为解码目标(见 [Heap.UnmarshalJSON]);需要零值可用、可直接解码的结构体字段时,
+// 有序类型请使用 [MinHeap] 或 [MaxHeap]。
 type Heap[T any] struct {
 	data []T
 	less func(a, b T) bool
@@ -31,14 +33,52 @@ func NewHeapWithCap[T any](cap int, less func(a, b T) bool) *Heap[T] {
 
 // NewMinHeap 创建一个基于有序类型的空 min-heap:最小元素具有最高优先级,最先出堆。
 func NewMinHeap[T cmp.Ordered]() *Heap[T] {
-	return NewHeap[T](func(a, b T) bool { return cmp.Compare(a, b) < 0 })
+	return NewHeap[T](minLess[T])
 }
 
 // NewMaxHeap 创建一个基于有序类型的空 max-heap:最大元素具有最高优先级,最先出堆。
 func NewMaxHeap[T cmp.Ordered]() *Heap[T] {
-	return NewHeap[T](func(a, b T) bool { return cmp.Compare(a, b) > 0 })
+	return NewHeap[T](maxLess[T])
 }
 
+// MinHeap 是有序类型上的 min-heap,行为同 [NewMinHeap] 的结果,但零值即可用:它可以直接
+// 作为配置结构体、API 载荷的字段,被 JSON、msgpack 与 YAML 解码而无需预先构造。其余方法
+// 经嵌入的 [Heap] 提供。
+type MinHeap[T cmp.Ordered] struct {
+	Heap[T]
+}
+
+// Push 将 v 添加到 heap。O(log n)。
+func (h *MinHeap[T]) Push(v T) { h.init().Push(v) }
+
+// init 为零值补上自然顺序的 less,返回内嵌的 Heap。
+func (h *MinHeap[T]) init() *Heap[T] {
+	if h.less == nil {
+		h.less = minLess[T]
+	}
+	return &h.Heap
+}
+
+// MaxHeap 是有序类型上的 max-heap,行为同 [NewMaxHeap] 的结果,零值即可用,见 [MinHeap]。
+type MaxHeap[T cmp.Ordered] struct {
+	Heap[T]
+}
+
+// Push 将 v 添加到 heap。O(log n)。
+func (h *MaxHeap[T]) Push(v T) { h.init().Push(v) }
+
+// init 为零值补上逆自然顺序的 less,返回内嵌的 Heap。
+func (h *MaxHeap[T]) init() *Heap[T] {
+	if h.less == nil {
+		h.less = maxLess[T]
+	}
+	return &h.Heap
+}
+
+func minLess[T cmp.Ordered](a, b T) bool { return cmp.Compare(a, b) < 0 }
+
+func maxLess[T cmp.Ordered](a, b T) bool { return cmp.Compare(a, b) > 0 }
+
 // Push 将 v 添加到 heap。O(log n)。
 func (h *Heap[T]) Push(v T) {
 	h.data = append(h.data, v)
@@ -0,0 +1,268 @@
+package collection
+
+import (
+	"slices"
+	"strings"
+)
+
+// RadixTree 是一个以 string 为 key 的 radix tree(压缩前缀树),用于路由、配置 key 路径、
+// feature flag 命名空间等按前缀匹配的场景,取代对全部 key 的线性扫描。
+//
+// 只有一个子节点且自身不存值的节点会与子节点合并,因此节点数不超过 2·Len,每条边保存
+// 一段公共前缀而不是单个字节。Insert/Get/Delete/LongestPrefix 是 O(len(key)),与 key 的
+// 总数无关。key 按字节比较,[RadixTree.All]、[RadixTree.WalkPrefix] 与 [RadixTree.Keys]
+// 都按字典序产出。
+//
+// 遍历期间不得修改树;需要边遍历边删除时,先用 Keys 收集再逐个 Delete。
+type RadixTree[V any] struct {
+	root radixNode[V]
+	n    int
+}
+
+type radixNode[V any] struct {
+	label    string          // 从父节点到本节点的边;根节点为空
+	children []*radixNode[V] // 按 label 首字节升序,首字节互不相同
+	val      V
+	leaf     bool // 本节点是否存有 key
+}
+
+// NewRadixTree 创建一个空 radix tree。
+func NewRadixTree[V any]() *RadixTree[V] {
+	return &RadixTree[V]{}
+}
+
+// Insert 将 key 关联到 val。key 已存在时替换其值并返回 false,否则插入并返回 true。
+// 空字符串也是合法的 key。
+func (t *RadixTree[V]) Insert(key string, val V) bool {
+	n, search := &t.root, key
+	for {
+		if search == "" {
+			added := !n.leaf
+			n.val, n.leaf = val, true
+			if added {
+				t.n++
+			}
+			return added
+		}
+		i, child := n.child(search[0])
+		if child == nil {
+			n.children = slices.Insert(n.children, i, &radixNode[V]{label: search, val: val, leaf: true})
+			t.n++
+			return true
+		}
+		common := commonPrefixLen(search, child.label)
+		if common == len(child.label) {
+			n, search = child, search[common:]
+			continue
+		}
+		// search 与 child 的边在中途分叉:在分叉点插入一个中间节点。
+		mid := &radixNode[V]{label: search[:common], children: []*radixNode[V]{child}}
+		child.label = child.label[common:]
+		n.children[i] = mid
+		if search = search[common:]; search == "" {
+			mid.val, mid.leaf = val, true
+		} else {
+			mid.addChild(&radixNode[V]{label: search, val: val, leaf: true})
+		}
+		t.n++
+		return true
+	}
+}
+
+// Get 返回 key 关联的值。key 不存在时返回 (zero, false)。
+func (t *RadixTree[V]) Get(key string) (V, bool) {
+	if n := t.find(key); n != nil && n.leaf {
+		return n.val, true
+	}
+	var zero V
+	return zero, false
+}
+
+// Contains 报告 key 是否存在。
+func (t *RadixTree[V]) Contains(key string) bool {
+	n := t.find(key)
+	return n != nil && n.leaf
+}
+
+// Delete 删除 key 并返回其值。key 不存在时返回 (zero, false)。删除后不再存值的节点会被
+// 移除或与唯一的子节点合并,树始终保持压缩形态。
+func (t *RadixTree[V]) Delete(key string) (V, bool) {
+	var zero V
+	var parent *radixNode[V]
+	n, search := &t.root, key
+	for search != "" {
+		_, child := n.child(search[0])
+		if child == nil || !strings.HasPrefix(search, child.label) {
+			return zero, false
+		}
+		parent, n, search = n, child, search[len(child.label):]
+	}
+	if !n.leaf {
+		return zero, false
+	}
+	v := n.val
+	n.val, n.leaf = zero, false // 让 GC 回收值持有的引用
+	t.n--
+
+	switch {
+	case n == &t.root:
+	case len(n.children) == 0:
+		i, _ := parent.child(n.label[0])
+		parent.children = slices.Delete(parent.children, i, i+1)
+		if parent != &t.root && !parent.leaf && len(parent.children) == 1 {
+			parent.mergeChild()
+		}
+	case len(n.children) == 1:
+		n.mergeChild()
+	}
+	return v, true
+}
+
+// LongestPrefix 返回树中是 s 前缀的最长 key 及其值,用于路由表一类"最具体的匹配"。没有任何
+// key 是 s 的前缀时返回 ok == false。
+func (t *RadixTree[V]) LongestPrefix(s string) (key string, val V, ok bool) {
+	n, consumed := &t.root, 0
+	for {
+		if n.leaf {
+			key, val, ok = s[:consumed], n.val, true
+		}
+		if consumed == len(s) {
+			return key, val, ok
+		}
+		_, child := n.child(s[consumed])
+		if child == nil || !strings.HasPrefix(s[consumed:], child.label) {
+			return key, val, ok
+		}
+		n, consumed = child, consumed+len(child.label)
+	}
+}
+
+// HasPrefix 报告树中是否存在以 prefix 为前缀的 key。
+func (t *RadixTree[V]) HasPrefix(prefix string) bool {
+	n, _ := t.seek(prefix)
+	return n != nil
+}
+
+// Len 返回 key 的个数。
+func (t *RadixTree[V]) Len() int { return t.n }
+
+// IsEmpty 报告树是否为空。
+func (t *RadixTree[V]) IsEmpty() bool { return t.n == 0 }
+
+// Clear 删除所有 key。
+func (t *RadixTree[V]) Clear() {
+	t.root = radixNode[V]{}
+	t.n = 0
+}
+
+// Keys 返回按字典序排列的 key 副本。
+func (t *RadixTree[V]) Keys() []string {
+	out := make([]string, 0, t.n)
+	for k := range t.All() {
+		out = append(out, k)
+	}
+	return out
+}
+
+// Values 返回按 key 字典序排列的值副本。
+func (t *RadixTree[V]) Values() []V {
+	out := make([]V, 0, t.n)
+	for _, v := range t.All() {
+		out = append(out, v)
+	}
+	return out
+}
+
+// Clone 返回树的独立副本。值本身是浅拷贝。
+func (t *RadixTree[V]) Clone() *RadixTree[V] {
+	return &RadixTree[V]{root: *t.root.clone(), n: t.n}
+}
+
+// find 返回恰好对应 key 的节点(不论是否存值);不存在时返回 nil。
+func (t *RadixTree[V]) find(key string) *radixNode[V] {
+	n, search := &t.root, key
+	for search != "" {
+		_, child := n.child(search[0])
+		if child == nil || !strings.HasPrefix(search, child.label) {
+			return nil
+		}
+		n, search = child, search[len(child.label):]
+	}
+	return n
+}
+
+// seek 返回子树覆盖所有以 prefix 开头的 key 的最浅节点,以及该节点对应的完整路径
+// (可能比 prefix 长,因为 prefix 可以止于一条边的中途)。不存在时返回 nil。
+func (t *RadixTree[V]) seek(prefix string) (*radixNode[V], string) {
+	n, search := &t.root, prefix
+	for search != "" {
+		_, child := n.child(search[0])
+		switch {
+		case child == nil:
+			return nil, ""
+		case strings.HasPrefix(search, child.label):
+			n, search = child, search[len(child.label):]
+		case strings.HasPrefix(child.label, search):
+			return child, prefix + child.label[len(search):]
+		default:
+			return nil, ""
+		}
+	}
+	return n, prefix
+}
+
+// walk 以先序(即字典序)遍历 n 的子树;path 是到 n 为止的完整 key。yield 返回 false 时
+// 停止并返回 false。
+func (n *radixNode[V]) walk(path []byte, yield func(string, V) bool) bool {
+	if n.leaf && !yield(string(path), n.val) {
+		return false
+	}
+	for _, c := range n.children {
+		if !c.walk(append(path, c.label...), yield) {
+			return false
+		}
+	}
+	return true
+}
+
+// child 返回首字节为 b 的子节点及其下标;不存在时返回 nil 与应插入的下标。
+func (n *radixNode[V]) child(b byte) (int, *radixNode[V]) {
+	i, found := slices.BinarySearchFunc(n.children, b, func(c *radixNode[V], b byte) int {
+		return int(c.label[0]) - int(b)
+	})
+	if !found {
+		return i, nil
+	}
+	return i, n.children[i]
+}
+
+func (n *radixNode[V]) addChild(c *radixNode[V]) {
+	i, _ := n.child(c.label[0])
+	n.children = slices.Insert(n.children, i, c)
+}
+
+// mergeChild 将唯一的子节点并入 n。调用方保证 n 不存值且恰有一个子节点。
+func (n *radixNode[V]) mergeChild() {
+	c := n.children[0]
+	n.label += c.label
+	n.children, n.val, n.leaf = c.children, c.val, c.leaf
+}
+
+func (n *radixNode[V]) clone() *radixNode[V] {
+	out := *n
+	out.children = make([]*radixNode[V], len(n.children))
+	for i, c := range n.children {
+		out.children[i] = c.clone()
+	}
+	return &out
+}
+
+func commonPrefixLen(a, b string) int {
+	n := min(len(a), len(b))
+	for i := 0; i < n; i++ {
+		if a[i] != b[i] {
+			return i
+		}
+	}
+	return n
+}
@@ -0,0 +1,125 @@
+package collection
+
+// RingMode 决定 [RingBuffer] 填满后如何处理新的 Push。
+type RingMode int
+
+const (
+	// RingOverwrite 在填满时覆盖最旧的元素,使 buffer 始终保留最近 cap 个元素。
+	// 适合最近 N 次延迟的滑动窗口、日志尾部等场景。
+	RingOverwrite RingMode = iota
+	// RingReject 在填满时拒绝新元素,Push 返回 false,已有元素保持不变。
+	RingReject
+)
+
+// RingBuffer 是一个固定 capacity 的环形缓冲。与会扩容的 [Queue] 不同,它的内存在构造时
+// 一次分配,之后永不增长:填满时按 [RingMode] 覆盖最旧元素或拒绝新元素。
+//
+// 元素按从最旧到最新的顺序排列;Push 写入最新端,Pop 从最旧端取出。Push/Pop/At 都是 O(1)。
+//
+// 零值是可用的空 buffer:capacity 为默认值、模式为 [RingOverwrite],首次 Push 时才分配。
+type RingBuffer[T any] struct {
+	buf   []T
+	head  int // 最旧元素的索引
+	count int // 元素数量
+	mode  RingMode
+}
+
+// NewRingBuffer 创建一个固定 capacity 为 cap、填满时按 mode 处理的空 ring buffer。
+// 非正的 cap 会回退到默认 capacity。
+func NewRingBuffer[T any](cap int, mode RingMode) *RingBuffer[T] {
+	if cap <= 0 {
+		cap = defaultCap
+	}
+	return &RingBuffer[T]{buf: make([]T, cap), mode: mode}
+}
+
+// Push 将 v 作为最新元素写入。buffer 未满时总是成功;填满时,[RingOverwrite] 模式覆盖
+// 最旧元素并返回 true,[RingReject] 模式不写入并返回 false。O(1)。
+func (r *RingBuffer[T]) Push(v T) bool {
+	if r.buf == nil {
+		r.buf = make([]T, defaultCap)
+	}
+	if r.count == len(r.buf) {
+		if r.mode == RingReject {
+			return false
+		}
+		r.buf[r.head] = v
+		r.head = (r.head + 1) % len(r.buf)
+		return true
+	}
+	r.buf[(r.head+r.count)%len(r.buf)] = v
+	r.count++
+	return true
+}
+
+// Pop 移除并返回最旧的元素。当 buffer 为空时返回 (zero, false)。取出的槽位会被清零,
+// 以便 GC 回收它持有的任何引用。
+func (r *RingBuffer[T]) Pop() (T, bool) {
+	var zero T
+	if r.count == 0 {
+		return zero, false
+	}
+	v := r.buf[r.head]
+	r.buf[r.head] = zero
+	r.head = (r.head + 1) % len(r.buf)
+	r.count--
+	return v, true
+}
+
+// Oldest 返回最旧的元素但不移除它。当 buffer 为空时返回 (zero, false)。
+func (r *RingBuffer[T]) Oldest() (T, bool) {
+	return r.At(0)
+}
+
+// Newest 返回最新的元素但不移除它。当 buffer 为空时返回 (zero, false)。
+func (r *RingBuffer[T]) Newest() (T, bool) {
+	return r.At(r.count - 1)
+}
+
+// At 返回从最旧端数起第 i 个元素(0 为最旧)。i 越界时返回 (zero, false)。O(1)。
+func (r *RingBuffer[T]) At(i int) (T, bool) {
+	var zero T
+	if i < 0 || i >= r.count {
+		return zero, false
+	}
+	return r.buf[(r.head+i)%len(r.buf)], true
+}
+
+// Len 返回元素数量。
+func (r *RingBuffer[T]) Len() int { return r.count }
+
+// Cap 返回固定的 capacity。
+func (r *RingBuffer[T]) Cap() int {
+	if r.buf == nil {
+		return defaultCap
+	}
+	return len(r.buf)
+}
+
+// IsEmpty 报告 buffer 是否没有元素。
+func (r *RingBuffer[T]) IsEmpty() bool { return r.count == 0 }
+
+// IsFull 报告 buffer 是否已达到 capacity。
+func (r *RingBuffer[T]) IsFull() bool { return r.count == r.Cap() }
+
+// Clear 移除所有元素并清零 buffer。capacity 与 mode 保持不变。
+func (r *RingBuffer[T]) Clear() {
+	clear(r.buf)
+	r.head, r.count = 0, 0
+}
+
+// Values 返回按从最旧到最新顺序排列的元素副本。返回的 slice 是独立的;修改它不会影响 buffer。
+func (r *RingBuffer[T]) Values() []T {
+	out := make([]T, r.count)
+	for i := range out {
+		out[i] = r.buf[(r.head+i)%len(r.buf)]
+	}
+	return out
+}
+
+// Clone 返回 buffer 的独立副本,capacity 与 mode 相同。副本线性排列(head=0)。
+func (r *RingBuffer[T]) Clone() *RingBuffer[T] {
+	out := make([]T, r.Cap())
+	copy(out, r.Values())
+	return &RingBuffer[T]{buf: out, count: r.count, mode: r.mode}
+}
@@ -0,0 +1,106 @@
+package collection
+
+import (
+	"encoding/binary"
+	"errors"
+	"fmt"
+)
+
+// ErrSketchMismatch 在合并参数(大小、哈希函数个数、精度)不同的 [BloomFilter]、
+// [CountMinSketch] 或 [HyperLogLog] 时返回。
+var ErrSketchMismatch = errors.New("collection: sketch parameters do not match")
+
+// 概率结构使用固定种子的 64 位哈希(FNV-1a 后接 splitmix64 混合),而不是每进程随机种子的
+// hash/maphash:同一输入在任何实例、任何进程中都得到相同的哈希,序列化后的状态才能跨实例合并。
+
+const (
+	fnvOffset64 = 14695981039346656037
+	fnvPrime64  = 1099511628211
+)
+
+// sketchHash 返回 data 的 64 位哈希。
+func sketchHash(data []byte) uint64 {
+	h := uint64(fnvOffset64)
+	for _, c := range data {
+		h ^= uint64(c)
+		h *= fnvPrime64
+	}
+	return mix64(h)
+}
+
+// sketchHashString 与 sketchHash 等价,但不为 string 分配 []byte。
+func sketchHashString(s string) uint64 {
+	h := uint64(fnvOffset64)
+	for i := 0; i < len(s); i++ {
+		h ^= uint64(s[i])
+		h *= fnvPrime64
+	}
+	return mix64(h)
+}
+
+// mix64 是 splitmix64 的终结混合,弥补 FNV 低位分布较差的问题。
+func mix64(h uint64) uint64 {
+	h ^= h >> 30
+	h *= 0xbf58476d1ce4e5b9
+	h ^= h >> 27
+	h *= 0x94d049bb133111eb
+	h ^= h >> 31
+	return h
+}
+
+// doubleHash 由一个 64 位哈希派生出 Kirsch–Mitzenmacher 双重哈希所需的两个值;
+// 第 i 个哈希取 h1 + i*h2。h2 恒为奇数,避免在 2 的幂大小上退化。
+func doubleHash(h uint64) (h1, h2 uint64) {
+	return h, mix64(h^0x9e3779b97f4a7c15) | 1
+}
+
+// sketchReader 顺序读取二进制编码,遇到截断时记录错误并返回零值。
+type sketchReader struct {
+	kind string
+	data []byte
+	err  error
+}
+
+func newSketchReader(kind, magic string, data []byte) *sketchReader {
+	r := &sketchReader{kind: kind, data: data}
+	if len(data) < len(magic) || string(data[:len(magic)]) != magic {
+		r.err = fmt.Errorf("collection: decode %s: bad header", kind)
+		return r
+	}
+	r.data = data[len(magic):]
+	return r
+}
+
+func (r *sketchReader) uint64() uint64 {
+	if r.err != nil {
+		return 0
+	}
+	if len(r.data) < 8 {
+		r.err = fmt.Errorf("collection: decode %s: truncated data", r.kind)
+		return 0
+	}
+	v := binary.LittleEndian.Uint64(r.data)
+	r.data = r.data[8:]
+	return v
+}
+
+func (r *sketchReader) bytes(n uint64) []byte {
+	if r.err != nil {
+		return nil
+	}
+	if uint64(len(r.data)) < n {
+		r.err = fmt.Errorf("collection: decode %s: truncated data", r.kind)
+		return nil
+	}
+	b := r.data[:n]
+	r.data = r.data[n:]
+	return b
+}
+
+// done 返回读取过程中的错误;数据有剩余时也视为错误。
+func (r *sketchReader) done() error {
+	if r.err == nil && len(r.data) != 0 {
+		r.err = fmt.Errorf("collection: decode %s: %d trailing bytes", r.kind, len(r.data))
+	}
+	return r.err
+}
@@ -0,0 +1,273 @@
+package collection
+
+import "math/rand/v2"
+
+// skipMaxLevel 是 skip list 的最大层数;以 1/4 的晋升概率,32 层足以容纳 4^32 个元素。
+const skipMaxLevel = 32
+
+// skipLink 是一层上指向后继节点的指针。span 是沿第 0 层从当前节点走到 next 需要的步数,
+// 用于 O(log n) 的 rank 计算与按下标访问;next 为 nil 时 span 无意义。
+type skipLink[K, V any] struct {
+	next *skipNode[K, V]
+	span int
+}
+
+type skipNode[K, V any] struct {
+	key     K
+	val     V
+	links   []skipLink[K, V]
+	prev    *skipNode[K, V] // 第 0 层的前驱;首元素为 nil
+	removed bool            // 已被删除,供迭代器判断是否需要按 key 重新定位
+}
+
+// skipList 是 [SortedMap] 与 [SortedSet] 共享的有序底层:一个带 span 的 indexable skip list。
+// 查找、插入、删除、Rank、At 均为期望 O(log n)。key 的相等性由 less 推导:
+// !less(a, b) && !less(b, a)。
+type skipList[K, V any] struct {
+	head   *skipNode[K, V] // 哨兵,不存元素
+	tail   *skipNode[K, V] // 最大元素;空表为 nil
+	level  int
+	length int
+	less   func(a, b K) bool
+}
+
+func newSkipList[K, V any](less func(a, b K) bool) *skipList[K, V] {
+	return &skipList[K, V]{
+		head:  &skipNode[K, V]{links: make([]skipLink[K, V], skipMaxLevel)},
+		level: 1,
+		less:  less,
+	}
+}
+
+// randomLevel 以 1/4 的概率逐层晋升。
+func randomLevel() int {
+	lvl := 1
+	for lvl < skipMaxLevel && rand.Uint32()&3 == 0 {
+		lvl++
+	}
+	return lvl
+}
+
+// put 插入或更新 key。key 已存在时只替换值并返回 false。
+func (s *skipList[K, V]) put(key K, val V) bool {
+	var (
+		update [skipMaxLevel]*skipNode[K, V]
+		rank   [skipMaxLevel]int // rank[i] 是 update[i] 的 1-based 位置(head 为 0)
+	)
+	x := s.head
+	for i := s.level - 1; i >= 0; i-- {
+		if i < s.level-1 {
+			rank[i] = rank[i+1]
+		}
+		for l := x.links[i]; l.next != nil && s.less(l.next.key, key); l = x.links[i] {
+			rank[i] += l.span
+			x = l.next
+		}
+		update[i] = x
+	}
+	if n := x.links[0].next; n != nil && !s.less(key, n.key) {
+		n.val = val
+		return false
+	}
+
+	lvl := randomLevel()
+	if lvl > s.level {
+		for i := s.level; i < lvl; i++ {
+			update[i] = s.head
+			s.head.links[i].span = s.length
+		}
+		s.level = lvl
+	}
+	n := &skipNode[K, V]{key: key, val: val, links: make([]skipLink[K, V], lvl)}
+	for i := 0; i < lvl; i++ {
+		prev := &update[i].links[i]
+		n.links[i] = skipLink[K, V]{next: prev.next, span: prev.span - (rank[0] - rank[i])}
+		*prev = skipLink[K, V]{next: n, span: rank[0] - rank[i] + 1}
+	}
+	for i := lvl; i < s.level; i++ {
+		update[i].links[i].span++
+	}
+
+	if update[0] != s.head {
+		n.prev = update[0]
+	}
+	if next := n.links[0].next; next != nil {
+		next.prev = n
+	} else {
+		s.tail = n
+	}
+	s.length++
+	return true
+}
+
+// remove 删除 key 并返回被删除的节点;key 不存在时返回 nil。
+func (s *skipList[K, V]) remove(key K) *skipNode[K, V] {
+	var update [skipMaxLevel]*skipNode[K, V]
+	x := s.head
+	for i := s.level - 1; i >= 0; i-- {
+		for l := x.links[i]; l.next != nil && s.less(l.next.key, key); l = x.links[i] {
+			x = l.next
+		}
+		update[i] = x
+	}
+	n := x.links[0].next
+	if n == nil || s.less(key, n.key) {
+		return nil
+	}
+
+	for i := 0; i < s.level; i++ {
+		l := &update[i].links[i]
+		if l.next == n {
+			l.span += n.links[i].span - 1
+			l.next = n.links[i].next
+		} else {
+			l.span--
+		}
+	}
+	if next := n.links[0].next; next != nil {
+		next.prev = n.prev
+	} else {
+		s.tail = n.prev
+	}
+	for s.level > 1 && s.head.links[s.level-1].next == nil {
+		s.level--
+	}
+	s.length--
+
+	// 断开引用以便 GC 回收;key 保留,供正在遍历到 n 的迭代器重新定位。
+	n.links, n.prev, n.removed = nil, nil, true
+	return n
+}
+
+// find 返回 key 对应的节点;不存在时返回 nil。
+func (s *skipList[K, V]) find(key K) *skipNode[K, V] {
+	n := s.ceiling(key)
+	if n == nil || s.less(key, n.key) {
+		return nil
+	}
+	return n
+}
+
+// ceiling 返回第一个 >= key 的节点;不存在时返回 nil。
+func (s *skipList[K, V]) ceiling(key K) *skipNode[K, V] {
+	x := s.head
+	for i := s.level - 1; i >= 0; i-- {
+		for l := x.links[i]; l.next != nil && s.less(l.next.key, key); l = x.links[i] {
+			x = l.next
+		}
+	}
+	return x.links[0].next
+}
+
+// higher 返回第一个 > key 的节点;不存在时返回 nil。
+func (s *skipList[K, V]) higher(key K) *skipNode[K, V] {
+	x := s.head
+	for i := s.level - 1; i >= 0; i-- {
+		for l := x.links[i]; l.next != nil && !s.less(key, l.next.key); l = x.links[i] {
+			x = l.next
+		}
+	}
+	return x.links[0].next
+}
+
+// floor 返回最后一个 <= key 的节点;不存在时返回 nil。
+func (s *skipList[K, V]) floor(key K) *skipNode[K, V] {
+	x := s.head
+	for i := s.level - 1; i >= 0; i-- {
+		for l := x.links[i]; l.next != nil && !s.less(key, l.next.key); l = x.links[i] {
+			x = l.next
+		}
+	}
+	if x == s.head {
+		return nil
+	}
+	return x
+}
+
+// lower 返回最后一个 < key 的节点;不存在时返回 nil。
+func (s *skipList[K, V]) lower(key K) *skipNode[K, V] {
+	x := s.head
+	for i := s.level - 1; i >= 0; i-- {
+		for l := x.links[i]; l.next != nil && s.less(l.next.key, key); l = x.links[i] {
+			x = l.next
+		}
+	}
+	if x == s.head {
+		return nil
+	}
+	return x
+}
+
+// rank 返回严格小于 key 的元素个数。
+func (s *skipList[K, V]) rank(key K) int {
+	r := 0
+	x := s.head
+	for i := s.level - 1; i >= 0; i-- {
+		for l := x.links[i]; l.next != nil && s.less(l.next.key, key); l = x.links[i] {
+			r += l.span
+			x = l.next
+		}
+	}
+	return r
+}
+
+// at 返回 0-based 第 i 个节点;越界时返回 nil。
+func (s *skipList[K, V]) at(i int) *skipNode[K, V] {
+	if i < 0 || i >= s.length {
+		return nil
+	}
+	target, traversed := i+1, 0
+	x := s.head
+	for lv := s.level - 1; lv >= 0; lv-- {
+		for l := x.links[lv]; l.next != nil && traversed+l.span <= target; l = x.links[lv] {
+			traversed += l.span
+			x = l.next
+		}
+		if traversed == target {
+			return x
+		}
+	}
+	return nil
+}
+
+// first 返回最小的节点;空表返回 nil。
+func (s *skipList[K, V]) first() *skipNode[K, V] { return s.head.links[0].next }
+
+// clear 移除所有元素。
+func (s *skipList[K, V]) clear() {
+	clear(s.head.links)
+	s.tail, s.level, s.length = nil, 1, 0
+}
+
+// ascend 从 start 起按升序遍历,直到 stop(key) 为 true 或 yield 返回 false。
+// yield 期间删除任意元素(包括当前元素)是安全的:当前节点被删除时,按其 key 重新定位到
+// 下一个更大的元素;插入到当前位置之后的元素会被遍历到。
+func (s *skipList[K, V]) ascend(start *skipNode[K, V], stop func(K) bool, yield func(*skipNode[K, V]) bool) {
+	for n := start; n != nil; {
+		if stop != nil && stop(n.key) {
+			return
+		}
+		if !yield(n) {
+			return
+		}
+		if n.removed {
+			n = s.higher(n.key)
+		} else {
+			n = n.links[0].next
+		}
+	}
+}
+
+// descend 从 start 起按降序遍历,规则同 [skipList.ascend]。
+func (s *skipList[K, V]) descend(start *skipNode[K, V], yield func(*skipNode[K, V]) bool) {
+	for n := start; n != nil; {
+		if !yield(n) {
+			return
+		}
+		if n.removed {
+			n = s.lower(n.key)
+		} else {
+			n = n.prev
+		}
+	}
+}
@@ -0,0 +1,137 @@
+package collection
+
+import "cmp"
+
+// SortedMap 是一个按 key 有序的关联容器,底层为 skip list。
+//
+// 顺序由 less 函数定义:less(a, b) == true 表示 a 排在 b 之前;两个 key 互不 less 即视为
+// 相等。与 [Heap] 一样,有序类型有专用构造器 [NewSortedMapOrdered],自定义排序请使用
+// [NewSortedMap] 并显式传入 less。
+//
+// Put/Get/Delete/Floor/Ceiling/Rank/At 是期望 O(log n);Len 是 O(1)。[SortedMap.All]、
+// [SortedMap.Backward] 与 [SortedMap.Range] 返回 [iter.Seq2],取代"对 map 的 key
+// 手动排序再遍历"。遍历期间可以安全地 Delete 任意 key(包括当前 key);在当前位置之后
+// Put 的新 key 会被遍历到,之前的不会。
+type SortedMap[K, V any] struct {
+	sl *skipList[K, V]
+}
+
+// NewSortedMap 创建一个按 less 排序的空 sorted map。less(a, b) == true 表示 a 排在 b 之前。
+func NewSortedMap[K, V any](less func(a, b K) bool) *SortedMap[K, V] {
+	return &SortedMap[K, V]{sl: newSkipList[K, V](less)}
+}
+
+// NewSortedMapOrdered 创建一个基于有序类型、按 key 升序排列的空 sorted map。
+func NewSortedMapOrdered[K cmp.Ordered, V any]() *SortedMap[K, V] {
+	return NewSortedMap[K, V](cmp.Less[K])
+}
+
+// Put 将 key 关联到 val。key 已存在时替换其值并返回 false,否则插入并返回 true。
+func (m *SortedMap[K, V]) Put(key K, val V) bool {
+	return m.sl.put(key, val)
+}
+
+// Get 返回 key 关联的值。key 不存在时返回 (zero, false)。
+func (m *SortedMap[K, V]) Get(key K) (V, bool) {
+	if n := m.sl.find(key); n != nil {
+		return n.val, true
+	}
+	var zero V
+	return zero, false
+}
+
+// Contains 报告 key 是否存在。
+func (m *SortedMap[K, V]) Contains(key K) bool {
+	return m.sl.find(key) != nil
+}
+
+// Delete 删除 key 并返回其值。key 不存在时返回 (zero, false)。
+func (m *SortedMap[K, V]) Delete(key K) (V, bool) {
+	var zero V
+	n := m.sl.remove(key)
+	if n == nil {
+		return zero, false
+	}
+	v := n.val
+	n.val = zero // 让 GC 回收值持有的引用
+	return v, true
+}
+
+// Min 返回最小的 key 及其值。map 为空时返回 ok == false。
+func (m *SortedMap[K, V]) Min() (K, V, bool) {
+	return entry(m.sl.first())
+}
+
+// Max 返回最大的 key 及其值。map 为空时返回 ok == false。
+func (m *SortedMap[K, V]) Max() (K, V, bool) {
+	return entry(m.sl.tail)
+}
+
+// Floor 返回 <= key 的最大 key 及其值。不存在时返回 ok == false。
+func (m *SortedMap[K, V]) Floor(key K) (K, V, bool) {
+	return entry(m.sl.floor(key))
+}
+
+// Ceiling 返回 >= key 的最小 key 及其值。不存在时返回 ok == false。
+func (m *SortedMap[K, V]) Ceiling(key K) (K, V, bool) {
+	return entry(m.sl.ceiling(key))
+}
+
+// Rank 返回严格小于 key 的 key 个数,即 key 在 map 中(或插入后)的 0-based 位置。
+// key 不必存在。
+func (m *SortedMap[K, V]) Rank(key K) int {
+	return m.sl.rank(key)
+}
+
+// At 返回按顺序第 i 个(0-based)key 及其值。i 越界时返回 ok == false。
+func (m *SortedMap[K, V]) At(i int) (K, V, bool) {
+	return entry(m.sl.at(i))
+}
+
+// Len 返回 key 的数量。
+func (m *SortedMap[K, V]) Len() int { return m.sl.length }
+
+// IsEmpty 报告 map 是否没有 key。
+func (m *SortedMap[K, V]) IsEmpty() bool { return m.sl.length == 0 }
+
+// Clear 移除所有 key。
+func (m *SortedMap[K, V]) Clear() { m.sl.clear() }
+
+// Keys 返回按升序排列的 key 副本。
+func (m *SortedMap[K, V]) Keys() []K {
+	out := make([]K, 0, m.sl.length)
+	for n := m.sl.first(); n != nil; n = n.links[0].next {
+		out = append(out, n.key)
+	}
+	return out
+}
+
+// Values 返回按 key 升序排列的值副本。
+func (m *SortedMap[K, V]) Values() []V {
+	out := make([]V, 0, m.sl.length)
+	for n := m.sl.first(); n != nil; n = n.links[0].next {
+		out = append(out, n.val)
+	}
+	return out
+}
+
+// Clone 返回 map 的独立副本(浅拷贝 key 与值),排序规则相同。
+func (m *SortedMap[K, V]) Clone() *SortedMap[K, V] {
+	out := NewSortedMap[K, V](m.sl.less)
+	for n := m.sl.first(); n != nil; n = n.links[0].next {
+		out.sl.put(n.key, n.val)
+	}
+	return out
+}
+
+// entry 将节点拆为 (key, val, ok);nil 节点返回零值与 false。
+func entry[K, V any](n *skipNode[K, V]) (K, V, bool) {
+	if n == nil {
+		var (
+			k K
+			v V
+		)
+		return k, v, false
+	}
+	return n.key, n.val, true
+}
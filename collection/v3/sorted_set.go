@@ -0,0 +1,104 @@
+package collection
+
+import "cmp"
+
+// SortedSet 是一个元素有序、去重的集合,底层与 [SortedMap] 共享同一 skip list 实现。
+//
+// 与无序的 [Set] 相比,它支持 Min/Max、Floor/Ceiling、Rank/At 与按区间遍历;元素不必
+// comparable,相等性由 less 推导。有序类型请使用 [NewSortedSetOrdered]。遍历期间删除
+// 元素的规则同 [SortedMap]。
+type SortedSet[T any] struct {
+	sl *skipList[T, struct{}]
+}
+
+// NewSortedSet 创建一个按 less 排序的 sorted set,并加入 vals。less(a, b) == true 表示
+// a 排在 b 之前。
+func NewSortedSet[T any](less func(a, b T) bool, vals ...T) *SortedSet[T] {
+	s := &SortedSet[T]{sl: newSkipList[T, struct{}](less)}
+	for _, v := range vals {
+		s.Add(v)
+	}
+	return s
+}
+
+// NewSortedSetOrdered 创建一个基于有序类型、升序排列的 sorted set,并加入 vals。
+func NewSortedSetOrdered[T cmp.Ordered](vals ...T) *SortedSet[T] {
+	return NewSortedSet(cmp.Less[T], vals...)
+}
+
+// Add 加入 v。v 已存在时返回 false。
+func (s *SortedSet[T]) Add(v T) bool {
+	return s.sl.put(v, struct{}{})
+}
+
+// Remove 移除 v。v 不存在时返回 false。
+func (s *SortedSet[T]) Remove(v T) bool {
+	return s.sl.remove(v) != nil
+}
+
+// Contains 报告 v 是否存在。
+func (s *SortedSet[T]) Contains(v T) bool {
+	return s.sl.find(v) != nil
+}
+
+// Min 返回最小元素。set 为空时返回 (zero, false)。
+func (s *SortedSet[T]) Min() (T, bool) {
+	return elem(s.sl.first())
+}
+
+// Max 返回最大元素。set 为空时返回 (zero, false)。
+func (s *SortedSet[T]) Max() (T, bool) {
+	return elem(s.sl.tail)
+}
+
+// Floor 返回 <= v 的最大元素。不存在时返回 (zero, false)。
+func (s *SortedSet[T]) Floor(v T) (T, bool) {
+	return elem(s.sl.floor(v))
+}
+
+// Ceiling 返回 >= v 的最小元素。不存在时返回 (zero, false)。
+func (s *SortedSet[T]) Ceiling(v T) (T, bool) {
+	return elem(s.sl.ceiling(v))
+}
+
+// Rank 返回严格小于 v 的元素个数。v 不必存在。
+func (s *SortedSet[T]) Rank(v T) int {
+	return s.sl.rank(v)
+}
+
+// At 返回按顺序第 i 个(0-based)元素。i 越界时返回 (zero, false)。
+func (s *SortedSet[T]) At(i int) (T, bool) {
+	return elem(s.sl.at(i))
+}
+
+// Len 返回元素数量。
+func (s *SortedSet[T]) Len() int { return s.sl.length }
+
+// IsEmpty 报告 set 是否没有元素。
+func (s *SortedSet[T]) IsEmpty() bool { return s.sl.length == 0 }
+
+// Clear 移除所有元素。
+func (s *SortedSet[T]) Clear() { s.sl.clear() }
+
+// Values 返回按升序排列的元素副本。
+func (s *SortedSet[T]) Values() []T {
+	out := make([]T, 0, s.sl.length)
+	for n := s.sl.first(); n != nil; n = n.links[0].next {
+		out = append(out, n.key)
+	}
+	return out
+}
+
+// Clone 返回 set 的独立副本,排序规则相同。
+func (s *SortedSet[T]) Clone() *SortedSet[T] {
+	return NewSortedSet(s.sl.less, s.Values()...)
+}
+
+// elem 取出节点的 key;nil 节点返回 (zero, false)。
+func elem[T any](n *skipNode[T, struct{}]) (T, bool) {
+	if n == nil {
+		var zero T
+		return zero, false
+	}
+	return n.key, true
+}
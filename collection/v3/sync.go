@@ -0,0 +1,358 @@
+package collection
+
+import (
+	"cmp"
+	"iter"
+	"sync"
+)
+
+// 本文件提供 [Stack]、[Queue]、[Heap]、[Set] 的并发安全版本。普通容器不是 goroutine 安全的;
+// Sync 版本用一把锁包住对应的容器,方法集与之相同,省去每个共享用法各自手写 mutex。
+//
+// 单个方法是原子的,但"先 Peek 再 Pop"这类复合操作不是:需要原子地执行多步时,用各类型的
+// Do 方法在锁内操作底层容器。All 遍历的是调用时刻的快照,遍历期间不持锁,因此循环体内
+// 可以继续调用同一容器的方法。
+
+// SyncStack 是并发安全的 [Stack]。零值不可用,请使用 [NewSyncStack]。
+type SyncStack[T any] struct {
+	mu sync.Mutex
+	s  *Stack[T]
+}
+
+// NewSyncStack 创建一个空的并发安全 stack。
+func NewSyncStack[T any]() *SyncStack[T] {
+	return &SyncStack[T]{s: NewStack[T]()}
+}
+
+// Push 将 v 添加到 stack 的顶部。
+func (s *SyncStack[T]) Push(v T) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.s.Push(v)
+}
+
+// Pop 移除并返回顶部元素。当 stack 为空时返回 (zero, false)。
+func (s *SyncStack[T]) Pop() (T, bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.s.Pop()
+}
+
+// Peek 返回顶部元素但不移除它。当 stack 为空时返回 (zero, false)。
+func (s *SyncStack[T]) Peek() (T, bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.s.Peek()
+}
+
+// Len 返回元素数量。
+func (s *SyncStack[T]) Len() int {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.s.Len()
+}
+
+// IsEmpty 报告 stack 是否没有元素。
+func (s *SyncStack[T]) IsEmpty() bool { return s.Len() == 0 }
+
+// Clear 移除所有元素。
+func (s *SyncStack[T]) Clear() {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.s.Clear()
+}
+
+// Values 返回按从底部到顶部顺序排列的元素副本。
+func (s *SyncStack[T]) Values() []T {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.s.Values()
+}
+
+// Clone 返回 stack 的独立副本。
+func (s *SyncStack[T]) Clone() *SyncStack[T] {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return &SyncStack[T]{s: s.s.Clone()}
+}
+
+// All 返回一个遍历调用时刻快照的 [iter.Seq],顺序从底部到顶部。
+func (s *SyncStack[T]) All() iter.Seq[T] {
+	return snapshot(s.Values)
+}
+
+// Do 在持锁期间以底层 stack 调用 fn,使多步操作原子地执行。fn 不得保留该指针,也不得
+// 调用 s 的其他方法(会死锁)。
+func (s *SyncStack[T]) Do(fn func(*Stack[T])) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	fn(s.s)
+}
+
+// SyncQueue 是并发安全的 [Queue]。它从不阻塞:空 queue 上的 Dequeue 立即返回
+// (zero, false)。需要在 goroutine 之间阻塞地传递任务时,请使用 [BlockingQueue]。
+type SyncQueue[T any] struct {
+	mu sync.Mutex
+	q  *Queue[T]
+}
+
+// NewSyncQueue 创建一个空的并发安全 queue。
+func NewSyncQueue[T any]() *SyncQueue[T] {
+	return &SyncQueue[T]{q: NewQueue[T]()}
+}
+
+// Enqueue 将 v 添加到 queue 的后端。
+func (q *SyncQueue[T]) Enqueue(v T) {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	q.q.Enqueue(v)
+}
+
+// Dequeue 移除并返回前端元素。当 queue 为空时返回 (zero, false)。
+func (q *SyncQueue[T]) Dequeue() (T, bool) {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	return q.q.Dequeue()
+}
+
+// Peek 返回前端元素但不移除它。当 queue 为空时返回 (zero, false)。
+func (q *SyncQueue[T]) Peek() (T, bool) {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	return q.q.Peek()
+}
+
+// Len 返回元素数量。
+func (q *SyncQueue[T]) Len() int {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	return q.q.Len()
+}
+
+// IsEmpty 报告 queue 是否没有元素。
+func (q *SyncQueue[T]) IsEmpty() bool { return q.Len() == 0 }
+
+// Clear 移除所有元素。
+func (q *SyncQueue[T]) Clear() {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	q.q.Clear()
+}
+
+// Values 返回按从前到后顺序排列的元素副本。
+func (q *SyncQueue[T]) Values() []T {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	return q.q.Values()
+}
+
+// Clone 返回 queue 的独立副本。
+func (q *SyncQueue[T]) Clone() *SyncQueue[T] {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	return &SyncQueue[T]{q: q.q.Clone()}
+}
+
+// All 返回一个遍历调用时刻快照的 [iter.Seq],顺序从前端到后端。
+func (q *SyncQueue[T]) All() iter.Seq[T] {
+	return snapshot(q.Values)
+}
+
+// Do 在持锁期间以底层 queue 调用 fn,使多步操作原子地执行。fn 不得保留该指针,也不得
+// 调用 q 的其他方法(会死锁)。
+func (q *SyncQueue[T]) Do(fn func(*Queue[T])) {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	fn(q.q)
+}
+
+// SyncHeap 是并发安全的 [Heap]。
+type SyncHeap[T any] struct {
+	mu sync.Mutex
+	h  *Heap[T]
+}
+
+// NewSyncHeap 创建一个按 less 排序的空并发安全 heap。less(a, b) == true 表示 a 的优先级高于 b。
+func NewSyncHeap[T any](less func(a, b T) bool) *SyncHeap[T] {
+	return &SyncHeap[T]{h: NewHeap(less)}
+}
+
+// NewSyncMinHeap 创建一个基于有序类型的空并发安全 min-heap。
+func NewSyncMinHeap[T cmp.Ordered]() *SyncHeap[T] {
+	return &SyncHeap[T]{h: NewMinHeap[T]()}
+}
+
+// NewSyncMaxHeap 创建一个基于有序类型的空并发安全 max-heap。
+func NewSyncMaxHeap[T cmp.Ordered]() *SyncHeap[T] {
+	return &SyncHeap[T]{h: NewMaxHeap[T]()}
+}
+
+// Push 将 v 添加到 heap。
+func (h *SyncHeap[T]) Push(v T) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	h.h.Push(v)
+}
+
+// Pop 移除并返回最高优先级的元素。当 heap 为空时返回 (zero, false)。
+func (h *SyncHeap[T]) Pop() (T, bool) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	return h.h.Pop()
+}
+
+// Peek 返回最高优先级的元素但不移除它。当 heap 为空时返回 (zero, false)。
+func (h *SyncHeap[T]) Peek() (T, bool) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	return h.h.Peek()
+}
+
+// Len 返回元素数量。
+func (h *SyncHeap[T]) Len() int {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	return h.h.Len()
+}
+
+// IsEmpty 报告 heap 是否没有元素。
+func (h *SyncHeap[T]) IsEmpty() bool { return h.Len() == 0 }
+
+// Clear 移除所有元素。
+func (h *SyncHeap[T]) Clear() {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	h.h.Clear()
+}
+
+// Values 返回底层 heap slice 的副本,顺序是 heap 的内部布局。
+func (h *SyncHeap[T]) Values() []T {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	return h.h.Values()
+}
+
+// Clone 返回 heap 的独立副本,共享相同的排序方式。
+func (h *SyncHeap[T]) Clone() *SyncHeap[T] {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	return &SyncHeap[T]{h: h.h.Clone()}
+}
+
+// All 返回一个遍历调用时刻快照的 [iter.Seq],顺序是 heap 的内部布局。
+func (h *SyncHeap[T]) All() iter.Seq[T] {
+	return snapshot(h.Values)
+}
+
+// Do 在持锁期间以底层 heap 调用 fn,使多步操作原子地执行。fn 不得保留该指针,也不得
+// 调用 h 的其他方法(会死锁)。
+func (h *SyncHeap[T]) Do(fn func(*Heap[T])) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	fn(h.h)
+}
+
+// SyncSet 是并发安全的 [Set],读操作共享一把 RWMutex 的读锁。集合代数请先用
+// [SyncSet.Snapshot] 取得普通 Set 再链式计算。
+type SyncSet[T comparable] struct {
+	mu sync.RWMutex
+	s  Set[T]
+}
+
+// NewSyncSet 创建一个包含 values 的并发安全 set。
+func NewSyncSet[T comparable](values ...T) *SyncSet[T] {
+	return &SyncSet[T]{s: NewSet(values...)}
+}
+
+// Add 将 values 加入 set。
+func (s *SyncSet[T]) Add(values ...T) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.s.Add(values...)
+}
+
+// AddIfAbsent 在 v 不存在时加入它并返回 true;v 已存在时返回 false。检查与加入是原子的,
+// 适合多个 goroutine 之间的去重。
+func (s *SyncSet[T]) AddIfAbsent(v T) bool {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.s.Contains(v) {
+		return false
+	}
+	s.s.Add(v)
+	return true
+}
+
+// Remove 从 set 中移除 v。
+func (s *SyncSet[T]) Remove(v T) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.s.Remove(v)
+}
+
+// Contains 报告 v 是否在 set 中。
+func (s *SyncSet[T]) Contains(v T) bool {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return s.s.Contains(v)
+}
+
+// Len 返回元素数量。
+func (s *SyncSet[T]) Len() int {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return s.s.Len()
+}
+
+// IsEmpty 报告 set 是否没有元素。
+func (s *SyncSet[T]) IsEmpty() bool { return s.Len() == 0 }
+
+// Clear 移除所有元素。
+func (s *SyncSet[T]) Clear() {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.s.Clear()
+}
+
+// Values 返回元素的副本,顺序不确定。
+func (s *SyncSet[T]) Values() []T {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return s.s.Values()
+}
+
+// Snapshot 返回当前元素的普通 [Set] 副本,可不加锁地进行集合代数。
+func (s *SyncSet[T]) Snapshot() Set[T] {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return s.s.Clone()
+}
+
+// Clone 返回 set 的独立副本。
+func (s *SyncSet[T]) Clone() *SyncSet[T] {
+	return &SyncSet[T]{s: s.Snapshot()}
+}
+
+// All 返回一个遍历调用时刻快照的 [iter.Seq]。顺序不确定。
+func (s *SyncSet[T]) All() iter.Seq[T] {
+	return snapshot(s.Values)
+}
+
+// Do 在持写锁期间以底层 set 调用 fn,使多步操作原子地执行。fn 不得保留该 Set,也不得
+// 调用 s 的其他方法(会死锁)。
+func (s *SyncSet[T]) Do(fn func(Set[T])) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	fn(s.s)
+}
+
+// snapshot 返回一个在开始遍历时调用 values 取快照的 [iter.Seq]。
+func snapshot[T any](values func() []T) iter.Seq[T] {
+	return func(yield func(T) bool) {
+		for _, v := range values() {
+			if !yield(v) {
+				return
+			}
+		}
+	}
+}
@@ -10,9 +10,11 @@
 //   - Fluent Chain:ChainOf(s).Map(...).Filter(...).TopK(k, key).Collect()
 //     每一步都物化出一个 slice——通常比 Go 中的 closure chain 更快,
 //     也更容易调试。
-//   - Lazy Seq:callback 风格的迭代器(在理念上与未来的 iter.Seq 兼容),
+//   - Lazy Seq:callback 风格的迭代器,底层类型与 iter.Seq 相同,
 //     用于在大型或生成的输入上做 short-circuit 读取(Any/All/Find),
-//     无需分配物化的 slice。
+//     无需分配物化的 slice。Take/Skip/TakeWhile/ZipSeq/FlatMapSeq/ChunkSeq
+//     等组合子正确传播提前终止;FromIter/FromIter2/Iter/ToIter2 与标准库及
+//     collection 的 All() 迭代器互转。
 //
 // 排序模型:
 //
@@ -26,6 +28,10 @@
 //	By[T] 是 cmp 风格的 comparator func(a, b T) int,供 *By 变体使用,
 //	当你需要多字段或非 key 排序时使用。当单个有序字段即可表达排序时优先用 Key。
 //
+// 并行变体 ParMap/ParFilter/ParForEach/ParMapErr 以至多 limit 个 goroutine 处理
+// slice,保持输入顺序,遵循 ctx 取消并在首个错误处短路;回调 panic 以 *PanicError
+// 返回,不会让进程崩溃。
+//
 // 除非名字以 InPlace 结尾,所有操作都是 pure 的。in-place 变体会复用输入
 // slice 的 backing array,并返回其(可能缩短的)视图;它们是 zero-allocation
 // 的热路径。
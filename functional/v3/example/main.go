@@ -3,14 +3,19 @@
 // Demonstrates the dual-track API:
 //   - Standalone functions (Map / Filter / Reduce / TopK / ...)
 //   - Fluent Chain (ChainOf(s).Map(...).Filter(...).TopK(k, key).Collect())
-//   - Lazy Seq with short-circuit reads (Any / All / Find)
+//   - Lazy Seq with short-circuit reads (Any / All / Find) and combinators
+//     (Take / Zip / Chunk / FlatMap / ...) interoperating with iter.Seq
 //   - In-place / zero-allocation variants (MapInPlace / FilterInPlace / ...)
 //   - key-extractor-based aggregation (TopK / MinByKey / MaxByKey)
 //   - OrderedSet for O(1) membership after a one-time build
+//   - Parallel ParMap / ParMapErr with bounded concurrency and panic safety
 package main
 
 import (
+	"context"
+	"errors"
 	"fmt"
+	"slices"
 
 	fn "github.com/tenz-io/gokit/functional/v3"
 )
@@ -95,6 +100,22 @@ func main() {
 	).Collect()
 	fmt.Println("seq: filtered+doubled:  ", scoreDoubles)
 
+	// Lazy combinators stop pulling upstream as soon as the consumer is done,
+	// so infinite generators are fine.
+	squares := fn.MapSeq(fn.Iterate(1, func(i int) int { return i + 1 }),
+		func(i int) int { return i * i })
+	fmt.Println("seq: first 5 squares:   ", squares.Take(5).Collect())
+	fmt.Println("seq: squares < 50:      ", squares.TakeWhile(func(i int) bool { return i < 50 }).Collect())
+	fmt.Println("seq: chunked by 2:      ", fn.ChunkSeq(squares.Skip(2).Take(5), 2).Collect())
+	ranked := fn.ZipSeq(fn.Iterate(1, func(i int) int { return i + 1 }), fn.FromIter(slices.Values(namesOf(users))))
+	for p := range ranked.Take(2) {
+		fmt.Printf("seq: zip rank %d:         %s\n", p.A, p.B)
+	}
+	distinctTags := fn.DeduplicateSeq(fn.FlatMapSeq(fn.SeqOf(users), func(u User) fn.Seq[string] {
+		return fn.SeqOf(u.Tags)
+	}))
+	fmt.Println("seq: distinct tags:     ", slices.Sorted(distinctTags.Iter()))
+
 	// --- 5. In-place / zero-allocation variants ---
 	scores := []int{10, 20, 30, 40}
 	fn.MapInPlace(scores, func(i int) int { return i * 10 })
@@ -147,6 +168,22 @@ func main() {
 	fmt.Println("default empty -> fallback:", def)
 	gated := fn.When(true, 5, func(i int) int { return i * 2 })
 	fmt.Println("when(true, 5, *2):      ", gated)
+
+	// --- 10. Parallel variants (bounded, ordered, first-error short-circuit) ---
+	ctx := context.Background()
+	lengths, _ := fn.ParMap(ctx, users, 2, func(u User) int { return len(u.Name) })
+	fmt.Println("par: name lengths:      ", lengths)
+	// limit 1 dispatches in order, so the first inactive user stops the run.
+	_, err := fn.ParMapErr(ctx, users, 1, func(_ context.Context, u User) (string, error) {
+		if !u.Active {
+			return "", fmt.Errorf("user %d is inactive", u.ID)
+		}
+		return u.Name, nil
+	})
+	fmt.Println("par: first error:       ", err)
+	_, err = fn.ParMap(ctx, []int{1, 0}, 0, func(i int) int { return 10 / i })
+	var pe *fn.PanicError
+	fmt.Println("par: panic recovered:   ", errors.As(err, &pe), err)
 }
 
 // namesOf is a tiny local helper to render a user slice compactly.
@@ -1,8 +1,15 @@
 package fn
 
 import (
+	"context"
+	"errors"
+	"maps"
 	"reflect"
+	"slices"
+	"sync"
+	"sync/atomic"
 	"testing"
+	"time"
 )
 
 // ---- helpers ----
@@ -703,6 +710,328 @@ func TestSeqFilterMap(t *testing.T) {
 	}
 }
 
+// counted returns a Seq over s together with a pointer to the number of
+// elements it has pushed, so tests can assert how far upstream was advanced.
+func counted[T any](s []T) (Seq[T], *int) {
+	n := new(int)
+	return func(yield func(T) bool) {
+		for _, v := range s {
+			*n++
+			if !yield(v) {
+				return
+			}
+		}
+	}, n
+}
+
+func naturals() Seq[int] { return Iterate(0, func(i int) int { return i + 1 }) }
+
+func TestSeqTakeSkip(t *testing.T) {
+	eq(t, "take", naturals().Take(3).Collect(), []int{0, 1, 2})
+	eq(t, "take 0", naturals().Take(0).Collect(), []int{})
+	eq(t, "take more than len", SeqOf([]int{1, 2}).Take(5).Collect(), []int{1, 2})
+	eq(t, "skip", SeqOf([]int{1, 2, 3, 4}).Skip(2).Collect(), []int{3, 4})
+	eq(t, "skip all", SeqOf([]int{1, 2}).Skip(5).Collect(), []int{})
+	eq(t, "skip+take", naturals().Skip(10).Take(2).Collect(), []int{10, 11})
+
+	q, pulled := counted([]int{1, 2, 3, 4, 5})
+	q.Take(2).Collect()
+	if *pulled != 2 {
+		t.Errorf("Take(2) pulled %d, want 2", *pulled)
+	}
+	*pulled = 0
+	q.Take(0).Collect()
+	if *pulled != 0 {
+		t.Errorf("Take(0) pulled %d, want 0", *pulled)
+	}
+}
+
+func TestSeqTakeWhileSkipWhile(t *testing.T) {
+	lt := func(n int) func(int) bool { return func(i int) bool { return i < n } }
+	eq(t, "takewhile", naturals().TakeWhile(lt(4)).Collect(), []int{0, 1, 2, 3})
+	eq(t, "skipwhile", SeqOf([]int{1, 2, 5, 1}).SkipWhile(lt(3)).Collect(), []int{5, 1})
+
+	q, pulled := counted([]int{1, 2, 3, 9, 4, 5})
+	q.TakeWhile(lt(5)).Collect()
+	if *pulled != 4 {
+		t.Errorf("TakeWhile pulled %d, want 4 (stops at first failure)", *pulled)
+	}
+
+	calls := 0
+	SeqOf([]int{1, 5, 1, 1}).SkipWhile(func(i int) bool { calls++; return i < 3 }).Collect()
+	if calls != 2 {
+		t.Errorf("SkipWhile pred calls = %d, want 2", calls)
+	}
+}
+
+func TestSeqChunkEnumerate(t *testing.T) {
+	eq(t, "chunk", ChunkSeq(SeqOf([]int{1, 2, 3, 4, 5}), 2).Collect(), [][]int{{1, 2}, {3, 4}, {5}})
+	eq(t, "chunk exact", ChunkSeq(SeqOf([]int{1, 2, 3, 4}), 2).Collect(), [][]int{{1, 2}, {3, 4}})
+	eq(t, "chunk empty", ChunkSeq(SeqOf([]int{}), 2).Collect(), [][]int{})
+	eq(t, "chunk infinite", ChunkSeq(naturals(), 3).Take(2).Collect(), [][]int{{0, 1, 2}, {3, 4, 5}})
+
+	chunks := ChunkSeq(SeqOf([]int{1, 2, 3, 4}), 2).Collect()
+	chunks[0][0] = 99
+	if chunks[1][0] != 3 {
+		t.Error("chunks must not share backing arrays")
+	}
+
+	defer func() {
+		if recover() == nil {
+			t.Error("ChunkSeq(0) should panic")
+		}
+	}()
+	ChunkSeq(naturals(), 0)
+}
+
+func TestSeqEnumerate(t *testing.T) {
+	got := EnumerateSeq(SeqOf([]string{"a", "b", "c"})).Skip(1).Collect()
+	eq(t, "enumerate", got, []Pair[int, string]{{1, "b"}, {2, "c"}})
+}
+
+func TestSeqConcatFlatMapZip(t *testing.T) {
+	eq(t, "concat", SeqOf([]int{1}).Concat(SeqOf([]int{}), SeqOf([]int{2, 3})).Collect(), []int{1, 2, 3})
+	eq(t, "concat none", ConcatSeq[int]().Collect(), []int{})
+
+	second, pulled := counted([]int{10, 11})
+	SeqOf([]int{1, 2}).Concat(second).Take(2).Collect()
+	if *pulled != 0 {
+		t.Errorf("Concat advanced the second Seq %d times after Take was satisfied", *pulled)
+	}
+
+	rep := func(i int) Seq[int] { return SeqOf(slices.Repeat([]int{i}, i)) }
+	eq(t, "flatmap", FlatMapSeq(SeqOf([]int{1, 2, 3}), rep).Collect(), []int{1, 2, 2, 3, 3, 3})
+
+	outer, outerPulled := counted([]int{2, 3, 4})
+	eq(t, "flatmap take", FlatMapSeq(outer, rep).Take(3).Collect(), []int{2, 2, 3})
+	if *outerPulled != 2 {
+		t.Errorf("FlatMap outer pulled %d, want 2", *outerPulled)
+	}
+
+	zipped := ZipSeq(naturals(), SeqOf([]string{"a", "b"})).Collect()
+	eq(t, "zip shorter b", zipped, []Pair[int, string]{{0, "a"}, {1, "b"}})
+	eq(t, "zip shorter a", ZipSeq(SeqOf([]int{7}), naturals()).Collect(), []Pair[int, int]{{7, 0}})
+	eq(t, "zip take", ZipSeq(naturals(), naturals()).Take(1).Collect(), []Pair[int, int]{{0, 0}})
+}
+
+func TestSeqDeduplicate(t *testing.T) {
+	eq(t, "dedupe", DeduplicateSeq(SeqOf([]int{3, 1, 3, 2, 1})).Collect(), []int{3, 1, 2})
+	byLen := DeduplicateBySeq(SeqOf([]string{"a", "bb", "c", "dd", "eee"}), func(s string) int { return len(s) })
+	eq(t, "dedupe by", byLen.Collect(), []string{"a", "bb", "eee"})
+	// Distinct over an infinite Seq is fine as long as the consumer stops.
+	mod := MapSeq(naturals(), func(i int) int { return i % 3 })
+	eq(t, "dedupe infinite", DeduplicateSeq(mod).Take(3).Collect(), []int{0, 1, 2})
+}
+
+func TestSeqIterInterop(t *testing.T) {
+	// slices/maps iterators plug straight in, and Seq ranges directly.
+	q := FromIter(slices.Values([]int{3, 1, 2}))
+	eq(t, "from iter", slices.Sorted(q.Iter()), []int{1, 2, 3})
+
+	sum := 0
+	for v := range naturals().Take(4) {
+		sum += v
+	}
+	if sum != 6 {
+		t.Errorf("range sum = %d, want 6", sum)
+	}
+
+	m := map[string]int{"a": 1, "b": 2, "c": 3}
+	big := FromIter2(maps.All(m)).Filter(func(p Pair[string, int]) bool { return p.B > 1 })
+	if got := maps.Collect(ToIter2(big)); !reflect.DeepEqual(got, map[string]int{"b": 2, "c": 3}) {
+		t.Errorf("seq2 round trip = %v", got)
+	}
+
+	idx := maps.Collect(ToIter2(EnumerateSeq(SeqOf([]string{"x", "y"}))))
+	if !reflect.DeepEqual(idx, map[int]string{0: "x", 1: "y"}) {
+		t.Errorf("enumerate seq2 = %v", idx)
+	}
+}
+
+// TestSeqEarlyTermination breaks out of a range loop over every combinator.
+// The runtime panics if an iterator calls yield after it returned false, so
+// this guards the short-circuit contract of each operation.
+func TestSeqEarlyTermination(t *testing.T) {
+	ints := map[string]Seq[int]{
+		"take":      naturals().Take(10),
+		"skip":      naturals().Skip(1),
+		"takewhile": naturals().TakeWhile(func(int) bool { return true }),
+		"skipwhile": naturals().SkipWhile(func(i int) bool { return i < 2 }),
+		"concat":    SeqOf([]int{1}).Concat(naturals(), naturals()),
+		"flatmap":   FlatMapSeq(naturals(), func(i int) Seq[int] { return SeqOf([]int{i, i}) }),
+		"dedupe":    DeduplicateSeq(naturals()),
+		"filter":    naturals().Filter(func(i int) bool { return i%2 == 0 }),
+		"chunk":     MapSeq(ChunkSeq(naturals(), 2), func(c []int) int { return c[0] }),
+		"enumerate": MapSeq(EnumerateSeq(naturals()), func(p Pair[int, int]) int { return p.A }),
+		"zip":       MapSeq(ZipSeq(naturals(), naturals()), func(p Pair[int, int]) int { return p.B }),
+		"from iter2": MapSeq(FromIter2(ToIter2(EnumerateSeq(naturals()))),
+			func(p Pair[int, int]) int { return p.B }),
+	}
+	for name, q := range ints {
+		n := 0
+		for range q {
+			if n++; n == 3 {
+				break
+			}
+		}
+		if n != 3 {
+			t.Errorf("%s: got %d elements before break, want 3", name, n)
+		}
+	}
+
+	// Chunk must not flush its partial buffer after the consumer stopped.
+	var got [][]int
+	for c := range ChunkSeq(SeqOf([]int{1, 2, 3}), 2) {
+		got = append(got, c)
+		break
+	}
+	eq(t, "chunk stop", got, [][]int{{1, 2}})
+}
+
+// ---- ParMap / ParMapErr / ParFilter / ParForEach ----
+
+func TestParMapOrdered(t *testing.T) {
+	in := make([]int, 100)
+	for i := range in {
+		in[i] = i
+	}
+	got, err := ParMap(context.Background(), in, 8, func(i int) int {
+		// Later elements finish first, so order must come from the index.
+		time.Sleep(time.Duration(100-i) * 10 * time.Microsecond)
+		return i * i
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	eq(t, "parmap", got, Map(in, func(i int) int { return i * i }))
+
+	evens, err := ParFilter(context.Background(), in, 0, func(i int) bool { return i%2 == 0 })
+	if err != nil {
+		t.Fatal(err)
+	}
+	eq(t, "parfilter", evens, Filter(in, func(i int) bool { return i%2 == 0 }))
+
+	empty, err := ParMap(context.Background(), []int{}, 4, func(i int) int { return i })
+	if err != nil || len(empty) != 0 {
+		t.Errorf("empty = (%v, %v)", empty, err)
+	}
+}
+
+func TestParLimit(t *testing.T) {
+	var cur, peak atomic.Int32
+	err := ParForEach(context.Background(), make([]int, 50), 3, func(context.Context, int) error {
+		n := cur.Add(1)
+		for {
+			p := peak.Load()
+			if n <= p || peak.CompareAndSwap(p, n) {
+				break
+			}
+		}
+		time.Sleep(time.Millisecond)
+		cur.Add(-1)
+		return nil
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if p := peak.Load(); p > 3 || p < 2 {
+		t.Errorf("peak concurrency = %d, want 2..3", p)
+	}
+}
+
+func TestParFirstErrorShortCircuit(t *testing.T) {
+	boom := errors.New("boom")
+	var calls atomic.Int32
+	// limit 1 dispatches strictly in order: nothing after the failure runs.
+	got, err := ParMapErr(context.Background(), []int{1, 2, 3, 4, 5}, 1,
+		func(_ context.Context, i int) (int, error) {
+			calls.Add(1)
+			if i == 2 {
+				return 0, boom
+			}
+			return i, nil
+		})
+	if !errors.Is(err, boom) || got != nil {
+		t.Errorf("ParMapErr = (%v, %v), want (nil, boom)", got, err)
+	}
+	if calls.Load() != 2 {
+		t.Errorf("calls = %d, want 2", calls.Load())
+	}
+
+	// In-flight siblings see their ctx cancelled by the first failure.
+	var cancelled atomic.Int32
+	var started sync.WaitGroup
+	started.Add(3)
+	err = ParForEach(context.Background(), []int{0, 1, 2, 3}, 4, func(ctx context.Context, i int) error {
+		if i == 0 {
+			started.Wait()
+			return boom
+		}
+		started.Done()
+		select {
+		case <-ctx.Done():
+			cancelled.Add(1)
+			return ctx.Err()
+		case <-time.After(time.Second):
+			return nil
+		}
+	})
+	if !errors.Is(err, boom) {
+		t.Errorf("ParForEach err = %v, want boom", err)
+	}
+	if cancelled.Load() != 3 {
+		t.Errorf("cancelled siblings = %d, want 3", cancelled.Load())
+	}
+}
+
+func TestParContextCancel(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	if _, err := ParMap(ctx, []int{1}, 1, func(i int) int { return i }); !errors.Is(err, context.Canceled) {
+		t.Errorf("pre-cancelled err = %v", err)
+	}
+
+	ctx, cancel = context.WithCancel(context.Background())
+	defer cancel()
+	var calls atomic.Int32
+	got, err := ParFilter(ctx, make([]int, 100), 2, func(int) bool {
+		if calls.Add(1) == 4 {
+			cancel()
+		}
+		return true
+	})
+	if !errors.Is(err, context.Canceled) || got != nil {
+		t.Errorf("ParFilter = (%v, %v), want (nil, Canceled)", got, err)
+	}
+	if n := calls.Load(); n >= 100 {
+		t.Errorf("calls = %d, dispatch should stop after cancel", n)
+	}
+}
+
+func TestParPanic(t *testing.T) {
+	cause := errors.New("cause")
+	_, err := ParMap(context.Background(), []int{1, 2, 3}, 2, func(i int) int {
+		if i == 2 {
+			panic(cause)
+		}
+		return i
+	})
+	var pe *PanicError
+	if !errors.As(err, &pe) {
+		t.Fatalf("err = %v, want *PanicError", err)
+	}
+	if !errors.Is(err, cause) || pe.Value() != cause || len(pe.Stack()) == 0 {
+		t.Errorf("PanicError = %v (value %v)", pe, pe.Value())
+	}
+
+	err = ParForEach(context.Background(), []string{"x"}, 1, func(context.Context, string) error {
+		panic("bad")
+	})
+	if !errors.As(err, &pe) || err.Error() != "fn: panic: bad" || pe.Unwrap() != nil {
+		t.Errorf("string panic err = %v", err)
+	}
+}
+
 // ---- Benchmarks (vs v2 baseline feel) ----
 
 func BenchmarkMap(b *testing.B) {
@@ -767,3 +1096,26 @@ func BenchmarkChain(b *testing.B) {
 			Collect()
 	}
 }
+
+func BenchmarkParMap(b *testing.B) {
+	list := make([]int, 1000)
+	for i := range list {
+		list[i] = i
+	}
+	work := func(i int) int {
+		for range 1000 {
+			i = i*31 + 7
+		}
+		return i
+	}
+	b.Run("Map", func(b *testing.B) {
+		for i := 0; i < b.N; i++ {
+			_ = Map(list, work)
+		}
+	})
+	b.Run("ParMap", func(b *testing.B) {
+		for i := 0; i < b.N; i++ {
+			_, _ = ParMap(context.Background(), list, 0, work)
+		}
+	})
+}
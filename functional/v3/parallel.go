@@ -0,0 +1,159 @@
+package fn
+
+import (
+	"context"
+	"fmt"
+	"runtime"
+	"sync"
+	"sync/atomic"
+)
+
+// PanicError 在 Par* 的回调 panic 时返回。它携带被 recover 的值以及捕获的调用栈,
+// 调用方可通过 [errors.As] 进行检视或记录日志。语义与 async/v3 的同名类型一致,
+// 以免本包引入依赖。
+type PanicError struct {
+	value any
+	stack []byte
+}
+
+// Value 返回传给 panic 的值。
+func (p *PanicError) Value() any { return p.value }
+
+// Stack 返回在 panic 发生处捕获的 goroutine 调用栈。
+func (p *PanicError) Stack() []byte { return p.stack }
+
+// Error 实现 error 接口。
+func (p *PanicError) Error() string {
+	return fmt.Sprintf("fn: panic: %v", p.value)
+}
+
+// Unwrap 使 errors.Is/As 能够穿透到包装了 error 的 panic 内部。
+func (p *PanicError) Unwrap() error {
+	if err, ok := p.value.(error); ok {
+		return err
+	}
+	return nil
+}
+
+// ParMap 以至多 limit 个 goroutine 并发计算 f(s[i]),结果按输入顺序返回。
+// limit <= 0 时取 runtime.GOMAXPROCS(0)。
+//
+// 适用于 CPU 密集的变换;f 无法失败,因此只有 ctx 结束(返回 ctx.Err())或 f panic
+// (返回 [*PanicError])时才返回错误,此时结果为 nil,尚未开始的元素不再处理。
+func ParMap[T, U any](ctx context.Context, s []T, limit int, f func(T) U) ([]U, error) {
+	return ParMapErr(ctx, s, limit, func(_ context.Context, v T) (U, error) {
+		return f(v), nil
+	})
+}
+
+// ParMapErr 以至多 limit 个 goroutine 并发计算 f(ctx, s[i]),结果按输入顺序返回。
+// limit <= 0 时取 runtime.GOMAXPROCS(0)。
+//
+// 首个错误(或 panic,以 [*PanicError] 形式)会取消传给其余 f 的 ctx,并停止派发
+// 尚未开始的元素;ParMapErr 在所有已开始的调用返回后,返回该错误与 nil 结果。
+// “首个”指最先被观察到的错误,而不一定是下标最小的元素的错误。
+func ParMapErr[T, U any](ctx context.Context, s []T, limit int, f func(context.Context, T) (U, error)) ([]U, error) {
+	out := make([]U, len(s))
+	err := parallel(ctx, len(s), limit, func(ctx context.Context, i int) error {
+		v, err := f(ctx, s[i])
+		out[i] = v
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+// ParFilter 以至多 limit 个 goroutine 并发求值 pred,返回满足 pred 的元素,
+// 保持输入顺序。limit 与错误语义同 [ParMap]。
+func ParFilter[T any](ctx context.Context, s []T, limit int, pred func(T) bool) ([]T, error) {
+	keep, err := ParMap(ctx, s, limit, pred)
+	if err != nil {
+		return nil, err
+	}
+	out := make([]T, 0, len(s))
+	for i, v := range s {
+		if keep[i] {
+			out = append(out, v)
+		}
+	}
+	return out, nil
+}
+
+// ParForEach 以至多 limit 个 goroutine 并发调用 fn(ctx, s[i]),用于 fan-out I/O。
+// limit 与首个错误短路的语义同 [ParMapErr];全部成功时返回 nil。
+func ParForEach[T any](ctx context.Context, s []T, limit int, fn func(context.Context, T) error) error {
+	return parallel(ctx, len(s), limit, func(ctx context.Context, i int) error {
+		return fn(ctx, s[i])
+	})
+}
+
+// parallel 以至多 limit 个 worker 对 [0, n) 的每个下标调用 task。worker 按下标递增
+// 领取任务,因此 limit == 1 时严格顺序执行。task 的 panic 被转换为 [*PanicError];
+// 首个错误取消派生的 ctx,worker 随之停止领取新下标。未发生错误但父 ctx 结束时返回
+// ctx.Err()。
+func parallel(ctx context.Context, n, limit int, task func(context.Context, int) error) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+	if n == 0 {
+		return nil
+	}
+	if limit <= 0 {
+		limit = runtime.GOMAXPROCS(0)
+	}
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	var (
+		next     atomic.Int64
+		once     sync.Once
+		firstErr error
+		wg       sync.WaitGroup
+	)
+	fail := func(err error) {
+		once.Do(func() {
+			firstErr = err
+			cancel()
+		})
+	}
+	for range min(limit, n) {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for ctx.Err() == nil {
+				i := int(next.Add(1) - 1)
+				if i >= n {
+					return
+				}
+				if err := recoverIndex(ctx, i, task); err != nil {
+					fail(err)
+					return
+				}
+			}
+		}()
+	}
+	wg.Wait()
+
+	if firstErr != nil {
+		return firstErr
+	}
+	// 父 ctx 在派发途中结束:部分元素未被处理。
+	if int(next.Load()) < n {
+		return ctx.Err()
+	}
+	return nil
+}
+
+// recoverIndex 调用 task(ctx, i),把 panic 以 [*PanicError] 形式返回。调用栈在 panic
+// 发生处捕获一次。
+func recoverIndex(ctx context.Context, i int, task func(context.Context, int) error) (err error) {
+	defer func() {
+		if rec := recover(); rec != nil {
+			buf := make([]byte, 4096)
+			err = &PanicError{value: rec, stack: buf[:runtime.Stack(buf, false)]}
+		}
+	}()
+	return task(ctx, i)
+}
@@ -1,5 +1,7 @@
 package fn
 
+import "iter"
+
 // Seq 是遍历 T 序列的 lazy、callback 风格迭代器。它是无 pull 的 "push" 模型:
 // 消费者传入一个 yield callback,生产者把每个元素 push 给它。从 yield
 // 返回 false 可提前停止迭代(short-circuit)。
@@ -8,7 +10,12 @@ package fn
 // 而 Seq 将操作融合为单个 callback chain,并可在得到结果时立即停止
 // (Any/All/Find)。对于构建结果 slice,Chain/Collect 通常更清晰。
 //
-// 用 SeqOf(从 slice)构造。Filter 和 Map 以 lazy 方式组合。
+// 用 SeqOf(从 slice)、Iterate(无限生成)或 FromIter/FromIter2(标准库与 collection 的
+// All() 迭代器)构造。Seq 的底层类型与 [iter.Seq] 相同,二者可以直接相互赋值,也可以直接用于
+// for range。
+//
+// 所有组合子都是 lazy 的,并正确传播提前终止:下游 yield 返回 false 后,上游不会再被推进,
+// yield 也不会再被调用——因此对无限 Seq 使用 Take/TakeWhile/Find 是安全的。
 type Seq[T any] func(yield func(T) bool)
 
 // SeqOf 创建一个按顺序 yield s 元素的 Seq。slice 是被引用(非复制);
@@ -131,3 +138,215 @@ func (q Seq[T]) Collect() []T {
 	})
 	return out
 }
+
+// Iterate 返回无限 Seq:seed, next(seed), next(next(seed)), ……。需配合 Take/TakeWhile/
+// Find 等提前终止的操作使用。
+func Iterate[T any](seed T, next func(T) T) Seq[T] {
+	return func(yield func(T) bool) {
+		for v := seed; yield(v); v = next(v) {
+		}
+	}
+}
+
+// FromIter 把标准库的 [iter.Seq](例如 maps.Keys、slices.Values 或 collection 的 All())
+// 转换为 Seq。二者底层类型相同,转换是 zero-cost 的。
+func FromIter[T any](it iter.Seq[T]) Seq[T] {
+	return Seq[T](it)
+}
+
+// FromIter2 把 [iter.Seq2](例如 maps.All 或 collection 的 All())转换为 Pair 的 Seq,
+// 以便继续使用 Filter/Take 等组合子。ToIter2 是它的逆操作。
+func FromIter2[K, V any](it iter.Seq2[K, V]) Seq[Pair[K, V]] {
+	return func(yield func(Pair[K, V]) bool) {
+		for k, v := range it {
+			if !yield(Pair[K, V]{A: k, B: v}) {
+				return
+			}
+		}
+	}
+}
+
+// Iter 以 [iter.Seq] 返回 q,用于需要标准库类型的 API(slices.Collect、maps.Collect……)。
+func (q Seq[T]) Iter() iter.Seq[T] {
+	return iter.Seq[T](q)
+}
+
+// ToIter2 把 Pair 的 Seq 展开为 [iter.Seq2],例如 maps.Collect(fn.ToIter2(fn.EnumerateSeq(q)))。
+func ToIter2[K, V any](q Seq[Pair[K, V]]) iter.Seq2[K, V] {
+	return func(yield func(K, V) bool) {
+		q(func(p Pair[K, V]) bool {
+			return yield(p.A, p.B)
+		})
+	}
+}
+
+// Take 返回只 yield q 前 n 个元素的 Seq。取满 n 个后立即停止,不会再推进 q,
+// 因此可用于无限 Seq。n <= 0 时不消费 q。
+func (q Seq[T]) Take(n int) Seq[T] {
+	return func(yield func(T) bool) {
+		if n <= 0 {
+			return
+		}
+		i := 0
+		q(func(v T) bool {
+			i++
+			return yield(v) && i < n
+		})
+	}
+}
+
+// Skip 返回跳过 q 前 n 个元素后 yield 其余元素的 Seq。
+func (q Seq[T]) Skip(n int) Seq[T] {
+	return func(yield func(T) bool) {
+		i := 0
+		q(func(v T) bool {
+			if i < n {
+				i++
+				return true
+			}
+			return yield(v)
+		})
+	}
+}
+
+// TakeWhile 返回 yield q 的元素直到 pred 首次不成立的 Seq;首个不满足 pred 的元素
+// 不会被 yield,q 也在此停止。
+func (q Seq[T]) TakeWhile(pred func(T) bool) Seq[T] {
+	return func(yield func(T) bool) {
+		q(func(v T) bool {
+			return pred(v) && yield(v)
+		})
+	}
+}
+
+// SkipWhile 返回跳过 q 开头连续满足 pred 的元素、yield 其余全部元素的 Seq。
+// pred 首次不成立之后不再被调用。
+func (q Seq[T]) SkipWhile(pred func(T) bool) Seq[T] {
+	return func(yield func(T) bool) {
+		skipping := true
+		q(func(v T) bool {
+			if skipping && pred(v) {
+				return true
+			}
+			skipping = false
+			return yield(v)
+		})
+	}
+}
+
+// ChunkSeq 返回把 q 按 size 个一组 yield 的 Seq;最后一组可能不足 size。每组都是新分配的
+// slice,可以安全保留。size <= 0 时 panic。它与 MapSeq 一样是自由函数:方法的返回类型
+// 不能以 receiver 的类型参数构造新的 Seq 实例(Seq[[]T])。
+func ChunkSeq[T any](q Seq[T], size int) Seq[[]T] {
+	if size <= 0 {
+		panic("fn.ChunkSeq: size must be positive")
+	}
+	return func(yield func([]T) bool) {
+		buf := make([]T, 0, size)
+		stopped := false
+		q(func(v T) bool {
+			buf = append(buf, v)
+			if len(buf) < size {
+				return true
+			}
+			if !yield(buf) {
+				stopped = true
+				return false
+			}
+			buf = make([]T, 0, size)
+			return true
+		})
+		if !stopped && len(buf) > 0 {
+			yield(buf)
+		}
+	}
+}
+
+// EnumerateSeq 返回 (index, 元素) 的 Seq,index 从 0 开始。用 ToIter2 可得到
+// iter.Seq2[int, T]。
+func EnumerateSeq[T any](q Seq[T]) Seq[Pair[int, T]] {
+	return func(yield func(Pair[int, T]) bool) {
+		i := 0
+		q(func(v T) bool {
+			p := Pair[int, T]{A: i, B: v}
+			i++
+			return yield(p)
+		})
+	}
+}
+
+// Concat 返回依次 yield q 与 others 中各 Seq 元素的 Seq。提前终止时后续 Seq 不会被推进。
+func (q Seq[T]) Concat(others ...Seq[T]) Seq[T] {
+	return ConcatSeq(append([]Seq[T]{q}, others...)...)
+}
+
+// ConcatSeq 返回依次 yield seqs 中各 Seq 元素的 Seq。
+func ConcatSeq[T any](seqs ...Seq[T]) Seq[T] {
+	return func(yield func(T) bool) {
+		for _, s := range seqs {
+			stopped := false
+			s(func(v T) bool {
+				if !yield(v) {
+					stopped = true
+					return false
+				}
+				return true
+			})
+			if stopped {
+				return
+			}
+		}
+	}
+}
+
+// FlatMapSeq 返回对 q 的每个元素调用 f、再依次 yield f 返回的 Seq 中元素的 Seq。
+// 内层 Seq 的提前终止会同时停止外层 q。
+func FlatMapSeq[T, U any](q Seq[T], f func(T) Seq[U]) Seq[U] {
+	return func(yield func(U) bool) {
+		q(func(v T) bool {
+			ok := true
+			f(v)(func(u U) bool {
+				ok = yield(u)
+				return ok
+			})
+			return ok
+		})
+	}
+}
+
+// ZipSeq 返回把 a 与 b 的元素按位置配对的 Seq,在较短的一方耗尽时停止。
+// b 通过 [iter.Pull] 逐个拉取,迭代结束(包括提前终止)时会释放。
+func ZipSeq[A, B any](a Seq[A], b Seq[B]) Seq[Pair[A, B]] {
+	return func(yield func(Pair[A, B]) bool) {
+		next, stop := iter.Pull(iter.Seq[B](b))
+		defer stop()
+		a(func(va A) bool {
+			vb, ok := next()
+			if !ok {
+				return false
+			}
+			return yield(Pair[A, B]{A: va, B: vb})
+		})
+	}
+}
+
+// DeduplicateSeq 返回只 yield 每个不同元素首次出现的 Seq(distinct),保持原顺序。
+// 已见过的元素保存在内部 set 中,内存随不同元素个数增长。
+func DeduplicateSeq[T comparable](q Seq[T]) Seq[T] {
+	return DeduplicateBySeq(q, func(v T) T { return v })
+}
+
+// DeduplicateBySeq 返回按 keyFn 去重的 Seq:只 yield 每个 key 首次出现的元素。
+func DeduplicateBySeq[T any, K comparable](q Seq[T], keyFn func(T) K) Seq[T] {
+	return func(yield func(T) bool) {
+		seen := make(map[K]struct{})
+		q(func(v T) bool {
+			k := keyFn(v)
+			if _, ok := seen[k]; ok {
+				return true
+			}
+			seen[k] = struct{}{}
+			return yield(v)
+		})
+	}
+}
@@ -8,7 +8,10 @@ import (
 
 // Backoff 计算两次重试之间的等待时长。attempt 从首次重试前的第一次失败
 // 开始按 0,1,2,... 编号。实现无需保持状态:同一 attempt 输入应得到可比的
-// 等待时长(允许抖动产生随机性)。
+// 等待时长(允许抖动产生随机性)。需要跨重试保持状态的策略(如
+// [Decorrelated])另实现 [StatefulBackoff],由 Do 为每次调用创建独立状态。
+// Next 的返回值总被当作等待时长,负值与 0 一样表示立即重试;需要主动停止
+// 重试的策略(如 [Limit])另实现 [Stopper]。
 //
 // 本包的所有内置策略均为可比较字段、可直接用复合字面量构造的值类型,
 // 并通过 [Jitter] 装饰器组合,例如:
@@ -21,6 +24,64 @@ type Backoff interface {
 	Next(attempt int) time.Duration
 }
 
+// StatefulBackoff 是需要跨重试保持状态的 [Backoff]。[Retriever.Do] 在每次
+// 调用开始时调用 Start,取得只属于本次调用的 Backoff 并用它计算该次调用内
+// 的全部等待,因此同一个 StatefulBackoff 可被并发的 Do 安全共享:状态从不
+// 跨调用共享。Start 返回的 Backoff 只在单个 goroutine 内使用,无需加锁。
+type StatefulBackoff interface {
+	Backoff
+	Start() Backoff
+}
+
+// startBackoff 为一次调用准备 b:b 实现 [StatefulBackoff] 时返回其
+// Start(),否则原样返回。包装型策略用它把 Start 传递给被包装者。
+func startBackoff(b Backoff) Backoff {
+	if s, ok := b.(StatefulBackoff); ok {
+		return s.Start()
+	}
+	return b
+}
+
+// Stopper 是可以主动停止重试的 [Backoff]。[Retriever.Do] 对实现了 Stopper
+// 的退避调用 NextOrStop 而不是 Next:ok 为 false 时停止重试,返回用
+// [ErrMaxAttempts] 包裹的最后一次错误。停止信号是显式的 ok,而不是某个
+// 特殊的等待时长,因此普通 Backoff 返回负值(例如减法抖动)不会被误判为
+// 停止。[Limit] 在累计耗时超限时停止;[Jitter]/[Limit] 会把被包装者的停止
+// 信号传递出去。
+type Stopper interface {
+	Backoff
+	NextOrStop(attempt int) (wait time.Duration, ok bool)
+}
+
+// nextOrStop 计算 b 的第 attempt 次等待:b 实现 [Stopper] 时使用
+// NextOrStop,否则 Next 的结果总是有效。
+func nextOrStop(b Backoff, attempt int) (time.Duration, bool) {
+	if s, ok := b.(Stopper); ok {
+		return s.NextOrStop(attempt)
+	}
+	return b.Next(attempt), true
+}
+
+// RandSource 是抖动类策略的随机数来源,Float64 返回 [0, 1) 内的值。
+// nil 表示使用 math/rand 的全局源(并发安全);测试中可注入确定性的源。
+// 注入的源若被并发的 Do 共享,须自行保证并发安全(*rand.Rand 不是)。
+type RandSource interface {
+	Float64() float64
+}
+
+// randFloat 从 r 取一个 [0, 1) 内的随机数,r 为 nil 时使用全局源。
+func randFloat(r RandSource) float64 {
+	if r == nil {
+		return rand.Float64()
+	}
+	return r.Float64()
+}
+
+// scale 返回 d*f,f 取 [0, 1),结果不超过 d。
+func scale(d time.Duration, f float64) time.Duration {
+	return time.Duration(float64(d) * f)
+}
+
 // Constant 在每次重试前等待相同时长。零值等待 0(等价于立即重试)。
 //
 //	retriever.Constant(50 * time.Millisecond)
@@ -115,28 +176,237 @@ func (e Exponential) Next(attempt int) time.Duration {
 	}
 }
 
-// Jitter 在被包装的 [Backoff] 基础上叠加随机抖动,等待时长变为
-// [wait, wait+Factor*wait)。Factor<=0 时退化为不抖动。
+// JitterMode 选择 [Jitter] 的抖动方式。
+type JitterMode int
+
+const (
+	// JitterAdditive 在 wait 之上叠加抖动:[wait, wait+Factor*wait)。零值,
+	// 即 [Jitter] 的默认方式。
+	JitterAdditive JitterMode = iota
+	// JitterFull 在 [0, wait) 内均匀取值("full jitter"),最大程度打散
+	// 重试时刻,代价是个别等待可能很短。忽略 Factor。
+	JitterFull
+	// JitterEqual 取 wait/2 加 [0, wait/2) 内的抖动("equal jitter"),在
+	// 打散与保证最小等待之间折中。忽略 Factor。
+	JitterEqual
+)
+
+// Jitter 在被包装的 [Backoff] 基础上叠加随机抖动。默认方式
+// ([JitterAdditive])下等待时长变为 [wait, wait+Factor*wait),Factor<=0
+// 时退化为不抖动;[JitterFull] 与 [JitterEqual] 见各自说明。
 // 抖动可避免大量客户端在退避后同步重试造成的"重试风暴"。
 // 即便被包装退避返回 [maxDuration],叠加抖动也会饱和到 [maxDuration],
 // 不会因 wait+jitter 回绕为负数而让 [time.NewTimer] 立即触发。
+// Rand 为 nil 时使用全局随机源。被包装者若是 [StatefulBackoff],Jitter
+// 会把 Start 传递给它。
 //
 //	retriever.Jitter{Backoff: retriever.Exponential{Base: 100 * time.Millisecond, Factor: 2}, Factor: 0.3}
+//	retriever.Jitter{Backoff: retriever.Exponential{Base: 100 * time.Millisecond, Factor: 2}, Mode: retriever.JitterFull}
 type Jitter struct {
 	Backoff Backoff
 	Factor  float64
+	Mode    JitterMode
+	Rand    RandSource
 }
 
-// Next 实现 [Backoff]:返回 b.Backoff.Next(attempt) 加上
-// [0, Factor*wait) 区间内的随机抖动,饱和加法保证不回绕为负。
+// Next 实现 [Backoff]:按 Mode 在 b.Backoff.Next(attempt) 上施加抖动,
+// 饱和加法保证不回绕为负。被包装者返回非正值时原样返回。
 func (j Jitter) Next(attempt int) time.Duration {
 	if j.Backoff == nil {
 		return 0
 	}
-	wait := j.Backoff.Next(attempt)
-	if j.Factor <= 0 || wait <= 0 {
+	return j.apply(j.Backoff.Next(attempt))
+}
+
+// NextOrStop 实现 [Stopper]:被包装者要求停止时停止,否则同 [Jitter.Next]。
+func (j Jitter) NextOrStop(attempt int) (time.Duration, bool) {
+	if j.Backoff == nil {
+		return 0, true
+	}
+	wait, ok := nextOrStop(j.Backoff, attempt)
+	if !ok {
+		return 0, false
+	}
+	return j.apply(wait), true
+}
+
+// apply 按 Mode 在 wait 上施加抖动。
+func (j Jitter) apply(wait time.Duration) time.Duration {
+	if wait <= 0 {
 		return wait
 	}
-	jitter := time.Duration(rand.Float64() * j.Factor * float64(wait))
-	return addSat(wait, jitter)
+	switch j.Mode {
+	case JitterFull:
+		return scale(wait, randFloat(j.Rand))
+	case JitterEqual:
+		half := wait / 2
+		return addSat(wait-half, scale(half, randFloat(j.Rand)))
+	default:
+		if j.Factor <= 0 {
+			return wait
+		}
+		jitter := time.Duration(randFloat(j.Rand) * j.Factor * float64(wait))
+		return addSat(wait, jitter)
+	}
+}
+
+// Start 实现 [StatefulBackoff]:把 Start 传递给被包装的 Backoff。
+func (j Jitter) Start() Backoff {
+	j.Backoff = startBackoff(j.Backoff)
+	return j
+}
+
+// Fibonacci 按斐波那契数列增长等待:Base * F(attempt+1),即 Base 的
+// 1,1,2,3,5,8... 倍。增长比 [Exponential]{Factor: 2} 平缓,溢出时钳制到
+// [maxDuration]。Base<=0 时立即重试。
+//
+//	retriever.Fibonacci{Base: 100 * time.Millisecond}
+type Fibonacci struct {
+	Base time.Duration
+}
+
+// Next 实现 [Backoff]。
+func (f Fibonacci) Next(attempt int) time.Duration {
+	if f.Base <= 0 {
+		return 0
+	}
+	if attempt < 0 {
+		attempt = 0
+	}
+	prev, cur := time.Duration(0), f.Base
+	for i := 0; i < attempt; i++ {
+		prev, cur = cur, addSat(prev, cur)
+		if cur == maxDuration {
+			break
+		}
+	}
+	return cur
+}
+
+// Decorrelated 是 AWS 架构博客所述的 "decorrelated jitter":每次等待在
+// [Base, 3*上一次等待) 内随机取值,并以 Cap 封顶。它依赖上一次的等待时长,
+// 因此是 [StatefulBackoff]:Do 为每次调用创建独立状态,同一个 Decorrelated
+// 可被并发的 Do 安全共享。Cap<=0 表示不封顶;Base<=0 时立即重试;Rand 为
+// nil 时使用全局随机源。
+//
+//	retriever.Decorrelated{Base: 100 * time.Millisecond, Cap: 10 * time.Second}
+type Decorrelated struct {
+	Base time.Duration
+	Cap  time.Duration
+	Rand RandSource
+}
+
+// Next 实现 [Backoff]。脱离 Do 直接调用时没有上一次等待可依,按
+// "上一次等待为 Base*3^(attempt-1)" 近似:在 [Base, Base*3^attempt) 内取值。
+func (d Decorrelated) Next(attempt int) time.Duration {
+	if d.Base <= 0 {
+		return 0
+	}
+	if attempt < 0 {
+		attempt = 0
+	}
+	upper := Exponential{Base: d.Base, Factor: 3}.Next(attempt)
+	return d.between(upper)
+}
+
+// Start 实现 [StatefulBackoff]。
+func (d Decorrelated) Start() Backoff {
+	return &decorrelatedRun{Decorrelated: d}
+}
+
+// between 在 [Base, upper) 内随机取值,upper 先以 Cap 封顶;封顶后不大于
+// Base 时直接返回 upper。
+func (d Decorrelated) between(upper time.Duration) time.Duration {
+	if d.Cap > 0 {
+		upper = min(upper, d.Cap)
+	}
+	if upper <= d.Base {
+		return upper
+	}
+	return d.Base + scale(upper-d.Base, randFloat(d.Rand))
+}
+
+// decorrelatedRun 是 [Decorrelated] 在单次调用内的状态。
+type decorrelatedRun struct {
+	Decorrelated
+	prev time.Duration
+}
+
+// Next 实现 [Backoff]:在 [Base, 3*prev) 内取值并记下结果。
+func (r *decorrelatedRun) Next(_ int) time.Duration {
+	if r.Base <= 0 {
+		return 0
+	}
+	prev := max(r.prev, r.Base)
+	r.prev = r.between(addSat(prev, mulStep(2, prev)))
+	return r.prev
+}
+
+// Limit 为被包装的 [Backoff] 加上限制:Cap>0 时每次等待不超过 Cap;
+// MaxElapsed>0 时,一旦自本次 Do 开始的耗时加上下一次等待将超过
+// MaxElapsed,即经 [Stopper] 停止重试。两者都为 0 时原样透传。MaxElapsed
+// 需要记录起始时刻,因此 Limit 是 [StatefulBackoff];脱离 Do 直接调用
+// Next 时只应用 Cap。
+//
+//	retriever.Limit{
+//	    Backoff:    retriever.Exponential{Base: 100 * time.Millisecond, Factor: 2},
+//	    Cap:        5 * time.Second,
+//	    MaxElapsed: 30 * time.Second,
+//	}
+type Limit struct {
+	Backoff    Backoff
+	Cap        time.Duration
+	MaxElapsed time.Duration
+}
+
+// Next 实现 [Backoff]:返回以 Cap 封顶的 b.Backoff.Next(attempt)。
+func (l Limit) Next(attempt int) time.Duration {
+	if l.Backoff == nil {
+		return 0
+	}
+	return l.cap(l.Backoff.Next(attempt))
+}
+
+// NextOrStop 实现 [Stopper]:被包装者要求停止时停止,否则同 [Limit.Next]。
+// 脱离 Do 直接调用时不检查 MaxElapsed。
+func (l Limit) NextOrStop(attempt int) (time.Duration, bool) {
+	if l.Backoff == nil {
+		return 0, true
+	}
+	wait, ok := nextOrStop(l.Backoff, attempt)
+	return l.cap(wait), ok
+}
+
+// cap 以 Cap 为 wait 封顶。
+func (l Limit) cap(wait time.Duration) time.Duration {
+	if l.Cap > 0 && wait > l.Cap {
+		return l.Cap
+	}
+	return wait
+}
+
+// Start 实现 [StatefulBackoff]:记录起始时刻,并把 Start 传递给被包装者。
+func (l Limit) Start() Backoff {
+	l.Backoff = startBackoff(l.Backoff)
+	return &limitRun{Limit: l, start: time.Now(), now: time.Now}
+}
+
+// limitRun 是 [Limit] 在单次调用内的状态。now 供测试注入时钟。
+type limitRun struct {
+	Limit
+	start time.Time
+	now   func() time.Time
+}
+
+// NextOrStop 实现 [Stopper]:被包装者要求停止、或累计耗时加上下一次等待将
+// 超过 MaxElapsed 时停止。
+func (r *limitRun) NextOrStop(attempt int) (time.Duration, bool) {
+	wait, ok := r.Limit.NextOrStop(attempt)
+	if !ok {
+		return 0, false
+	}
+	if r.MaxElapsed > 0 && r.now().Sub(r.start)+max(wait, 0) > r.MaxElapsed {
+		return 0, false
+	}
+	return wait, true
 }
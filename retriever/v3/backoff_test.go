@@ -1,7 +1,10 @@
 package retriever
 
 import (
+	"context"
+	"errors"
 	"math"
+	"sync"
 	"testing"
 	"time"
 )
@@ -313,3 +316,220 @@ func TestAddSat_NegativeInputs(t *testing.T) {
 		t.Errorf("addSat(1ms, -1s) = %v, want 1ms (keep larger)", got)
 	}
 }
+
+// seqRand 依次返回 vals 中的值(循环),作为确定性的随机源。
+type seqRand struct {
+	vals []float64
+	i    int
+}
+
+func (r *seqRand) Float64() float64 {
+	v := r.vals[r.i%len(r.vals)]
+	r.i++
+	return v
+}
+
+func TestJitter_FullMode(t *testing.T) {
+	j := Jitter{Backoff: Constant(100 * time.Millisecond), Mode: JitterFull, Rand: &seqRand{vals: []float64{0, 0.5, 0.99}}}
+	want := []time.Duration{0, 50 * time.Millisecond, 99 * time.Millisecond}
+	for i, w := range want {
+		if got := j.Next(0); got != w {
+			t.Errorf("Next #%d = %v, want %v", i, got, w)
+		}
+	}
+}
+
+func TestJitter_EqualMode(t *testing.T) {
+	j := Jitter{Backoff: Constant(100 * time.Millisecond), Mode: JitterEqual, Rand: &seqRand{vals: []float64{0, 0.5}}}
+	if got := j.Next(0); got != 50*time.Millisecond {
+		t.Errorf("Next = %v, want 50ms", got)
+	}
+	if got := j.Next(0); got != 75*time.Millisecond {
+		t.Errorf("Next = %v, want 75ms", got)
+	}
+}
+
+func TestJitter_AdditiveWithRand(t *testing.T) {
+	j := Jitter{Backoff: Constant(100 * time.Millisecond), Factor: 0.5, Rand: &seqRand{vals: []float64{0.5}}}
+	if got := j.Next(0); got != 125*time.Millisecond {
+		t.Errorf("Next = %v, want 125ms", got)
+	}
+}
+
+func TestJitter_PassesStop(t *testing.T) {
+	start := time.Unix(0, 0)
+	inner := &limitRun{
+		Limit: Limit{Backoff: Constant(100 * time.Millisecond), MaxElapsed: time.Millisecond},
+		start: start,
+		now:   func() time.Time { return start },
+	}
+	j := Jitter{Backoff: inner, Mode: JitterFull}
+	if _, ok := j.NextOrStop(0); ok {
+		t.Error("NextOrStop ok = true, want the wrapped stop passed through")
+	}
+	// 非 Stopper 的负值只是等待时长,不是停止信号。
+	if wait, ok := (Jitter{Backoff: Constant(-time.Second)}).NextOrStop(0); !ok || wait != -time.Second {
+		t.Errorf("NextOrStop = (%v, %v), want (-1s, true)", wait, ok)
+	}
+}
+
+func TestFibonacci(t *testing.T) {
+	f := Fibonacci{Base: 10 * time.Millisecond}
+	want := []time.Duration{10, 10, 20, 30, 50, 80, 130}
+	for attempt, w := range want {
+		if got := f.Next(attempt); got != w*time.Millisecond {
+			t.Errorf("Fibonacci.Next(%d) = %v, want %v", attempt, got, w*time.Millisecond)
+		}
+	}
+	if got := (Fibonacci{}).Next(3); got != 0 {
+		t.Errorf("zero Fibonacci.Next(3) = %v, want 0", got)
+	}
+	if got := f.Next(-1); got != 10*time.Millisecond {
+		t.Errorf("Fibonacci.Next(-1) = %v, want 10ms", got)
+	}
+	if got := f.Next(10000); got != maxDuration {
+		t.Errorf("Fibonacci.Next(10000) = %v, want clamped to maxDuration", got)
+	}
+}
+
+func TestDecorrelated_StateAcrossRetries(t *testing.T) {
+	// 随机源恒为 0.5:每次等待 = Base + (min(3*prev, Cap) - Base)/2。
+	d := Decorrelated{Base: 100 * time.Millisecond, Cap: time.Second, Rand: &seqRand{vals: []float64{0.5}}}
+	b := d.Start()
+	want := []time.Duration{200, 350, 550, 550}
+	for i, w := range want {
+		if got := b.Next(i); got != w*time.Millisecond {
+			t.Errorf("Next #%d = %v, want %v", i, got, w*time.Millisecond)
+		}
+	}
+}
+
+func TestDecorrelated_BoundsAndIndependentRuns(t *testing.T) {
+	d := Decorrelated{Base: 10 * time.Millisecond, Cap: 200 * time.Millisecond}
+	for run := 0; run < 20; run++ {
+		b := d.Start()
+		for i := 0; i < 10; i++ {
+			if got := b.Next(i); got < 10*time.Millisecond || got > 200*time.Millisecond {
+				t.Fatalf("Next = %v, want in [10ms, 200ms]", got)
+			}
+		}
+	}
+	// 每次 Start 都从 Base 重新开始。
+	fixed := Decorrelated{Base: 100 * time.Millisecond, Rand: &seqRand{vals: []float64{0}}}
+	if got := fixed.Start().Next(0); got != 100*time.Millisecond {
+		t.Errorf("fresh run Next = %v, want Base", got)
+	}
+}
+
+func TestDecorrelated_StatelessNext(t *testing.T) {
+	d := Decorrelated{Base: 100 * time.Millisecond, Rand: &seqRand{vals: []float64{0.5}}}
+	// attempt 2:在 [100ms, 900ms) 内取中点。
+	if got := d.Next(2); got != 500*time.Millisecond {
+		t.Errorf("Next(2) = %v, want 500ms", got)
+	}
+	if got := (Decorrelated{}).Next(1); got != 0 {
+		t.Errorf("zero Decorrelated.Next = %v, want 0", got)
+	}
+	if got := (Decorrelated{}).Start().Next(1); got != 0 {
+		t.Errorf("zero Decorrelated run Next = %v, want 0", got)
+	}
+}
+
+func TestLimit_Cap(t *testing.T) {
+	l := Limit{Backoff: Exponential{Base: 100 * time.Millisecond, Factor: 2}, Cap: 300 * time.Millisecond}
+	want := []time.Duration{100, 200, 300, 300}
+	for attempt, w := range want {
+		if got := l.Next(attempt); got != w*time.Millisecond {
+			t.Errorf("Limit.Next(%d) = %v, want %v", attempt, got, w*time.Millisecond)
+		}
+	}
+	if got := (Limit{}).Next(0); got != 0 {
+		t.Errorf("Limit{}.Next = %v, want 0", got)
+	}
+}
+
+func TestLimit_MaxElapsed(t *testing.T) {
+	start := time.Unix(0, 0)
+	now := start
+	run := &limitRun{
+		Limit: Limit{Backoff: Constant(100 * time.Millisecond), MaxElapsed: time.Second},
+		start: start,
+		now:   func() time.Time { return now },
+	}
+	if got := run.Next(0); got != 100*time.Millisecond {
+		t.Errorf("Next = %v, want 100ms", got)
+	}
+	now = start.Add(900 * time.Millisecond)
+	if got, ok := run.NextOrStop(1); !ok || got != 100*time.Millisecond {
+		t.Errorf("NextOrStop = (%v, %v), want (100ms, true) exactly at the limit", got, ok)
+	}
+	now = start.Add(901 * time.Millisecond)
+	if _, ok := run.NextOrStop(2); ok {
+		t.Error("NextOrStop ok = true, want stop past the limit")
+	}
+}
+
+func TestLimit_StartPropagates(t *testing.T) {
+	l := Limit{Backoff: Decorrelated{Base: 100 * time.Millisecond, Rand: &seqRand{vals: []float64{0.5}}}}
+	b := l.Start()
+	if got := b.Next(0); got != 200*time.Millisecond {
+		t.Errorf("Next #0 = %v, want 200ms", got)
+	}
+	if got := b.Next(1); got != 350*time.Millisecond {
+		t.Errorf("Next #1 = %v, want 350ms (state carried by the wrapped run)", got)
+	}
+	j := Jitter{Backoff: Decorrelated{Base: 100 * time.Millisecond, Rand: &seqRand{vals: []float64{0.5}}}}
+	if _, ok := j.Start().(Jitter).Backoff.(*decorrelatedRun); !ok {
+		t.Error("Jitter.Start should start the wrapped Decorrelated")
+	}
+}
+
+func TestDo_StatefulBackoffPerCall(t *testing.T) {
+	// 并发的 Do 共享同一个 Decorrelated,各自的状态互不干扰(配合 -race)。
+	r := New[int](WithMaxAttempts(4), WithBackoff(Decorrelated{Base: time.Microsecond, Cap: time.Millisecond}))
+	var wg sync.WaitGroup
+	for i := 0; i < 20; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			_, _ = r.Do(context.Background(), func(context.Context) (int, error) { return 0, errBoom })
+		}()
+	}
+	wg.Wait()
+}
+
+// negativeBackoff 模拟减法抖动的自定义策略:返回负的等待时长。
+type negativeBackoff struct{}
+
+func (negativeBackoff) Next(int) time.Duration { return -time.Millisecond }
+
+func TestDo_NegativeBackoffRetriesImmediately(t *testing.T) {
+	// 普通 Backoff 的负值沿用原有语义:立即重试,而不是停止。
+	calls := 0
+	r := New[int](WithMaxAttempts(4), WithBackoff(negativeBackoff{}))
+	_, err := r.Do(context.Background(), func(context.Context) (int, error) {
+		calls++
+		return 0, errBoom
+	})
+	if calls != 4 {
+		t.Errorf("calls = %d, want all 4 attempts", calls)
+	}
+	if !errors.Is(err, ErrMaxAttempts) {
+		t.Errorf("err = %v, want ErrMaxAttempts", err)
+	}
+}
+
+func TestDo_BackoffStopEndsRetries(t *testing.T) {
+	calls := 0
+	r := New[int](WithMaxAttempts(10), WithBackoff(Limit{Backoff: Constant(20 * time.Millisecond), MaxElapsed: 50 * time.Millisecond}))
+	_, err := r.Do(context.Background(), func(context.Context) (int, error) {
+		calls++
+		return 0, errBoom
+	})
+	if !errors.Is(err, ErrMaxAttempts) || !errors.Is(err, errBoom) {
+		t.Errorf("err = %v, want ErrMaxAttempts wrapping errBoom", err)
+	}
+	if calls < 2 || calls > 4 {
+		t.Errorf("calls = %d, want the elapsed limit to stop retries early", calls)
+	}
+}
@@ -0,0 +1,400 @@
+package retriever
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/tenz-io/gokit/monitor/v3"
+)
+
+// ErrCircuitOpen 在熔断器处于 open 状态(或 half-open 探测名额已满)而拒绝
+// 调用时返回。被拒绝的调用不会执行 fn。[Retriever.Do] 遇到它会立即返回,
+// 不再重试:对一个已判定宕机的依赖继续重试只会放大故障。
+var ErrCircuitOpen = errors.New("retriever: circuit breaker is open")
+
+// errAttemptPanicked 是 fn panic 时交给 guard 的结果,使其释放占用的名额
+// (例如 half-open 探测位),随后 panic 照常向上传播。
+var errAttemptPanicked = errors.New("retriever: attempt panicked")
+
+// BreakerState 是熔断器的状态。
+type BreakerState int
+
+const (
+	// StateClosed:正常放行,并在滚动窗口内统计失败率。
+	StateClosed BreakerState = iota
+	// StateOpen:拒绝全部调用,直到冷却期结束。
+	StateOpen
+	// StateHalfOpen:冷却期结束后放行有限个探测调用,全部成功则闭合,
+	// 任一失败则重新打开。
+	StateHalfOpen
+)
+
+// String 返回状态名,同时用作 monitor 的 opt 后缀。
+func (s BreakerState) String() string {
+	switch s {
+	case StateClosed:
+		return "closed"
+	case StateOpen:
+		return "open"
+	case StateHalfOpen:
+		return "half_open"
+	default:
+		return fmt.Sprintf("BreakerState(%d)", int(s))
+	}
+}
+
+// BreakerOption 在构造期配置一个 [CircuitBreaker]。
+type BreakerOption func(*breakerOptions)
+
+type breakerOptions struct {
+	failureRate    float64
+	window         time.Duration
+	buckets        int
+	minRequests    int
+	coolDown       time.Duration
+	halfOpenProbes int
+	isFailure      func(error) bool
+	onStateChange  func(name string, from, to BreakerState)
+}
+
+func defaultBreakerOptions() breakerOptions {
+	return breakerOptions{
+		failureRate:    0.5,
+		window:         10 * time.Second,
+		buckets:        10,
+		minRequests:    20,
+		coolDown:       5 * time.Second,
+		halfOpenProbes: 1,
+		isFailure:      defaultIsFailure,
+	}
+}
+
+// defaultIsFailure 把除调用方取消与本地拒绝之外的所有错误计为失败:
+// context.Canceled 反映的是调用方放弃,[ErrBulkheadFull] 反映的是本地饱和,
+// 都不代表依赖不健康。
+func defaultIsFailure(err error) bool {
+	return err != nil && !errors.Is(err, context.Canceled) && !localRejection(err)
+}
+
+// WithBreakerFailureRate 设置触发熔断的失败率阈值,取值 (0, 1]:窗口内
+// 失败数/总数达到该值即打开。越界值被忽略(默认 0.5)。
+func WithBreakerFailureRate(rate float64) BreakerOption {
+	return func(o *breakerOptions) {
+		if rate > 0 && rate <= 1 {
+			o.failureRate = rate
+		}
+	}
+}
+
+// WithBreakerWindow 设置滚动窗口总时长 d 及其分桶数 buckets:统计只保留
+// 最近 d 内的结果,每过 d/buckets 丢弃最旧的一桶。非正数被忽略(默认
+// 10s/10 桶)。
+func WithBreakerWindow(d time.Duration, buckets int) BreakerOption {
+	return func(o *breakerOptions) {
+		if d > 0 {
+			o.window = d
+		}
+		if buckets > 0 {
+			o.buckets = buckets
+		}
+	}
+}
+
+// WithBreakerMinRequests 设置判定失败率所需的最小请求量:窗口内请求数不足
+// n 时,无论失败率多高都不熔断,避免低流量下一两次失败就打开。非正数被
+// 忽略(默认 20)。
+func WithBreakerMinRequests(n int) BreakerOption {
+	return func(o *breakerOptions) {
+		if n > 0 {
+			o.minRequests = n
+		}
+	}
+}
+
+// WithBreakerCoolDown 设置 open 状态的冷却期,期满后转入 half-open。非正数
+// 被忽略(默认 5s)。
+func WithBreakerCoolDown(d time.Duration) BreakerOption {
+	return func(o *breakerOptions) {
+		if d > 0 {
+			o.coolDown = d
+		}
+	}
+}
+
+// WithBreakerHalfOpenProbes 设置 half-open 状态下允许同时在途的探测调用数,
+// 也是闭合所需的连续成功次数。非正数被忽略(默认 1)。
+func WithBreakerHalfOpenProbes(n int) BreakerOption {
+	return func(o *breakerOptions) {
+		if n > 0 {
+			o.halfOpenProbes = n
+		}
+	}
+}
+
+// WithBreakerIsFailure 设置失败判定:返回 true 的错误计入失败率,返回
+// false 的错误视作成功(例如 4xx 属于调用方问题,不代表依赖不健康);
+// 例外是未被判定为失败的 context.Canceled,它既不计成功也不计失败;内层
+// guard 的本地拒绝([ErrBulkheadFull]、[ErrCircuitOpen])总是既不计成功也
+// 不计失败。nil 被忽略,保留默认:除上述错误之外的非 nil 错误均为失败。
+func WithBreakerIsFailure(fn func(error) bool) BreakerOption {
+	return func(o *breakerOptions) {
+		if fn != nil {
+			o.isFailure = fn
+		}
+	}
+}
+
+// WithBreakerStateChange 设置状态迁移回调,在迁移发生后、于锁外同步调用,
+// 因此回调内可安全读取 [CircuitBreaker.State]。回调须快速返回。
+func WithBreakerStateChange(fn func(name string, from, to BreakerState)) BreakerOption {
+	return func(o *breakerOptions) { o.onStateChange = fn }
+}
+
+// CircuitBreaker 是一个基于滚动窗口失败率的熔断器,含 closed/open/
+// half-open 三态:
+//
+//   - closed:放行全部调用。窗口内请求数达到最小请求量且失败率达到阈值
+//     时打开。
+//   - open:以 [ErrCircuitOpen] 拒绝全部调用,冷却期满后转入 half-open。
+//   - half-open:至多放行 N 个并发探测;N 个探测全部成功则闭合(窗口清零),
+//     任一失败立即重新打开。
+//
+// 用 [WrapBreaker] 保护任意 [DoFunc],或用 [WithCircuitBreaker] 挂到
+// [Retriever] 上(每次尝试都经过熔断器)。同一个 CircuitBreaker 应在
+// 访问同一依赖的所有调用方之间共享;它是并发安全的。
+//
+// 状态迁移除触发 [WithBreakerStateChange] 回调外,还经由
+// monitor.FromContext(ctx) 计数:dsCmd 为熔断器名,opt 为
+// "breaker_<state>";被拒绝的调用以 opt "breaker_reject" 计数。
+type CircuitBreaker struct {
+	name string
+	opts breakerOptions
+	now  func() time.Time
+
+	mu       sync.Mutex
+	state    BreakerState
+	gen      uint64 // 每次迁移递增,丢弃来自旧状态的迟到结果
+	openedAt time.Time
+	window   rollingWindow
+	probes   int // half-open 在途探测数
+	passed   int // half-open 已成功探测数
+}
+
+// NewCircuitBreaker 返回一个初始为 closed 的 [CircuitBreaker]。name 标识被
+// 保护的依赖,用于回调与 monitor 的 dsCmd。零个 option 即可用,默认:
+// 10s/10 桶窗口内至少 20 次请求且失败率 ≥ 50% 时打开,冷却 5s,half-open
+// 放行 1 个探测。
+func NewCircuitBreaker(name string, opts ...BreakerOption) *CircuitBreaker {
+	o := defaultBreakerOptions()
+	for _, opt := range opts {
+		opt(&o)
+	}
+	return &CircuitBreaker{
+		name:   name,
+		opts:   o,
+		now:    time.Now,
+		window: newRollingWindow(o.window, o.buckets),
+	}
+}
+
+// Name 返回熔断器名。
+func (cb *CircuitBreaker) Name() string { return cb.name }
+
+// State 返回当前状态。冷却期满的 open 状态报告为 half-open;State 没有调用方
+// 的 ctx,因此迁移本身(含回调与 monitor 计数)推迟到下一次调用经过熔断器
+// 时,以该调用的 ctx 发出。
+func (cb *CircuitBreaker) State() BreakerState {
+	cb.mu.Lock()
+	defer cb.mu.Unlock()
+	if cb.state == StateOpen && cb.now().Sub(cb.openedAt) >= cb.opts.coolDown {
+		return StateHalfOpen
+	}
+	return cb.state
+}
+
+// transition 记录一次状态迁移,在锁外交给回调与 monitor。
+type transition struct {
+	from, to BreakerState
+	changed  bool
+}
+
+// acquire 实现 guard:放行时返回一个须以本次尝试结果调用恰好一次的
+// release;拒绝时返回 [ErrCircuitOpen]。
+func (cb *CircuitBreaker) acquire(ctx context.Context) (func(error), error) {
+	cb.mu.Lock()
+	tr := cb.advanceLocked(cb.now())
+	switch {
+	case cb.state == StateOpen,
+		cb.state == StateHalfOpen && cb.probes >= cb.opts.halfOpenProbes:
+		cb.mu.Unlock()
+		cb.emit(ctx, tr)
+		monitor.FromContext(ctx).Count(ctx, cb.name, "1", "breaker_reject")
+		return nil, ErrCircuitOpen
+	case cb.state == StateHalfOpen:
+		cb.probes++
+	}
+	gen := cb.gen
+	cb.mu.Unlock()
+	cb.emit(ctx, tr)
+
+	return func(err error) { cb.record(ctx, gen, err) }, nil
+}
+
+// record 计入一次结果。gen 与当前代不符的结果来自已结束的状态,被丢弃。
+func (cb *CircuitBreaker) record(ctx context.Context, gen uint64, err error) {
+	now := cb.now()
+	// 调用方取消(且未被判定为失败)与本地拒绝都不提供任何健康信息:既不计
+	// 成功也不计失败。其余未被判定为失败的错误按成功计。
+	ignored := errors.Is(err, context.Canceled) && !cb.opts.isFailure(err) || localRejection(err)
+	failed := err != nil && cb.opts.isFailure(err)
+
+	cb.mu.Lock()
+	if gen != cb.gen {
+		cb.mu.Unlock()
+		return
+	}
+	var tr transition
+	switch cb.state {
+	case StateClosed:
+		if ignored {
+			break
+		}
+		cb.window.add(now, failed)
+		total, failures := cb.window.totals(now)
+		if total >= cb.opts.minRequests && float64(failures) >= cb.opts.failureRate*float64(total) {
+			tr = cb.setStateLocked(StateOpen, now)
+		}
+	case StateHalfOpen:
+		cb.probes--
+		switch {
+		case ignored, errors.Is(err, errAttemptPanicked):
+			// 不提供健康信息:仅归还探测名额。
+		case failed:
+			tr = cb.setStateLocked(StateOpen, now)
+		default:
+			cb.passed++
+			if cb.passed >= cb.opts.halfOpenProbes {
+				tr = cb.setStateLocked(StateClosed, now)
+			}
+		}
+	}
+	cb.mu.Unlock()
+	cb.emit(ctx, tr)
+}
+
+// localRejection 报告 err 是否是本地 guard 的拒绝:内层舱壁已满
+// ([ErrBulkheadFull])、在内层舱壁排队期间 ctx 终止,或内层熔断器打开
+// ([ErrCircuitOpen])。此时 fn 没有被调用、依赖也未被访问,因此不论
+// isFailure 如何判定,都不计入熔断统计;否则本地饱和加上较短的截止时间
+// 会把健康的依赖熔断掉。
+func localRejection(err error) bool {
+	return errors.Is(err, ErrBulkheadFull) || errors.Is(err, errBulkheadQueueAbandoned) ||
+		errors.Is(err, ErrCircuitOpen)
+}
+
+// advanceLocked 在冷却期满时把 open 推进为 half-open。调用方须持锁。
+func (cb *CircuitBreaker) advanceLocked(now time.Time) transition {
+	if cb.state == StateOpen && now.Sub(cb.openedAt) >= cb.opts.coolDown {
+		return cb.setStateLocked(StateHalfOpen, now)
+	}
+	return transition{}
+}
+
+// setStateLocked 迁移到 to 并重置该状态的计数。调用方须持锁。
+func (cb *CircuitBreaker) setStateLocked(to BreakerState, now time.Time) transition {
+	from := cb.state
+	cb.state = to
+	cb.gen++
+	cb.probes, cb.passed = 0, 0
+	switch to {
+	case StateOpen:
+		cb.openedAt = now
+	case StateClosed:
+		cb.window.reset()
+	}
+	return transition{from: from, to: to, changed: true}
+}
+
+// emit 在锁外通知一次迁移。
+func (cb *CircuitBreaker) emit(ctx context.Context, tr transition) {
+	if !tr.changed {
+		return
+	}
+	monitor.FromContext(ctx).Count(ctx, cb.name, "0", "breaker_"+tr.to.String())
+	if cb.opts.onStateChange != nil {
+		cb.opts.onStateChange(cb.name, tr.from, tr.to)
+	}
+}
+
+// WrapBreaker 返回一个经 cb 保护的 [DoFunc]:cb 拒绝时不调用 fn,直接返回
+// 零值与 [ErrCircuitOpen];否则调用 fn 并把其结果计入 cb。
+func WrapBreaker[T any](cb *CircuitBreaker, fn DoFunc[T]) DoFunc[T] {
+	return guardFunc(cb, fn, nil)
+}
+
+// WithCircuitBreaker 让 [Retriever] 的每次尝试都经过 cb。cb 拒绝时 Do 立即
+// 返回 [ErrCircuitOpen](若此前已有失败尝试,一并包裹其错误,并携带其
+// result),不再重试。多次传入时按传入顺序由外向内嵌套。nil 被忽略。
+func WithCircuitBreaker(cb *CircuitBreaker) Option {
+	return func(o *options) {
+		if cb != nil {
+			o.guards = append(o.guards, cb)
+		}
+	}
+}
+
+// rollingWindow 是按时间分桶的成功/失败计数器。桶以绝对时间编号,读写时
+// 按编号惰性清除过期桶,因此无需后台 goroutine。
+type rollingWindow struct {
+	width   time.Duration
+	buckets []windowBucket
+}
+
+type windowBucket struct {
+	epoch    int64
+	total    int
+	failures int
+}
+
+func newRollingWindow(d time.Duration, n int) rollingWindow {
+	width := d / time.Duration(n)
+	if width <= 0 {
+		width = 1
+	}
+	return rollingWindow{width: width, buckets: make([]windowBucket, n)}
+}
+
+func (w *rollingWindow) epoch(now time.Time) int64 {
+	return now.UnixNano() / int64(w.width)
+}
+
+func (w *rollingWindow) add(now time.Time, failed bool) {
+	e := w.epoch(now)
+	b := &w.buckets[e%int64(len(w.buckets))]
+	if b.epoch != e {
+		*b = windowBucket{epoch: e}
+	}
+	b.total++
+	if failed {
+		b.failures++
+	}
+}
+
+func (w *rollingWindow) totals(now time.Time) (total, failures int) {
+	e := w.epoch(now)
+	oldest := e - int64(len(w.buckets)) + 1
+	for _, b := range w.buckets {
+		if b.epoch >= oldest && b.epoch <= e {
+			total += b.total
+			failures += b.failures
+		}
+	}
+	return total, failures
+}
+
+func (w *rollingWindow) reset() {
+	clear(w.buckets)
+}
@@ -0,0 +1,365 @@
+package retriever
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/tenz-io/gokit/monitor/v3"
+)
+
+// fakeClock 是可手动推进的时钟,注入到 CircuitBreaker.now 以获得确定性。
+type fakeClock struct {
+	mu  sync.Mutex
+	now time.Time
+}
+
+func newFakeClock() *fakeClock { return &fakeClock{now: time.Unix(1_700_000_000, 0)} }
+
+func (c *fakeClock) Now() time.Time {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.now
+}
+
+func (c *fakeClock) Advance(d time.Duration) {
+	c.mu.Lock()
+	c.now = c.now.Add(d)
+	c.mu.Unlock()
+}
+
+// transitionLog 收集状态迁移回调。
+type transitionLog struct {
+	mu  sync.Mutex
+	got []BreakerState
+}
+
+func (l *transitionLog) record(_ string, _, to BreakerState) {
+	l.mu.Lock()
+	l.got = append(l.got, to)
+	l.mu.Unlock()
+}
+
+func (l *transitionLog) states() []BreakerState {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	return append([]BreakerState(nil), l.got...)
+}
+
+func newTestBreaker(clock *fakeClock, log *transitionLog, opts ...BreakerOption) *CircuitBreaker {
+	opts = append([]BreakerOption{
+		WithBreakerMinRequests(4),
+		WithBreakerFailureRate(0.5),
+		WithBreakerWindow(10*time.Second, 10),
+		WithBreakerCoolDown(time.Second),
+		WithBreakerStateChange(log.record),
+	}, opts...)
+	cb := NewCircuitBreaker("dep", opts...)
+	cb.now = clock.Now
+	return cb
+}
+
+func callBreaker(cb *CircuitBreaker, err error) error {
+	_, got := WrapBreaker(cb, func(context.Context) (int, error) { return 0, err })(context.Background())
+	return got
+}
+
+func TestBreakerState_String(t *testing.T) {
+	cases := map[BreakerState]string{
+		StateClosed:     "closed",
+		StateOpen:       "open",
+		StateHalfOpen:   "half_open",
+		BreakerState(9): "BreakerState(9)",
+	}
+	for s, want := range cases {
+		if got := s.String(); got != want {
+			t.Errorf("String() = %q, want %q", got, want)
+		}
+	}
+}
+
+func TestBreaker_MinRequestsGate(t *testing.T) {
+	clock, log := newFakeClock(), &transitionLog{}
+	cb := newTestBreaker(clock, log)
+	// 3 次全失败:失败率 100%,但未达最小请求量 4,不熔断。
+	for i := 0; i < 3; i++ {
+		_ = callBreaker(cb, errBoom)
+	}
+	if got := cb.State(); got != StateClosed {
+		t.Fatalf("State() = %v, want closed below min requests", got)
+	}
+	_ = callBreaker(cb, errBoom)
+	if got := cb.State(); got != StateOpen {
+		t.Fatalf("State() = %v, want open once min requests reached", got)
+	}
+}
+
+func TestBreaker_FailureRateThreshold(t *testing.T) {
+	clock, log := newFakeClock(), &transitionLog{}
+	cb := newTestBreaker(clock, log)
+	// 3 成功 + 1 失败 = 25% < 50%:保持 closed。
+	for i := 0; i < 3; i++ {
+		_ = callBreaker(cb, nil)
+	}
+	_ = callBreaker(cb, errBoom)
+	if got := cb.State(); got != StateClosed {
+		t.Fatalf("State() = %v, want closed at 25%% failures", got)
+	}
+	// 再失败 2 次:3/6 = 50%,打开。
+	_ = callBreaker(cb, errBoom)
+	_ = callBreaker(cb, errBoom)
+	if got := cb.State(); got != StateOpen {
+		t.Fatalf("State() = %v, want open at 50%% failures", got)
+	}
+}
+
+func TestBreaker_WindowExpiresOldResults(t *testing.T) {
+	clock, log := newFakeClock(), &transitionLog{}
+	cb := newTestBreaker(clock, log)
+	for i := 0; i < 3; i++ {
+		_ = callBreaker(cb, errBoom)
+	}
+	clock.Advance(11 * time.Second) // 旧失败滑出窗口
+	_ = callBreaker(cb, errBoom)
+	if got := cb.State(); got != StateClosed {
+		t.Fatalf("State() = %v, want closed after old failures expired", got)
+	}
+}
+
+func TestBreaker_OpenRejectsWithoutCalling(t *testing.T) {
+	clock, log := newFakeClock(), &transitionLog{}
+	cb := newTestBreaker(clock, log)
+	for i := 0; i < 4; i++ {
+		_ = callBreaker(cb, errBoom)
+	}
+	called := false
+	_, err := WrapBreaker(cb, func(context.Context) (int, error) {
+		called = true
+		return 0, nil
+	})(context.Background())
+	if !errors.Is(err, ErrCircuitOpen) {
+		t.Errorf("err = %v, want ErrCircuitOpen", err)
+	}
+	if called {
+		t.Error("fn must not run while the breaker is open")
+	}
+}
+
+func TestBreaker_HalfOpenProbeSuccessCloses(t *testing.T) {
+	clock, log := newFakeClock(), &transitionLog{}
+	cb := newTestBreaker(clock, log, WithBreakerHalfOpenProbes(2))
+	for i := 0; i < 4; i++ {
+		_ = callBreaker(cb, errBoom)
+	}
+	clock.Advance(time.Second)
+	if got := cb.State(); got != StateHalfOpen {
+		t.Fatalf("State() = %v, want half_open after cool-down", got)
+	}
+	_ = callBreaker(cb, nil)
+	if got := cb.State(); got != StateHalfOpen {
+		t.Fatalf("State() = %v, want half_open until all probes pass", got)
+	}
+	_ = callBreaker(cb, nil)
+	if got := cb.State(); got != StateClosed {
+		t.Fatalf("State() = %v, want closed after probes pass", got)
+	}
+
+	want := []BreakerState{StateOpen, StateHalfOpen, StateClosed}
+	got := log.states()
+	if len(got) != len(want) {
+		t.Fatalf("transitions = %v, want %v", got, want)
+	}
+	for i := range want {
+		if got[i] != want[i] {
+			t.Errorf("transitions = %v, want %v", got, want)
+			break
+		}
+	}
+}
+
+func TestBreaker_StateDefersTransitionToCallerCtx(t *testing.T) {
+	clock, log := newFakeClock(), &transitionLog{}
+	cb := newTestBreaker(clock, log)
+	for i := 0; i < 4; i++ {
+		_ = callBreaker(cb, errBoom)
+	}
+	clock.Advance(time.Second)
+	// State 只报告冷却期已满,不在无 ctx 的情况下迁移。
+	if got := cb.State(); got != StateHalfOpen {
+		t.Fatalf("State() = %v, want half_open after cool-down", got)
+	}
+	if got := log.states(); len(got) != 1 {
+		t.Fatalf("transitions = %v, want only the open transition", got)
+	}
+
+	// 下一次调用以自己的 ctx 发出 half_open 迁移,monitor 计数不会丢失。
+	exp := &countingExporter{Exporter: monitor.FromContext(context.Background()), counts: map[string]int{}}
+	ctx := monitor.WithExporter(context.Background(), exp)
+	_, _ = WrapBreaker(cb, func(context.Context) (int, error) { return 0, nil })(ctx)
+	if got := exp.counts["dep/0/breaker_half_open"]; got != 1 {
+		t.Errorf("breaker_half_open count = %d, want 1", got)
+	}
+	if got := exp.counts["dep/0/breaker_closed"]; got != 1 {
+		t.Errorf("breaker_closed count = %d, want 1", got)
+	}
+}
+
+func TestBreaker_HalfOpenProbeFailureReopens(t *testing.T) {
+	clock, log := newFakeClock(), &transitionLog{}
+	cb := newTestBreaker(clock, log)
+	for i := 0; i < 4; i++ {
+		_ = callBreaker(cb, errBoom)
+	}
+	clock.Advance(time.Second)
+	_ = callBreaker(cb, errBoom)
+	if got := cb.State(); got != StateOpen {
+		t.Fatalf("State() = %v, want open after failed probe", got)
+	}
+	// 重新打开后冷却期重新计时。
+	clock.Advance(500 * time.Millisecond)
+	if err := callBreaker(cb, nil); !errors.Is(err, ErrCircuitOpen) {
+		t.Errorf("err = %v, want ErrCircuitOpen during renewed cool-down", err)
+	}
+}
+
+func TestBreaker_HalfOpenLimitsConcurrentProbes(t *testing.T) {
+	clock, log := newFakeClock(), &transitionLog{}
+	cb := newTestBreaker(clock, log)
+	for i := 0; i < 4; i++ {
+		_ = callBreaker(cb, errBoom)
+	}
+	clock.Advance(time.Second)
+
+	release := make(chan struct{})
+	started := make(chan struct{})
+	done := make(chan error, 1)
+	go func() {
+		_, err := WrapBreaker(cb, func(context.Context) (int, error) {
+			close(started)
+			<-release
+			return 0, nil
+		})(context.Background())
+		done <- err
+	}()
+	<-started
+	if err := callBreaker(cb, nil); !errors.Is(err, ErrCircuitOpen) {
+		t.Errorf("second probe err = %v, want ErrCircuitOpen", err)
+	}
+	close(release)
+	if err := <-done; err != nil {
+		t.Errorf("probe err = %v", err)
+	}
+	if got := cb.State(); got != StateClosed {
+		t.Errorf("State() = %v, want closed", got)
+	}
+}
+
+func TestBreaker_CanceledIgnored(t *testing.T) {
+	clock, log := newFakeClock(), &transitionLog{}
+	cb := newTestBreaker(clock, log)
+	for i := 0; i < 10; i++ {
+		_ = callBreaker(cb, context.Canceled)
+	}
+	if got := cb.State(); got != StateClosed {
+		t.Fatalf("State() = %v, want closed: cancellations are not failures", got)
+	}
+	// 取消也不稀释失败率:随后 4 次失败仍应打开。
+	for i := 0; i < 4; i++ {
+		_ = callBreaker(cb, errBoom)
+	}
+	if got := cb.State(); got != StateOpen {
+		t.Fatalf("State() = %v, want open", got)
+	}
+}
+
+func TestBreaker_CustomIsFailure(t *testing.T) {
+	clock, log := newFakeClock(), &transitionLog{}
+	cb := newTestBreaker(clock, log, WithBreakerIsFailure(func(err error) bool {
+		return !errors.Is(err, errPerm)
+	}))
+	for i := 0; i < 10; i++ {
+		_ = callBreaker(cb, errPerm)
+	}
+	if got := cb.State(); got != StateClosed {
+		t.Fatalf("State() = %v, want closed: errPerm is not a failure", got)
+	}
+}
+
+func TestBreaker_PanicReleasesProbe(t *testing.T) {
+	clock, log := newFakeClock(), &transitionLog{}
+	cb := newTestBreaker(clock, log)
+	for i := 0; i < 4; i++ {
+		_ = callBreaker(cb, errBoom)
+	}
+	clock.Advance(time.Second)
+
+	func() {
+		defer func() { _ = recover() }()
+		_, _ = WrapBreaker(cb, func(context.Context) (int, error) { panic("boom") })(context.Background())
+	}()
+	// 探测名额已归还:下一次探测可以进入并闭合熔断器。
+	if err := callBreaker(cb, nil); err != nil {
+		t.Fatalf("probe after panic err = %v, want nil", err)
+	}
+	if got := cb.State(); got != StateClosed {
+		t.Errorf("State() = %v, want closed", got)
+	}
+}
+
+func TestBreaker_OptionsIgnoreInvalid(t *testing.T) {
+	cb := NewCircuitBreaker("x",
+		WithBreakerFailureRate(0),
+		WithBreakerFailureRate(1.5),
+		WithBreakerWindow(0, 0),
+		WithBreakerMinRequests(0),
+		WithBreakerCoolDown(-1),
+		WithBreakerHalfOpenProbes(0),
+		WithBreakerIsFailure(nil),
+	)
+	want := defaultBreakerOptions()
+	o := cb.opts
+	if o.failureRate != want.failureRate || o.window != want.window || o.buckets != want.buckets ||
+		o.minRequests != want.minRequests || o.coolDown != want.coolDown ||
+		o.halfOpenProbes != want.halfOpenProbes || o.isFailure == nil {
+		t.Errorf("invalid options changed defaults: %+v", o)
+	}
+	if cb.Name() != "x" {
+		t.Errorf("Name() = %q, want x", cb.Name())
+	}
+}
+
+func TestDo_CircuitOpenStopsRetrying(t *testing.T) {
+	clock, log := newFakeClock(), &transitionLog{}
+	cb := newTestBreaker(clock, log, WithBreakerMinRequests(2))
+	calls := 0
+	r := New[int](WithMaxAttempts(5), WithBackoff(Constant(0)), WithCircuitBreaker(cb))
+	result, err := r.Do(context.Background(), func(context.Context) (int, error) {
+		calls++
+		return calls, errBoom
+	})
+	// 前 2 次失败打开熔断器,第 3 次尝试被拒绝,Do 立即返回。
+	if calls != 2 {
+		t.Errorf("calls = %d, want 2", calls)
+	}
+	if !errors.Is(err, ErrCircuitOpen) || !errors.Is(err, errBoom) {
+		t.Errorf("err = %v, want ErrCircuitOpen wrapping last error", err)
+	}
+	if result != 2 {
+		t.Errorf("result = %d, want last attempt's result 2", result)
+	}
+
+	// 已打开时首次尝试即被拒绝:返回零值与裸 ErrCircuitOpen。
+	result, err = r.Do(context.Background(), func(context.Context) (int, error) { return 1, nil })
+	if !errors.Is(err, ErrCircuitOpen) || result != 0 {
+		t.Errorf("Do() = %d, %v; want 0, ErrCircuitOpen", result, err)
+	}
+}
+
+func TestWithCircuitBreaker_NilIgnored(t *testing.T) {
+	r := New[int](WithCircuitBreaker(nil))
+	if got, err := r.Do(context.Background(), func(context.Context) (int, error) { return 1, nil }); err != nil || got != 1 {
+		t.Errorf("Do() = %d, %v; want 1, nil", got, err)
+	}
+}
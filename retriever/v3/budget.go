@@ -0,0 +1,150 @@
+package retriever
+
+import (
+	"context"
+	"errors"
+	"sync"
+
+	"github.com/tenz-io/gokit/monitor/v3"
+)
+
+// ErrRetryBudgetExhausted 在重试预算耗尽、[Retriever.Do] 放弃本应进行的重试
+// 时,作为包裹错误返回,内层为最后一次失败的 error。
+var ErrRetryBudgetExhausted = errors.New("retriever: retry budget exhausted")
+
+// BudgetOption 在构造期配置一个 [RetryBudget]。
+type BudgetOption func(*budgetOptions)
+
+type budgetOptions struct {
+	ratio     float64
+	maxTokens float64
+}
+
+func defaultBudgetOptions() budgetOptions {
+	return budgetOptions{
+		ratio:     0.1,
+		maxTokens: 100,
+	}
+}
+
+// WithBudgetRatio 设置每次成功调用补充的令牌数,即长期来看重试数与成功
+// 调用数之比的上限:0.1 表示重试不超过成功调用的 10%。非正数被忽略
+// (默认 0.1)。
+func WithBudgetRatio(ratio float64) BudgetOption {
+	return func(o *budgetOptions) {
+		if ratio > 0 {
+			o.ratio = ratio
+		}
+	}
+}
+
+// WithBudgetMaxTokens 设置令牌上限,即预算允许的最大突发重试数;预算以满
+// 桶起步。小于 1 的值被忽略(默认 100)。
+func WithBudgetMaxTokens(n float64) BudgetOption {
+	return func(o *budgetOptions) {
+		if n >= 1 {
+			o.maxTokens = n
+		}
+	}
+}
+
+// BudgetStats 是 [RetryBudget] 的状态快照。
+type BudgetStats struct {
+	Tokens    float64 // 当前可用令牌
+	MaxTokens float64 // 令牌上限
+	Successes uint64  // 累计补充过令牌的成功调用数
+	Retries   uint64  // 累计获批的重试数
+	Rejected  uint64  // 累计因预算耗尽被拒的重试数
+}
+
+// RetryBudget 是一个在多个 [Retriever] 之间共享的重试预算,用于防止故障
+// 期间的重试风暴:每次重试消耗 1 枚令牌,每次成功调用补充 ratio 枚,令牌
+// 数封顶于上限。依赖健康时成功调用不断补充,重试畅通;故障期间没有成功
+// 补充,令牌耗尽后所有 Retriever 退化为单次尝试,而不是把负载放大数倍。
+//
+// 同一个 RetryBudget 应在访问同一依赖的所有 Retriever 之间共享(经
+// [WithRetryBudget]);它是并发安全的。用 [RetryBudget.Stats] 读取状态;
+// 令牌数还在每次补充与扣除时经由 monitor.FromContext(ctx) 以 gauge 上报
+// (dsCmd 为预算名,opt "budget_tokens"),被拒的重试以 opt "budget_exhausted" 计数。
+type RetryBudget struct {
+	name string
+	opts budgetOptions
+
+	mu        sync.Mutex
+	tokens    float64
+	successes uint64
+	retries   uint64
+	rejected  uint64
+}
+
+// NewRetryBudget 返回一个满桶起步的 [RetryBudget]。name 标识被保护的依赖,
+// 用于 monitor 的 dsCmd。零个 option 即可用,默认:每次成功补充 0.1 枚、
+// 上限 100 枚。
+func NewRetryBudget(name string, opts ...BudgetOption) *RetryBudget {
+	o := defaultBudgetOptions()
+	for _, opt := range opts {
+		opt(&o)
+	}
+	return &RetryBudget{name: name, opts: o, tokens: o.maxTokens}
+}
+
+// Name 返回预算名。
+func (b *RetryBudget) Name() string { return b.name }
+
+// Stats 返回当前状态快照。
+func (b *RetryBudget) Stats() BudgetStats {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return BudgetStats{
+		Tokens:    b.tokens,
+		MaxTokens: b.opts.maxTokens,
+		Successes: b.successes,
+		Retries:   b.retries,
+		Rejected:  b.rejected,
+	}
+}
+
+// deposit 为一次成功调用补充令牌,并上报补充后的令牌数,使恢复过程在
+// 下一次重试之前就能在监控中看到。
+func (b *RetryBudget) deposit(ctx context.Context) {
+	b.mu.Lock()
+	b.successes++
+	b.tokens = min(b.opts.maxTokens, b.tokens+b.opts.ratio)
+	tokens := b.tokens
+	b.mu.Unlock()
+
+	monitor.FromContext(ctx).Set(ctx, b.name, "0", tokens, "budget_tokens")
+}
+
+// withdraw 为一次重试扣除 1 枚令牌;不足时拒绝并返回 false。
+func (b *RetryBudget) withdraw(ctx context.Context) bool {
+	b.mu.Lock()
+	ok := b.tokens >= 1
+	if ok {
+		b.tokens--
+		b.retries++
+	} else {
+		b.rejected++
+	}
+	tokens := b.tokens
+	b.mu.Unlock()
+
+	exp := monitor.FromContext(ctx)
+	exp.Set(ctx, b.name, "0", tokens, "budget_tokens")
+	if !ok {
+		exp.Count(ctx, b.name, "1", "budget_exhausted")
+	}
+	return ok
+}
+
+// WithRetryBudget 让 [Retriever] 的重试受 b 约束:每次成功调用为 b 补充
+// 令牌,每次重试前从 b 扣除 1 枚;令牌不足时 Do 不再重试,返回用
+// [ErrRetryBudgetExhausted] 包裹的最后一次错误。首次尝试永不受预算限制。
+// nil 被忽略。
+func WithRetryBudget(b *RetryBudget) Option {
+	return func(o *options) {
+		if b != nil {
+			o.budget = b
+		}
+	}
+}
@@ -0,0 +1,165 @@
+package retriever
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"testing"
+
+	"github.com/tenz-io/gokit/monitor/v3"
+)
+
+func TestRetryBudget_Defaults(t *testing.T) {
+	b := NewRetryBudget("dep")
+	st := b.Stats()
+	if st.Tokens != 100 || st.MaxTokens != 100 {
+		t.Errorf("Stats() = %+v, want full bucket of 100", st)
+	}
+	if b.Name() != "dep" {
+		t.Errorf("Name() = %q, want dep", b.Name())
+	}
+}
+
+func TestRetryBudget_OptionsIgnoreInvalid(t *testing.T) {
+	b := NewRetryBudget("dep", WithBudgetRatio(0), WithBudgetMaxTokens(0.5))
+	want := defaultBudgetOptions()
+	if b.opts != want {
+		t.Errorf("opts = %+v, want defaults %+v", b.opts, want)
+	}
+}
+
+func TestRetryBudget_WithdrawAndDeposit(t *testing.T) {
+	b := NewRetryBudget("dep", WithBudgetMaxTokens(2), WithBudgetRatio(0.5))
+	ctx := context.Background()
+	if !b.withdraw(ctx) || !b.withdraw(ctx) {
+		t.Fatal("withdraw() should succeed while tokens remain")
+	}
+	if b.withdraw(ctx) {
+		t.Fatal("withdraw() should fail once exhausted")
+	}
+	// 两次成功补充 1 枚,又可重试一次。
+	b.deposit(ctx)
+	b.deposit(ctx)
+	if !b.withdraw(ctx) {
+		t.Fatal("withdraw() should succeed after deposits refill a token")
+	}
+	st := b.Stats()
+	if st.Retries != 3 || st.Rejected != 1 || st.Successes != 2 {
+		t.Errorf("Stats() = %+v, want 3 retries, 1 rejected, 2 successes", st)
+	}
+}
+
+func TestRetryBudget_DepositCapped(t *testing.T) {
+	b := NewRetryBudget("dep", WithBudgetMaxTokens(3))
+	ctx := context.Background()
+	for i := 0; i < 100; i++ {
+		b.deposit(ctx)
+	}
+	if got := b.Stats().Tokens; got != 3 {
+		t.Errorf("Tokens = %v, want capped at 3", got)
+	}
+}
+
+func TestRetryBudget_GaugeOnDeposit(t *testing.T) {
+	exp := newGaugeExporter()
+	ctx := monitor.WithExporter(context.Background(), exp)
+	b := NewRetryBudget("dep", WithBudgetMaxTokens(2), WithBudgetRatio(0.5))
+	b.withdraw(ctx)
+	b.withdraw(ctx)
+	if got := exp.gauge("dep/budget_tokens"); got != 0 {
+		t.Fatalf("gauge after withdraws = %v, want 0", got)
+	}
+	// 成功调用的补充无需等到下一次重试即可在监控中看到。
+	b.deposit(ctx)
+	if got := exp.gauge("dep/budget_tokens"); got != 0.5 {
+		t.Errorf("gauge after deposit = %v, want 0.5", got)
+	}
+}
+
+func TestDo_RetryBudgetExhaustedDegradesToSingleAttempt(t *testing.T) {
+	b := NewRetryBudget("dep", WithBudgetMaxTokens(1))
+	r := New[int](WithMaxAttempts(3), WithBackoff(Constant(0)), WithRetryBudget(b))
+
+	calls := 0
+	fail := func(context.Context) (int, error) {
+		calls++
+		return calls, errBoom
+	}
+
+	// 第一次 Do:1 枚令牌允许 1 次重试,随后预算耗尽。
+	result, err := r.Do(context.Background(), fail)
+	if calls != 2 {
+		t.Errorf("first Do calls = %d, want 2", calls)
+	}
+	if !errors.Is(err, ErrRetryBudgetExhausted) || !errors.Is(err, errBoom) {
+		t.Errorf("err = %v, want ErrRetryBudgetExhausted wrapping errBoom", err)
+	}
+	if result != 2 {
+		t.Errorf("result = %d, want last result 2", result)
+	}
+
+	// 第二次 Do:预算已空,退化为单次尝试。
+	calls = 0
+	_, err = r.Do(context.Background(), fail)
+	if calls != 1 {
+		t.Errorf("second Do calls = %d, want 1", calls)
+	}
+	if !errors.Is(err, ErrRetryBudgetExhausted) {
+		t.Errorf("err = %v, want ErrRetryBudgetExhausted", err)
+	}
+}
+
+func TestDo_RetryBudgetSharedAcrossRetrievers(t *testing.T) {
+	b := NewRetryBudget("dep", WithBudgetMaxTokens(2), WithBudgetRatio(1))
+	r1 := New[int](WithMaxAttempts(2), WithBackoff(Constant(0)), WithRetryBudget(b))
+	r2 := New[string](WithMaxAttempts(2), WithBackoff(Constant(0)), WithRetryBudget(b))
+
+	_, _ = r1.Do(context.Background(), func(context.Context) (int, error) { return 0, errBoom })
+	_, _ = r2.Do(context.Background(), func(context.Context) (string, error) { return "", errBoom })
+	if got := b.Stats().Tokens; got != 0 {
+		t.Fatalf("Tokens = %v, want 0 after two retries", got)
+	}
+
+	// 成功调用为共享预算补充令牌。
+	_, _ = r2.Do(context.Background(), func(context.Context) (string, error) { return "ok", nil })
+	if got := b.Stats().Tokens; got != 1 {
+		t.Errorf("Tokens = %v, want 1 after a success", got)
+	}
+}
+
+func TestDo_RetryBudgetNotChargedOnLastAttempt(t *testing.T) {
+	b := NewRetryBudget("dep", WithBudgetMaxTokens(5))
+	r := New[int](WithMaxAttempts(1), WithRetryBudget(b))
+	_, _ = r.Do(context.Background(), func(context.Context) (int, error) { return 0, errBoom })
+	if st := b.Stats(); st.Retries != 0 || st.Rejected != 0 || st.Tokens != 5 {
+		t.Errorf("Stats() = %+v, want untouched budget with a single attempt", st)
+	}
+}
+
+func TestRetryBudget_Concurrent(t *testing.T) {
+	b := NewRetryBudget("dep", WithBudgetMaxTokens(50))
+	var wg sync.WaitGroup
+	for i := 0; i < 100; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			_ = b.withdraw(context.Background())
+		}()
+	}
+	wg.Wait()
+	if st := b.Stats(); st.Retries != 50 || st.Rejected != 50 {
+		t.Errorf("Stats() = %+v, want 50 retries and 50 rejected", st)
+	}
+}
+
+func TestWithRetryBudget_NilIgnored(t *testing.T) {
+	r := New[int](WithRetryBudget(nil), WithBackoff(Constant(0)))
+	calls := 0
+	_, _ = r.Do(context.Background(), func(context.Context) (int, error) {
+		calls++
+		return 0, errBoom
+	})
+	if calls != 3 {
+		t.Errorf("calls = %d, want 3 without a budget", calls)
+	}
+}
@@ -0,0 +1,222 @@
+package retriever
+
+import (
+	"container/list"
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/tenz-io/gokit/monitor/v3"
+)
+
+// ErrBulkheadFull 在舱壁的并发名额与等待队列都已占满,或排队超过
+// [WithBulkheadQueueTimeout] 仍未获得名额时返回。被拒绝的调用不会执行 fn;
+// [Retriever.Do] 遇到它会立即返回,不再重试。
+var ErrBulkheadFull = errors.New("retriever: bulkhead is full")
+
+// errBulkheadQueueAbandoned 包装排队期间终止的 ctx.Err():fn 没有被调用,外层
+// [CircuitBreaker] 据此把它当作本地拒绝,而不是依赖超时。
+var errBulkheadQueueAbandoned = errors.New("retriever: bulkhead queue abandoned")
+
+// BulkheadOption 在构造期配置一个 [Bulkhead]。
+type BulkheadOption func(*bulkheadOptions)
+
+type bulkheadOptions struct {
+	maxConcurrent int
+	maxQueue      int
+	queueTimeout  time.Duration
+}
+
+func defaultBulkheadOptions() bulkheadOptions {
+	return bulkheadOptions{
+		maxConcurrent: 10,
+		maxQueue:      0,
+		queueTimeout:  0,
+	}
+}
+
+// WithBulkheadMaxConcurrent 设置同时在途的调用数上限。非正数被忽略
+// (默认 10)。
+func WithBulkheadMaxConcurrent(n int) BulkheadOption {
+	return func(o *bulkheadOptions) {
+		if n > 0 {
+			o.maxConcurrent = n
+		}
+	}
+}
+
+// WithBulkheadMaxQueue 设置名额占满时允许排队等待的调用数上限;队列也满时
+// 新调用立即以 [ErrBulkheadFull] 拒绝。负数被忽略(默认 0,即不排队)。
+func WithBulkheadMaxQueue(n int) BulkheadOption {
+	return func(o *bulkheadOptions) {
+		if n >= 0 {
+			o.maxQueue = n
+		}
+	}
+}
+
+// WithBulkheadQueueTimeout 设置排队等待的上限,超时仍未获得名额即以
+// [ErrBulkheadFull] 拒绝。d<=0 表示不单独限时,只受调用方 ctx 约束
+// (默认)。
+func WithBulkheadQueueTimeout(d time.Duration) BulkheadOption {
+	return func(o *bulkheadOptions) { o.queueTimeout = max(d, 0) }
+}
+
+// Bulkhead 为一个依赖限定并发调用数,使慢依赖只能占满自己的名额,而不是
+// 拖住调用方的全部 goroutine。名额占满时,新调用至多 maxQueue 个按先来
+// 先得排队等待;队列也满、排队超时时以 [ErrBulkheadFull] 拒绝,排队期间
+// ctx 终止则返回包装了 ctx.Err() 的错误(errors.Is 仍可判别)。
+//
+// 用 [WrapBulkhead] 保护任意 [DoFunc],或用 [WithBulkhead] 挂到
+// [Retriever] 上(每次尝试各占一个名额,退避等待期间不占用)。同一个
+// Bulkhead 应在访问同一依赖的所有调用方之间共享;它是并发安全的。
+//
+// 在途数与排队数经由 monitor.FromContext(ctx) 以 gauge 上报(dsCmd 为
+// 舱壁名,opt "bulkhead_in_use"/"bulkhead_queued"),被拒绝的调用以 opt
+// "bulkhead_reject" 计数。
+type Bulkhead struct {
+	name string
+	opts bulkheadOptions
+
+	mu      sync.Mutex
+	inUse   int
+	waiters list.List // 排队者,元素为 chan struct{};名额空出时按先来先得直接移交
+}
+
+// NewBulkhead 返回一个 [Bulkhead]。name 标识被保护的依赖,用于 monitor 的
+// dsCmd。零个 option 即可用,默认:并发上限 10、不排队。
+func NewBulkhead(name string, opts ...BulkheadOption) *Bulkhead {
+	o := defaultBulkheadOptions()
+	for _, opt := range opts {
+		opt(&o)
+	}
+	return &Bulkhead{name: name, opts: o}
+}
+
+// Name 返回舱壁名。
+func (b *Bulkhead) Name() string { return b.name }
+
+// InUse 返回当前在途的调用数。
+func (b *Bulkhead) InUse() int {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return b.inUse
+}
+
+// Queued 返回当前排队等待的调用数。
+func (b *Bulkhead) Queued() int {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return b.waiters.Len()
+}
+
+// acquire 实现 guard:取得名额时返回归还名额的 release;队列已满、排队
+// 超时时返回 [ErrBulkheadFull],排队期间 ctx 终止时返回以
+// errBulkheadQueueAbandoned 包装的 ctx.Err()。
+//
+// 只有队列为空时新调用才能直接占用空闲名额;否则它排到队尾,名额由
+// release 按到达顺序直接移交给队首,新来者无法插队。
+func (b *Bulkhead) acquire(ctx context.Context) (func(error), error) {
+	b.mu.Lock()
+	if b.inUse < b.opts.maxConcurrent && b.waiters.Len() == 0 {
+		b.inUse++
+		b.report(ctx, "bulkhead_in_use", b.inUse)
+		b.mu.Unlock()
+		return b.releaser(ctx), nil
+	}
+	if b.waiters.Len() >= b.opts.maxQueue {
+		b.mu.Unlock()
+		b.reject(ctx)
+		return nil, ErrBulkheadFull
+	}
+	ready := make(chan struct{})
+	elem := b.waiters.PushBack(ready)
+	b.report(ctx, "bulkhead_queued", b.waiters.Len())
+	b.mu.Unlock()
+
+	var timeout <-chan time.Time
+	if b.opts.queueTimeout > 0 {
+		timer := time.NewTimer(b.opts.queueTimeout)
+		defer timer.Stop()
+		timeout = timer.C
+	}
+
+	var err error
+	select {
+	case <-ready:
+		return b.releaser(ctx), nil
+	case <-timeout:
+		err = ErrBulkheadFull
+	case <-ctx.Done():
+		err = fmt.Errorf("%w: %w", errBulkheadQueueAbandoned, ctx.Err())
+	}
+
+	b.mu.Lock()
+	select {
+	case <-ready:
+		// 移交与超时/取消竞争且移交先到:名额已归本调用,转交给下一位。
+		b.mu.Unlock()
+		b.release(ctx)
+	default:
+		b.waiters.Remove(elem)
+		b.report(ctx, "bulkhead_queued", b.waiters.Len())
+		b.mu.Unlock()
+	}
+	if errors.Is(err, ErrBulkheadFull) {
+		b.reject(ctx)
+	}
+	return nil, err
+}
+
+// releaser 返回归还一个已取得名额的 release。
+func (b *Bulkhead) releaser(ctx context.Context) func(error) {
+	return func(error) { b.release(ctx) }
+}
+
+// release 归还一个名额:有排队者时直接移交给队首(在途数不变),否则
+// 在途数减一。
+func (b *Bulkhead) release(ctx context.Context) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	if front := b.waiters.Front(); front != nil {
+		b.waiters.Remove(front)
+		b.report(ctx, "bulkhead_queued", b.waiters.Len())
+		close(front.Value.(chan struct{}))
+		return
+	}
+	b.inUse--
+	b.report(ctx, "bulkhead_in_use", b.inUse)
+}
+
+// report 以 gauge 上报 opt 的当前值。调用方须持锁,使并发上报按计数
+// 变化的顺序到达。
+func (b *Bulkhead) report(ctx context.Context, opt string, n int) {
+	monitor.FromContext(ctx).Set(ctx, b.name, "0", float64(n), opt)
+}
+
+// reject 计数一次拒绝。
+func (b *Bulkhead) reject(ctx context.Context) {
+	monitor.FromContext(ctx).Count(ctx, b.name, "1", "bulkhead_reject")
+}
+
+// WrapBulkhead 返回一个经 b 限流的 [DoFunc]:取得名额后调用 fn,返回时
+// 归还;被拒绝时不调用 fn,直接返回零值与 [ErrBulkheadFull](或包装了
+// 排队期间终止的 ctx.Err() 的错误)。
+func WrapBulkhead[T any](b *Bulkhead, fn DoFunc[T]) DoFunc[T] {
+	return guardFunc(b, fn, nil)
+}
+
+// WithBulkhead 让 [Retriever] 的每次尝试都经过 b:每次尝试各占一个名额,
+// 退避等待期间不占用。b 拒绝时 Do 立即返回 [ErrBulkheadFull](若此前已有
+// 失败尝试,一并包裹其错误,并携带其 result),不再重试。与
+// [WithCircuitBreaker] 一样按传入顺序由外向内嵌套:通常先传熔断器,使
+// 熔断打开时不必占用或等待舱壁名额。nil 被忽略。
+func WithBulkhead(b *Bulkhead) Option {
+	return func(o *options) {
+		if b != nil {
+			o.guards = append(o.guards, b)
+		}
+	}
+}
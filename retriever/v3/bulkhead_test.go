@@ -0,0 +1,422 @@
+package retriever
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/tenz-io/gokit/monitor/v3"
+)
+
+// gaugeExporter 记录 Set 的最新值与 Count 次数,其余方法沿用内嵌的 no-op
+// Exporter。
+type gaugeExporter struct {
+	monitor.Exporter
+	mu     sync.Mutex
+	gauges map[string]float64 // key: dsCmd/opt
+	counts map[string]int     // key: dsCmd/opt
+}
+
+func newGaugeExporter() *gaugeExporter {
+	return &gaugeExporter{
+		Exporter: monitor.FromContext(context.Background()),
+		gauges:   map[string]float64{},
+		counts:   map[string]int{},
+	}
+}
+
+func (e *gaugeExporter) Set(_ context.Context, dsCmd, _ string, val float64, opt string) {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	e.gauges[dsCmd+"/"+opt] = val
+}
+
+func (e *gaugeExporter) Count(_ context.Context, dsCmd, _, opt string) {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	e.counts[dsCmd+"/"+opt]++
+}
+
+func (e *gaugeExporter) gauge(key string) float64 {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	return e.gauges[key]
+}
+
+// occupy 占住 b 的一个名额,直到 release 被关闭。
+func occupy(t *testing.T, ctx context.Context, b *Bulkhead, release <-chan struct{}) <-chan error {
+	t.Helper()
+	done := make(chan error, 1)
+	started := make(chan struct{})
+	go func() {
+		_, err := WrapBulkhead(b, func(context.Context) (int, error) {
+			close(started)
+			<-release
+			return 0, nil
+		})(ctx)
+		done <- err
+	}()
+	<-started
+	return done
+}
+
+// waitFor 轮询直到 cond 成立或超时。
+func waitFor(t *testing.T, cond func() bool) {
+	t.Helper()
+	deadline := time.Now().Add(time.Second)
+	for !cond() {
+		if time.Now().After(deadline) {
+			t.Fatal("condition not met in time")
+		}
+		time.Sleep(time.Millisecond)
+	}
+}
+
+func TestBulkhead_RejectsWhenFullWithoutQueue(t *testing.T) {
+	b := NewBulkhead("dep", WithBulkheadMaxConcurrent(1))
+	release := make(chan struct{})
+	done := occupy(t, context.Background(), b, release)
+
+	called := false
+	_, err := WrapBulkhead(b, func(context.Context) (int, error) {
+		called = true
+		return 0, nil
+	})(context.Background())
+	if !errors.Is(err, ErrBulkheadFull) || called {
+		t.Errorf("err = %v, called = %v; want ErrBulkheadFull without calling fn", err, called)
+	}
+
+	close(release)
+	if err := <-done; err != nil {
+		t.Fatalf("occupying call err = %v", err)
+	}
+	if b.InUse() != 0 {
+		t.Errorf("InUse() = %d, want 0 after release", b.InUse())
+	}
+}
+
+func TestBulkhead_QueuedCallerGetsSlot(t *testing.T) {
+	b := NewBulkhead("dep", WithBulkheadMaxConcurrent(1), WithBulkheadMaxQueue(1))
+	release := make(chan struct{})
+	done := occupy(t, context.Background(), b, release)
+
+	result := make(chan error, 1)
+	go func() {
+		_, err := WrapBulkhead(b, func(context.Context) (int, error) { return 1, nil })(context.Background())
+		result <- err
+	}()
+	waitFor(t, func() bool { return b.Queued() == 1 })
+
+	// 队列已满:第三个调用立即被拒。
+	if _, err := WrapBulkhead(b, func(context.Context) (int, error) { return 0, nil })(context.Background()); !errors.Is(err, ErrBulkheadFull) {
+		t.Errorf("err = %v, want ErrBulkheadFull with a full queue", err)
+	}
+
+	close(release)
+	<-done
+	if err := <-result; err != nil {
+		t.Errorf("queued call err = %v, want nil", err)
+	}
+	if b.Queued() != 0 || b.InUse() != 0 {
+		t.Errorf("Queued() = %d, InUse() = %d; want 0, 0", b.Queued(), b.InUse())
+	}
+}
+
+func TestBulkhead_QueueIsFIFO(t *testing.T) {
+	b := NewBulkhead("dep", WithBulkheadMaxConcurrent(1), WithBulkheadMaxQueue(3))
+	ctx := context.Background()
+	release, err := b.acquire(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// 依次排入 0、1、2 号调用者。
+	admitted := make(chan int, 3)
+	releases := make([]chan struct{}, 3)
+	for i := range releases {
+		releases[i] = make(chan struct{})
+		go func() {
+			rel, err := b.acquire(ctx)
+			if err != nil {
+				t.Error(err)
+				return
+			}
+			admitted <- i
+			<-releases[i]
+			rel(nil)
+		}()
+		waitFor(t, func() bool { return b.Queued() == i+1 })
+	}
+
+	// 名额直接移交给队首:紧接着到达的新来者不能抢到刚空出的名额,只能
+	// 排在 0、1、2 号之后等到超时。
+	release(nil)
+	short, cancel := context.WithTimeout(ctx, 20*time.Millisecond)
+	defer cancel()
+	if _, err := b.acquire(short); !errors.Is(err, context.DeadlineExceeded) {
+		t.Fatalf("newcomer err = %v, want to wait behind the queue", err)
+	}
+	if got := <-admitted; got != 0 {
+		t.Fatalf("first admitted = %d, want 0", got)
+	}
+	if b.InUse() != 1 || b.Queued() != 2 {
+		t.Fatalf("InUse() = %d, Queued() = %d; want the slot handed over", b.InUse(), b.Queued())
+	}
+	for want := 1; want < 3; want++ {
+		close(releases[want-1])
+		if got := <-admitted; got != want {
+			t.Fatalf("admitted = %d, want %d (FIFO)", got, want)
+		}
+	}
+	close(releases[2])
+	waitFor(t, func() bool { return b.InUse() == 0 })
+}
+
+func TestBulkhead_QueueTimeout(t *testing.T) {
+	b := NewBulkhead("dep", WithBulkheadMaxConcurrent(1), WithBulkheadMaxQueue(1),
+		WithBulkheadQueueTimeout(10*time.Millisecond))
+	release := make(chan struct{})
+	defer close(release)
+	occupy(t, context.Background(), b, release)
+
+	start := time.Now()
+	_, err := WrapBulkhead(b, func(context.Context) (int, error) { return 0, nil })(context.Background())
+	if !errors.Is(err, ErrBulkheadFull) {
+		t.Errorf("err = %v, want ErrBulkheadFull after the queue timeout", err)
+	}
+	if elapsed := time.Since(start); elapsed < 10*time.Millisecond {
+		t.Errorf("elapsed = %v, want to have waited in the queue", elapsed)
+	}
+	if b.Queued() != 0 {
+		t.Errorf("Queued() = %d, want 0", b.Queued())
+	}
+}
+
+func TestBulkhead_QueueHonoursContext(t *testing.T) {
+	b := NewBulkhead("dep", WithBulkheadMaxConcurrent(1), WithBulkheadMaxQueue(1))
+	release := make(chan struct{})
+	defer close(release)
+	occupy(t, context.Background(), b, release)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancel()
+	_, err := WrapBulkhead(b, func(context.Context) (int, error) { return 0, nil })(ctx)
+	if !errors.Is(err, context.DeadlineExceeded) {
+		t.Errorf("err = %v, want DeadlineExceeded", err)
+	}
+}
+
+func TestBulkhead_BoundsConcurrency(t *testing.T) {
+	const limit = 3
+	b := NewBulkhead("dep", WithBulkheadMaxConcurrent(limit), WithBulkheadMaxQueue(100))
+	var (
+		mu       sync.Mutex
+		cur, top int
+		wg       sync.WaitGroup
+	)
+	fn := WrapBulkhead(b, func(context.Context) (int, error) {
+		mu.Lock()
+		cur++
+		top = max(top, cur)
+		mu.Unlock()
+		time.Sleep(time.Millisecond)
+		mu.Lock()
+		cur--
+		mu.Unlock()
+		return 0, nil
+	})
+	for i := 0; i < 30; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			if _, err := fn(context.Background()); err != nil {
+				t.Errorf("err = %v", err)
+			}
+		}()
+	}
+	wg.Wait()
+	if top > limit {
+		t.Errorf("max concurrency = %d, want <= %d", top, limit)
+	}
+}
+
+func TestBulkhead_ReleasesOnPanic(t *testing.T) {
+	b := NewBulkhead("dep", WithBulkheadMaxConcurrent(1))
+	func() {
+		defer func() { _ = recover() }()
+		_, _ = WrapBulkhead(b, func(context.Context) (int, error) { panic("boom") })(context.Background())
+	}()
+	if b.InUse() != 0 {
+		t.Errorf("InUse() = %d, want 0 after a panic", b.InUse())
+	}
+	if _, err := WrapBulkhead(b, func(context.Context) (int, error) { return 0, nil })(context.Background()); err != nil {
+		t.Errorf("err = %v, the slot should be free again", err)
+	}
+}
+
+func TestBulkhead_Gauges(t *testing.T) {
+	exp := newGaugeExporter()
+	ctx := monitor.WithExporter(context.Background(), exp)
+	b := NewBulkhead("dep", WithBulkheadMaxConcurrent(1), WithBulkheadMaxQueue(1))
+	release := make(chan struct{})
+	done := occupy(t, ctx, b, release)
+	if got := exp.gauge("dep/bulkhead_in_use"); got != 1 {
+		t.Errorf("in_use gauge = %v, want 1", got)
+	}
+
+	queuedDone := make(chan struct{})
+	go func() {
+		defer close(queuedDone)
+		_, _ = WrapBulkhead(b, func(context.Context) (int, error) { return 0, nil })(ctx)
+	}()
+	waitFor(t, func() bool { return exp.gauge("dep/bulkhead_queued") == 1 })
+	_, _ = WrapBulkhead(b, func(context.Context) (int, error) { return 0, nil })(ctx)
+
+	close(release)
+	<-done
+	<-queuedDone
+	if got := exp.gauge("dep/bulkhead_in_use"); got != 0 {
+		t.Errorf("in_use gauge = %v, want 0", got)
+	}
+	if got := exp.gauge("dep/bulkhead_queued"); got != 0 {
+		t.Errorf("queued gauge = %v, want 0", got)
+	}
+	exp.mu.Lock()
+	defer exp.mu.Unlock()
+	if got := exp.counts["dep/bulkhead_reject"]; got != 1 {
+		t.Errorf("reject count = %d, want 1", got)
+	}
+}
+
+func TestDo_BulkheadFullStopsRetrying(t *testing.T) {
+	b := NewBulkhead("dep", WithBulkheadMaxConcurrent(1))
+	release := make(chan struct{})
+	defer close(release)
+	occupy(t, context.Background(), b, release)
+
+	calls := 0
+	r := New[int](WithBulkhead(b), WithBackoff(Constant(0)))
+	_, err := r.Do(context.Background(), func(context.Context) (int, error) {
+		calls++
+		return 0, nil
+	})
+	if !errors.Is(err, ErrBulkheadFull) || calls != 0 {
+		t.Errorf("err = %v, calls = %d; want ErrBulkheadFull without calling fn", err, calls)
+	}
+}
+
+func TestDo_BulkheadSlotFreedDuringBackoff(t *testing.T) {
+	b := NewBulkhead("dep", WithBulkheadMaxConcurrent(1))
+	calls := 0
+	r := New[int](WithBulkhead(b), WithBackoff(Constant(time.Millisecond)))
+	_, err := r.Do(context.Background(), func(context.Context) (int, error) {
+		calls++
+		if b.InUse() != 1 {
+			t.Errorf("InUse() = %d during an attempt, want 1", b.InUse())
+		}
+		if calls < 3 {
+			return 0, errBoom
+		}
+		return 1, nil
+	})
+	if err != nil || calls != 3 {
+		t.Errorf("err = %v, calls = %d; want success on the third attempt", err, calls)
+	}
+	if b.InUse() != 0 {
+		t.Errorf("InUse() = %d, want 0 after Do", b.InUse())
+	}
+}
+
+func TestDo_BreakerIgnoresBulkheadRejections(t *testing.T) {
+	// 文档推荐的顺序:熔断器在外、舱壁在内。舱壁的本地拒绝不应把熔断器打开。
+	clock, log := newFakeClock(), &transitionLog{}
+	cb := newTestBreaker(clock, log, WithBreakerMinRequests(2))
+	b := NewBulkhead("dep", WithBulkheadMaxConcurrent(1))
+	r := New[int](WithCircuitBreaker(cb), WithBulkhead(b), WithBackoff(Constant(0)))
+	ok := func(context.Context) (int, error) { return 1, nil }
+
+	release := make(chan struct{})
+	done := occupy(t, context.Background(), b, release)
+	for i := 0; i < 5; i++ {
+		if _, err := r.Do(context.Background(), ok); !errors.Is(err, ErrBulkheadFull) {
+			t.Fatalf("Do #%d err = %v, want ErrBulkheadFull", i, err)
+		}
+	}
+	if got := cb.State(); got != StateClosed {
+		t.Fatalf("State() = %v, want closed: local rejections are not failures", got)
+	}
+	close(release)
+	<-done
+	if v, err := r.Do(context.Background(), ok); err != nil || v != 1 {
+		t.Errorf("Do after saturation = (%v, %v), want (1, nil)", v, err)
+	}
+
+	// half-open 时被舱壁拒绝的探测既不关闭也不重新打开熔断器,并归还探测名额。
+	for i := 0; i < 2; i++ {
+		_ = callBreaker(cb, errBoom)
+	}
+	if got := cb.State(); got != StateOpen {
+		t.Fatalf("State() = %v, want open", got)
+	}
+	clock.Advance(time.Second)
+	release = make(chan struct{})
+	done = occupy(t, context.Background(), b, release)
+	if _, err := r.Do(context.Background(), ok); !errors.Is(err, ErrBulkheadFull) {
+		t.Fatalf("probe err = %v, want ErrBulkheadFull", err)
+	}
+	if got := cb.State(); got != StateHalfOpen {
+		t.Fatalf("State() = %v, want half-open after a rejected probe", got)
+	}
+	close(release)
+	<-done
+	if _, err := r.Do(context.Background(), ok); err != nil {
+		t.Errorf("probe after saturation err = %v, want the probe slot returned", err)
+	}
+	if got := cb.State(); got != StateClosed {
+		t.Errorf("State() = %v, want closed after a successful probe", got)
+	}
+}
+
+func TestWrapBreaker_IgnoresQueuedDeadline(t *testing.T) {
+	// 熔断器在外、舱壁在内:调用方的截止时间在排队期间耗尽时 fn 没有执行,
+	// 这次超时不应记入熔断窗口。
+	clock, log := newFakeClock(), &transitionLog{}
+	cb := newTestBreaker(clock, log, WithBreakerMinRequests(1))
+	b := NewBulkhead("dep", WithBulkheadMaxConcurrent(1), WithBulkheadMaxQueue(1))
+	guarded := WrapBreaker(cb, WrapBulkhead(b, func(context.Context) (int, error) { return 1, nil }))
+
+	release := make(chan struct{})
+	defer close(release)
+	occupy(t, context.Background(), b, release)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancel()
+	if _, err := guarded(ctx); !errors.Is(err, context.DeadlineExceeded) {
+		t.Fatalf("err = %v, want DeadlineExceeded", err)
+	}
+	cb.mu.Lock()
+	total, failures := cb.window.totals(clock.Now())
+	cb.mu.Unlock()
+	if total != 0 || failures != 0 {
+		t.Errorf("window = %d total, %d failures; want unchanged", total, failures)
+	}
+	if got := cb.State(); got != StateClosed {
+		t.Errorf("State() = %v, want closed", got)
+	}
+}
+
+func TestBulkhead_OptionsIgnoreInvalid(t *testing.T) {
+	b := NewBulkhead("dep", WithBulkheadMaxConcurrent(0), WithBulkheadMaxQueue(-1), WithBulkheadQueueTimeout(-time.Second))
+	if b.opts != defaultBulkheadOptions() {
+		t.Errorf("opts = %+v, want defaults", b.opts)
+	}
+	if b.Name() != "dep" {
+		t.Errorf("Name() = %q, want dep", b.Name())
+	}
+	r := New[int](WithBulkhead(nil))
+	if _, err := r.Do(context.Background(), func(context.Context) (int, error) { return 0, nil }); err != nil {
+		t.Errorf("WithBulkhead(nil) err = %v", err)
+	}
+}
@@ -0,0 +1,234 @@
+package retriever
+
+import (
+	"context"
+	"database/sql/driver"
+	"errors"
+	"io"
+	"net"
+	"net/http"
+	"strings"
+	"syscall"
+)
+
+// Classifier 判断一个错误是否值得重试,可直接传给 [WithRetryable]。本包
+// 提供的 RetryOn* 函数都是 Classifier,可用 [Any]/[All] 组合,使重试策略
+// 成为声明式配置:
+//
+//	retriever.WithRetryable(retriever.Any(
+//	    retriever.RetryOnNetwork,
+//	    retriever.RetryOnHTTPStatus,
+//	))
+//
+// 所有内置 Classifier 对 nil error 返回 false。
+type Classifier func(err error) bool
+
+// Any 返回一个 Classifier:任一 cs 判定可重试即可重试。nil 项被忽略;
+// 没有 Classifier 时总是返回 false。
+func Any(cs ...Classifier) Classifier {
+	return func(err error) bool {
+		for _, c := range cs {
+			if c != nil && c(err) {
+				return true
+			}
+		}
+		return false
+	}
+}
+
+// All 返回一个 Classifier:全部 cs 都判定可重试才可重试,常用于在宽泛的
+// 分类上再排除一类错误。nil 项被忽略;没有 Classifier 或 err 为 nil 时
+// 返回 false。
+func All(cs ...Classifier) Classifier {
+	return func(err error) bool {
+		if err == nil {
+			return false
+		}
+		matched := false
+		for _, c := range cs {
+			if c == nil {
+				continue
+			}
+			if !c(err) {
+				return false
+			}
+			matched = true
+		}
+		return matched
+	}
+}
+
+// temporary 是部分网络错误仍然实现的 Temporary() 方法;net.Error 已将其
+// 标为废弃,这里单独声明以免依赖废弃 API。
+type temporary interface {
+	Temporary() bool
+}
+
+// RetryOnNetwork 判定瞬时的网络错误可重试:超时([net.Error].Timeout)、
+// 自报 Temporary() 的错误、连接被重置/拒绝/中止、管道断开,以及连接被
+// 提前关闭导致的 [io.EOF]/[io.ErrUnexpectedEOF]。均经由错误链穿透判别。
+// 注意 [context.DeadlineExceeded] 本身实现了 net.Error 且 Timeout() 为
+// true,因此也被判为可重试。
+func RetryOnNetwork(err error) bool {
+	if err == nil {
+		return false
+	}
+	var ne net.Error
+	if errors.As(err, &ne) && ne.Timeout() {
+		return true
+	}
+	var te temporary
+	if errors.As(err, &te) && te.Temporary() {
+		return true
+	}
+	for _, target := range []error{
+		syscall.ECONNRESET,
+		syscall.ECONNREFUSED,
+		syscall.ECONNABORTED,
+		syscall.EPIPE,
+		io.EOF,
+		io.ErrUnexpectedEOF,
+	} {
+		if errors.Is(err, target) {
+			return true
+		}
+	}
+	return false
+}
+
+// httpStatusError 是 [HTTPStatus] 的内部实现,对错误字符串透明。
+type httpStatusError struct {
+	err  error
+	code int
+}
+
+func (e *httpStatusError) Error() string   { return e.err.Error() }
+func (e *httpStatusError) Unwrap() error   { return e.err }
+func (e *httpStatusError) StatusCode() int { return e.code }
+
+// statusCoder 是携带 HTTP 状态码的错误所实现的接口。
+type statusCoder interface {
+	StatusCode() int
+}
+
+// HTTPStatus 为 err 附加 HTTP 状态码 code,供 [RetryOnHTTPStatus] 判别。
+// nil 输入返回 nil;标记是字符串透明的。已自带 StatusCode() int 方法的
+// 错误(如部分 SDK 的错误类型)无需包装。
+//
+//	if resp.StatusCode >= 400 {
+//	    return nil, retriever.HTTPStatus(fmt.Errorf("GET %s: %s", url, resp.Status), resp.StatusCode)
+//	}
+func HTTPStatus(err error, code int) error {
+	if err == nil {
+		return nil
+	}
+	return &httpStatusError{err: err, code: code}
+}
+
+// RetryableStatus 报告 HTTP 状态码 code 是否值得重试:5xx、429 Too Many
+// Requests 与 408 Request Timeout 可重试,其余(含其它 4xx)不可重试。
+func RetryableStatus(code int) bool {
+	switch {
+	case code == http.StatusTooManyRequests, code == http.StatusRequestTimeout:
+		return true
+	case code >= 500 && code <= 599:
+		return true
+	default:
+		return false
+	}
+}
+
+// RetryOnHTTPStatus 按错误链上第一个 HTTP 状态码(见 [HTTPStatus])判定:
+// 状态码满足 [RetryableStatus] 时可重试。链上没有状态码时返回 false。
+func RetryOnHTTPStatus(err error) bool {
+	var sc statusCoder
+	if !errors.As(err, &sc) {
+		return false
+	}
+	return RetryableStatus(sc.StatusCode())
+}
+
+// RetryOnContext 判定下游返回的 [context.DeadlineExceeded] 可重试(下游或
+// 单次调用超时),[context.Canceled] 不可重试(调用被主动放弃)。Do 自身
+// ctx 的终止总是优先返回,不经分类器。
+func RetryOnContext(err error) bool {
+	return errors.Is(err, context.DeadlineExceeded) && !errors.Is(err, context.Canceled)
+}
+
+// sqlStater 是 PostgreSQL 驱动(lib/pq、pgx)错误所实现的 SQLSTATE 访问器。
+type sqlStater interface {
+	SQLState() string
+}
+
+// retryableSQLStates 是值得重试的 SQLSTATE:序列化失败、死锁、锁不可用。
+var retryableSQLStates = map[string]bool{
+	"40001": true, // serialization_failure(MySQL 死锁亦报此码)
+	"40P01": true, // deadlock_detected
+	"55P03": true, // lock_not_available
+}
+
+// retryableSQLMessages 是 MySQL 驱动(其错误类型不暴露方法,只能按文本
+// 识别)中值得重试的错误片段:1213 死锁、1205 锁等待超时。
+var retryableSQLMessages = []string{
+	"Error 1213",
+	"Error 1205",
+	"Deadlock found",
+	"Lock wait timeout exceeded",
+}
+
+// mysqlInvalidConn 是 go-sql-driver/mysql 的 mysql.ErrInvalidConn 的完整文本
+// (连接被服务端关闭或读写中断)。它是一个 errors.New 哨兵,只能按整条消息
+// 识别;只比较错误链上各节点自身的文本,不做子串匹配,以免误判恰好包含该
+// 短语的其他错误。
+const mysqlInvalidConn = "invalid connection"
+
+// RetryOnSQL 判定可重试的数据库错误:连接失效([driver.ErrBadConn]、
+// MySQL 的 mysql.ErrInvalidConn)、死锁、锁等待超时与序列化失败。
+// PostgreSQL 错误按 SQLState() 识别,MySQL 错误按错误号/文本识别,本包
+// 不依赖任何具体驱动。
+func RetryOnSQL(err error) bool {
+	if err == nil {
+		return false
+	}
+	if errors.Is(err, driver.ErrBadConn) {
+		return true
+	}
+	var ss sqlStater
+	if errors.As(err, &ss) && retryableSQLStates[ss.SQLState()] {
+		return true
+	}
+	if chainHasMessage(err, mysqlInvalidConn) {
+		return true
+	}
+	msg := err.Error()
+	for _, m := range retryableSQLMessages {
+		if strings.Contains(msg, m) {
+			return true
+		}
+	}
+	return false
+}
+
+// chainHasMessage 报告 err 的错误链(含 errors.Join 的各分支)上是否有某个
+// 节点的 Error() 恰好等于 msg。
+func chainHasMessage(err error, msg string) bool {
+	for err != nil {
+		if err.Error() == msg {
+			return true
+		}
+		switch u := err.(type) {
+		case interface{ Unwrap() error }:
+			err = u.Unwrap()
+		case interface{ Unwrap() []error }:
+			for _, e := range u.Unwrap() {
+				if chainHasMessage(e, msg) {
+					return true
+				}
+			}
+			return false
+		default:
+			return false
+		}
+	}
+	return false
+}
@@ -0,0 +1,166 @@
+package retriever
+
+import (
+	"context"
+	"database/sql/driver"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"os"
+	"syscall"
+	"testing"
+)
+
+// timeoutErr 模拟一个 net.Error 超时。
+type timeoutErr struct{ timeout bool }
+
+func (e timeoutErr) Error() string   { return "i/o timeout" }
+func (e timeoutErr) Timeout() bool   { return e.timeout }
+func (e timeoutErr) Temporary() bool { return false }
+
+// pgErr 模拟 PostgreSQL 驱动带 SQLState() 的错误。
+type pgErr struct{ code string }
+
+func (e pgErr) Error() string    { return "pq: " + e.code }
+func (e pgErr) SQLState() string { return e.code }
+
+// sdkStatus 模拟自带 StatusCode() 方法的 SDK 错误。
+type sdkStatus struct{ code int }
+
+func (e sdkStatus) Error() string   { return fmt.Sprintf("status %d", e.code) }
+func (e sdkStatus) StatusCode() int { return e.code }
+
+func TestRetryOnNetwork(t *testing.T) {
+	cases := []struct {
+		name string
+		err  error
+		want bool
+	}{
+		{"nil", nil, false},
+		{"timeout", timeoutErr{timeout: true}, true},
+		{"non-timeout net error", timeoutErr{}, false},
+		{"conn reset via OpError", &net.OpError{Op: "read", Err: os.NewSyscallError("read", syscall.ECONNRESET)}, true},
+		{"conn refused", fmt.Errorf("dial: %w", syscall.ECONNREFUSED), true},
+		{"broken pipe", fmt.Errorf("write: %w", syscall.EPIPE), true},
+		{"eof", fmt.Errorf("read body: %w", io.EOF), true},
+		{"unexpected eof", io.ErrUnexpectedEOF, true},
+		{"deadline exceeded", context.DeadlineExceeded, true}, // 标准库的 DeadlineExceeded 实现了 net.Error
+		{"plain", errBoom, false},
+	}
+	for _, c := range cases {
+		if got := RetryOnNetwork(c.err); got != c.want {
+			t.Errorf("%s: RetryOnNetwork(%v) = %v, want %v", c.name, c.err, got, c.want)
+		}
+	}
+}
+
+func TestRetryableStatus(t *testing.T) {
+	for code, want := range map[int]bool{
+		200: false, 400: false, 401: false, 404: false, 409: false,
+		408: true, 429: true, 500: true, 502: true, 503: true, 599: true, 600: false,
+	} {
+		if got := RetryableStatus(code); got != want {
+			t.Errorf("RetryableStatus(%d) = %v, want %v", code, got, want)
+		}
+	}
+}
+
+func TestHTTPStatus(t *testing.T) {
+	if HTTPStatus(nil, 500) != nil {
+		t.Error("HTTPStatus(nil) should be nil")
+	}
+	err := HTTPStatus(errBoom, 503)
+	if err.Error() != errBoom.Error() || !errors.Is(err, errBoom) {
+		t.Errorf("HTTPStatus should be transparent, got %v", err)
+	}
+	if !RetryOnHTTPStatus(fmt.Errorf("call: %w", err)) {
+		t.Error("503 should be retryable through wrapping")
+	}
+	if RetryOnHTTPStatus(HTTPStatus(errBoom, 400)) {
+		t.Error("400 should not be retryable")
+	}
+	if !RetryOnHTTPStatus(sdkStatus{code: 429}) {
+		t.Error("an SDK error with StatusCode() 429 should be retryable")
+	}
+	if RetryOnHTTPStatus(errBoom) {
+		t.Error("an error without a status should not be retryable")
+	}
+}
+
+func TestRetryOnContext(t *testing.T) {
+	if !RetryOnContext(fmt.Errorf("rpc: %w", context.DeadlineExceeded)) {
+		t.Error("DeadlineExceeded should be retryable")
+	}
+	if RetryOnContext(context.Canceled) || RetryOnContext(errBoom) || RetryOnContext(nil) {
+		t.Error("Canceled, plain and nil errors should not be retryable")
+	}
+}
+
+func TestRetryOnSQL(t *testing.T) {
+	cases := []struct {
+		name string
+		err  error
+		want bool
+	}{
+		{"nil", nil, false},
+		{"bad conn", fmt.Errorf("query: %w", driver.ErrBadConn), true},
+		{"pg deadlock", pgErr{code: "40P01"}, true},
+		{"pg serialization", pgErr{code: "40001"}, true},
+		{"pg lock not available", pgErr{code: "55P03"}, true},
+		{"pg unique violation", pgErr{code: "23505"}, false},
+		{"mysql deadlock", errors.New("Error 1213 (40001): Deadlock found when trying to get lock"), true},
+		{"mysql lock wait", errors.New("Error 1205 (HY000): Lock wait timeout exceeded; try restarting transaction"), true},
+		{"mysql invalid conn", fmt.Errorf("exec: %w", errors.New("invalid connection")), true}, // mysql.ErrInvalidConn
+		{"mysql invalid conn joined", errors.Join(errBoom, errors.New("invalid connection")), true},
+		{"phrase in other error", errors.New("validate user: invalid connection string"), false},
+		{"phrase in wrapper only", fmt.Errorf("config: invalid connection: %w", errBoom), false},
+		{"mysql duplicate", errors.New("Error 1062 (23000): Duplicate entry"), false},
+	}
+	for _, c := range cases {
+		if got := RetryOnSQL(c.err); got != c.want {
+			t.Errorf("%s: RetryOnSQL(%v) = %v, want %v", c.name, c.err, got, c.want)
+		}
+	}
+}
+
+func TestAnyAll(t *testing.T) {
+	yes := func(error) bool { return true }
+	no := func(error) bool { return false }
+
+	if !Any(no, yes)(errBoom) || Any(no, no)(errBoom) || Any()(errBoom) {
+		t.Error("Any should report true iff some classifier does")
+	}
+	if Any(nil, yes)(errBoom) != true {
+		t.Error("Any should skip nil classifiers")
+	}
+	if !All(yes, yes)(errBoom) || All(yes, no)(errBoom) || All()(errBoom) || All(nil)(errBoom) {
+		t.Error("All should report true iff every (non-nil) classifier does")
+	}
+	if All(yes)(nil) {
+		t.Error("All should report false for a nil error")
+	}
+}
+
+func TestDo_WithBuiltinClassifiers(t *testing.T) {
+	policy := WithRetryable(Any(RetryOnNetwork, RetryOnHTTPStatus))
+
+	calls := 0
+	r := New[int](policy, WithBackoff(Constant(0)))
+	_, err := r.Do(context.Background(), func(context.Context) (int, error) {
+		calls++
+		return 0, HTTPStatus(errBoom, 400)
+	})
+	if calls != 1 || !errors.Is(err, errBoom) {
+		t.Errorf("400: calls = %d, err = %v; want 1 call, no retry", calls, err)
+	}
+
+	calls = 0
+	_, _ = r.Do(context.Background(), func(context.Context) (int, error) {
+		calls++
+		return 0, fmt.Errorf("read: %w", syscall.ECONNRESET)
+	})
+	if calls != 3 {
+		t.Errorf("ECONNRESET: calls = %d, want 3", calls)
+	}
+}
@@ -0,0 +1,121 @@
+package retriever
+
+import (
+	"context"
+	"time"
+
+	"github.com/tenz-io/gokit/logger/v3"
+	"github.com/tenz-io/gokit/monitor/v3"
+	"github.com/tenz-io/gokit/tracer/v3"
+)
+
+// AttemptInfo 描述 [Retriever.Do] 中一次重试、成功或放弃时的现场,传给 [Hook]。
+type AttemptInfo struct {
+	// Attempt 为已执行的尝试次数,从 1 开始。OnRetry 中即刚失败的尝试序号;
+	// OnSuccess 中为成功前 fn 被调用的次数(含成功的那次);OnGiveUp 中为放弃
+	// 前 fn 实际被调用的次数(首次尝试即被熔断拒绝时为 0)。
+	Attempt int
+	// Err 在 OnRetry 中为刚失败的尝试的错误;在 OnGiveUp 中为 Do 最终返回的
+	// error;OnSuccess 中恒为 nil。
+	Err error
+	// Delay 为下一次尝试前将要等待的时长(已计入 [RetryAfter] 的建议);
+	// OnSuccess 与 OnGiveUp 中恒为 0。
+	Delay time.Duration
+	// Elapsed 为自 Do 开始以来的耗时。
+	Elapsed time.Duration
+}
+
+// Hook 在 [Retriever.Do] 决定重试、成功返回或放弃时被同步调用,ctx 为 Do 的 ctx
+// (已叠加 [WithTimeout])。Hook 应当轻量且不阻塞:它运行在重试路径上。
+type Hook func(ctx context.Context, info AttemptInfo)
+
+// WithOnRetry 追加一个重试钩子:每次确定要重试(已通过分类器、重试预算等
+// 判定)、进入等待之前调用。可多次传入,按传入顺序调用;nil 被忽略。
+func WithOnRetry(h Hook) Option {
+	return func(o *options) {
+		if h != nil {
+			o.onRetry = append(o.onRetry, h)
+		}
+	}
+}
+
+// WithOnSuccess 追加一个成功钩子:Do 以 nil error 返回前调用,不论此前重试
+// 了几次。可多次传入,按传入顺序调用;nil 被忽略。
+func WithOnSuccess(h Hook) Option {
+	return func(o *options) {
+		if h != nil {
+			o.onSuccess = append(o.onSuccess, h)
+		}
+	}
+}
+
+// WithOnGiveUp 追加一个放弃钩子:Do 以非 nil error 返回前调用,覆盖
+// 所有失败终止路径(不可重试、尝试耗尽、ctx 终止、熔断拒绝、预算耗尽等),
+// 参数校验失败(nil fn/ctx)除外。可多次传入,按传入顺序调用;nil 被忽略。
+func WithOnGiveUp(h Hook) Option {
+	return func(o *options) {
+		if h != nil {
+			o.onGiveUp = append(o.onGiveUp, h)
+		}
+	}
+}
+
+// WithLogHooks 注册内置的日志钩子:经 logger.FromContext(ctx) 在每次重试时
+// 记一条 warn、在放弃时记一条 error,字段含 name、尝试序号、错误、等待时长
+// 与耗时;ctx 携带 request ID 时一并记录。name 标识被调用的依赖。
+func WithLogHooks(name string) Option {
+	return func(o *options) {
+		o.onRetry = append(o.onRetry, func(ctx context.Context, info AttemptInfo) {
+			hookLogger(ctx).Warnw("retriever: retrying",
+				"name", name,
+				"attempt", info.Attempt,
+				"error", info.Err,
+				"delay", info.Delay,
+				"elapsed", info.Elapsed,
+			)
+		})
+		o.onGiveUp = append(o.onGiveUp, func(ctx context.Context, info AttemptInfo) {
+			hookLogger(ctx).Errorw("retriever: giving up",
+				"name", name,
+				"attempt", info.Attempt,
+				"error", info.Err,
+				"elapsed", info.Elapsed,
+			)
+		})
+	}
+}
+
+// WithMonitorHooks 注册内置的计数钩子:经 monitor.FromContext(ctx),以 name
+// 为 dsCmd,每次重试计数 opt "retry",每次成功返回计数 opt "success",每次
+// 放弃计数 opt "giveup"。success 与 giveup 之和即 Do 的调用总数;对
+// retry 与该总数的比值设告警,即可感知依赖的重试率上升。
+func WithMonitorHooks(name string) Option {
+	return func(o *options) {
+		o.onRetry = append(o.onRetry, func(ctx context.Context, _ AttemptInfo) {
+			monitor.FromContext(ctx).Count(ctx, name, "1", "retry")
+		})
+		o.onSuccess = append(o.onSuccess, func(ctx context.Context, _ AttemptInfo) {
+			monitor.FromContext(ctx).Count(ctx, name, "1", "success")
+		})
+		o.onGiveUp = append(o.onGiveUp, func(ctx context.Context, _ AttemptInfo) {
+			monitor.FromContext(ctx).Count(ctx, name, "1", "giveup")
+		})
+	}
+}
+
+// hookLogger 返回 ctx 上的 logger,ctx 携带 request ID 时绑定它。缺失时
+// 不追加该 field,避免记下一个与请求无关的生成 id。
+func hookLogger(ctx context.Context) logger.Entry {
+	entry := logger.FromContext(ctx)
+	if id := tracer.RequestIDFromCtxOr(ctx); id != "" {
+		entry = entry.WithRequestID(id)
+	}
+	return entry
+}
+
+// fire 依次调用 hooks。
+func fire(ctx context.Context, hooks []Hook, info AttemptInfo) {
+	for _, h := range hooks {
+		h(ctx, info)
+	}
+}
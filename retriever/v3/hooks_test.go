@@ -0,0 +1,260 @@
+package retriever
+
+import (
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/tenz-io/gokit/logger/v3"
+	"github.com/tenz-io/gokit/monitor/v3"
+	"github.com/tenz-io/gokit/tracer/v3"
+)
+
+// hookRecorder 记录钩子收到的 AttemptInfo。
+type hookRecorder struct {
+	retries   []AttemptInfo
+	successes []AttemptInfo
+	giveUps   []AttemptInfo
+}
+
+func (h *hookRecorder) options() []Option {
+	return []Option{
+		WithOnRetry(func(_ context.Context, info AttemptInfo) { h.retries = append(h.retries, info) }),
+		WithOnSuccess(func(_ context.Context, info AttemptInfo) { h.successes = append(h.successes, info) }),
+		WithOnGiveUp(func(_ context.Context, info AttemptInfo) { h.giveUps = append(h.giveUps, info) }),
+	}
+}
+
+// countingExporter 记录 Count 调用,其余方法沿用内嵌的 no-op Exporter。
+type countingExporter struct {
+	monitor.Exporter
+	mu     sync.Mutex
+	counts map[string]int // key: dsCmd/code/opt
+}
+
+func (e *countingExporter) Count(_ context.Context, dsCmd, code, opt string) {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	e.counts[dsCmd+"/"+code+"/"+opt]++
+}
+
+func TestHooks_RetryThenSuccess(t *testing.T) {
+	var h hookRecorder
+	calls := 0
+	r := New[int](append(h.options(), WithBackoff(Constant(time.Millisecond)))...)
+	_, err := r.Do(context.Background(), func(context.Context) (int, error) {
+		calls++
+		if calls < 3 {
+			return 0, errBoom
+		}
+		return 1, nil
+	})
+	if err != nil {
+		t.Fatalf("Do() err = %v", err)
+	}
+	if len(h.retries) != 2 || len(h.giveUps) != 0 {
+		t.Fatalf("retries = %d, giveUps = %d; want 2, 0", len(h.retries), len(h.giveUps))
+	}
+	for i, info := range h.retries {
+		if info.Attempt != i+1 || !errors.Is(info.Err, errBoom) || info.Delay != time.Millisecond {
+			t.Errorf("retries[%d] = %+v", i, info)
+		}
+	}
+	if h.retries[1].Elapsed < time.Millisecond {
+		t.Errorf("Elapsed = %v, want at least one backoff", h.retries[1].Elapsed)
+	}
+	if len(h.successes) != 1 {
+		t.Fatalf("successes = %d, want 1", len(h.successes))
+	}
+	if got := h.successes[0]; got.Attempt != 3 || got.Err != nil || got.Delay != 0 || got.Elapsed < 2*time.Millisecond {
+		t.Errorf("successes[0] = %+v, want attempt 3 after two backoffs", got)
+	}
+}
+
+func TestHooks_DelayReflectsRetryAfter(t *testing.T) {
+	var h hookRecorder
+	r := New[int](append(h.options(), WithMaxAttempts(2), WithBackoff(Constant(time.Hour)))...)
+	_, _ = r.Do(context.Background(), func(context.Context) (int, error) {
+		return 0, RetryAfter(errBoom, time.Millisecond)
+	})
+	if len(h.retries) != 1 || h.retries[0].Delay != time.Millisecond {
+		t.Errorf("retries = %+v, want one with the suggested 1ms delay", h.retries)
+	}
+}
+
+func TestHooks_GiveUpPaths(t *testing.T) {
+	cases := []struct {
+		name        string
+		opts        []Option
+		fn          DoFunc[int]
+		wantAttempt int
+		wantErr     error
+		wantRetries int
+	}{
+		{
+			name:        "max attempts",
+			opts:        []Option{WithBackoff(Constant(0))},
+			fn:          func(context.Context) (int, error) { return 0, errBoom },
+			wantAttempt: 3,
+			wantErr:     ErrMaxAttempts,
+			wantRetries: 2,
+		},
+		{
+			name:        "non-retryable",
+			fn:          func(context.Context) (int, error) { return 0, NonRetryable(errPerm) },
+			wantAttempt: 1,
+			wantErr:     ErrNonRetryable,
+		},
+		{
+			name:        "classifier",
+			opts:        []Option{WithRetryable(func(error) bool { return false })},
+			fn:          func(context.Context) (int, error) { return 0, errBoom },
+			wantAttempt: 1,
+			wantErr:     errBoom,
+		},
+		{
+			name:        "budget exhausted",
+			opts:        []Option{WithRetryBudget(NewRetryBudget("dep", WithBudgetMaxTokens(1))), WithBackoff(Constant(0))},
+			fn:          func(context.Context) (int, error) { return 0, errBoom },
+			wantAttempt: 2,
+			wantErr:     ErrRetryBudgetExhausted,
+			wantRetries: 1,
+		},
+	}
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			var h hookRecorder
+			r := New[int](append(h.options(), c.opts...)...)
+			_, err := r.Do(context.Background(), c.fn)
+			if len(h.giveUps) != 1 {
+				t.Fatalf("giveUps = %d, want 1", len(h.giveUps))
+			}
+			got := h.giveUps[0]
+			if got.Attempt != c.wantAttempt || got.Err != err || !errors.Is(got.Err, c.wantErr) || got.Delay != 0 {
+				t.Errorf("giveUp = %+v, want attempt %d with the returned error", got, c.wantAttempt)
+			}
+			if len(h.retries) != c.wantRetries {
+				t.Errorf("retries = %d, want %d", len(h.retries), c.wantRetries)
+			}
+		})
+	}
+}
+
+func TestHooks_GiveUpOnBreakerRejectCountsNoAttempt(t *testing.T) {
+	cb := NewCircuitBreaker("dep", WithBreakerMinRequests(1))
+	_, _ = WrapBreaker(cb, func(context.Context) (int, error) { return 0, errBoom })(context.Background())
+
+	var h hookRecorder
+	calls := 0
+	r := New[int](append(h.options(), WithCircuitBreaker(cb))...)
+	_, err := r.Do(context.Background(), func(context.Context) (int, error) {
+		calls++
+		return 0, nil
+	})
+	if !errors.Is(err, ErrCircuitOpen) || calls != 0 {
+		t.Fatalf("err = %v, calls = %d; want ErrCircuitOpen without calling fn", err, calls)
+	}
+	if len(h.giveUps) != 1 || h.giveUps[0].Attempt != 0 {
+		t.Errorf("giveUps = %+v, want one with Attempt 0", h.giveUps)
+	}
+}
+
+func TestHooks_GiveUpOnContextCancel(t *testing.T) {
+	var h hookRecorder
+	ctx, cancel := context.WithCancel(context.Background())
+	r := New[int](append(h.options(), WithBackoff(Constant(time.Hour)))...)
+	_, err := r.Do(ctx, func(context.Context) (int, error) {
+		cancel()
+		return 0, errBoom
+	})
+	if !errors.Is(err, context.Canceled) {
+		t.Fatalf("err = %v, want Canceled", err)
+	}
+	if len(h.giveUps) != 1 || h.giveUps[0].Attempt != 1 {
+		t.Errorf("giveUps = %+v, want one with Attempt 1", h.giveUps)
+	}
+}
+
+func TestHooks_NotFiredOnInvalidArgs(t *testing.T) {
+	var h hookRecorder
+	r := New[int](h.options()...)
+	_, _ = r.Do(context.Background(), nil)
+	if len(h.giveUps) != 0 {
+		t.Errorf("giveUps = %d, want 0 for a nil fn", len(h.giveUps))
+	}
+}
+
+func TestHooks_NilIgnoredAndOrdered(t *testing.T) {
+	var order []int
+	r := New[int](
+		WithOnGiveUp(nil),
+		WithOnRetry(nil),
+		WithOnSuccess(nil),
+		WithOnGiveUp(func(context.Context, AttemptInfo) { order = append(order, 1) }),
+		WithOnGiveUp(func(context.Context, AttemptInfo) { order = append(order, 2) }),
+		WithMaxAttempts(1),
+	)
+	_, _ = r.Do(context.Background(), func(context.Context) (int, error) { return 0, errBoom })
+	if len(order) != 2 || order[0] != 1 || order[1] != 2 {
+		t.Errorf("order = %v, want [1 2]", order)
+	}
+}
+
+func TestWithMonitorHooks_Counts(t *testing.T) {
+	exp := &countingExporter{Exporter: monitor.FromContext(context.Background()), counts: map[string]int{}}
+	ctx := monitor.WithExporter(context.Background(), exp)
+	r := New[int](WithMonitorHooks("payments"), WithBackoff(Constant(0)))
+	_, _ = r.Do(ctx, func(context.Context) (int, error) { return 0, errBoom })
+	calls := 0
+	_, _ = r.Do(ctx, func(context.Context) (int, error) {
+		calls++
+		if calls < 2 {
+			return 0, errBoom
+		}
+		return 1, nil
+	})
+	// 两次 Do:一次放弃、一次成功,共 3 次重试 —— 重试率 = retry/(success+giveup)。
+	if got := exp.counts["payments/1/retry"]; got != 3 {
+		t.Errorf("retry count = %d, want 3", got)
+	}
+	if got := exp.counts["payments/1/success"]; got != 1 {
+		t.Errorf("success count = %d, want 1", got)
+	}
+	if got := exp.counts["payments/1/giveup"]; got != 1 {
+		t.Errorf("giveup count = %d, want 1", got)
+	}
+}
+
+func TestWithLogHooks_LogsWithRequestID(t *testing.T) {
+	dir := t.TempDir()
+	entry := logger.NewEntryWithOpts(
+		logger.WithConsole(false),
+		logger.WithFilePath(dir),
+		logger.WithEncoding(logger.JSONEncoding),
+	)
+	ctx := tracer.WithRequestID(context.Background(), "req-retry")
+	ctx = logger.WithLogger(ctx, entry)
+
+	r := New[int](WithLogHooks("payments"), WithMaxAttempts(2), WithBackoff(Constant(0)))
+	_, _ = r.Do(ctx, func(context.Context) (int, error) { return 0, errBoom })
+
+	// error 级别的放弃日志写入 error.log;等待异步落盘。
+	path := filepath.Join(dir, "error.log")
+	deadline := time.Now().Add(time.Second)
+	for {
+		b, _ := os.ReadFile(path)
+		s := string(b)
+		if strings.Contains(s, "req-retry") && strings.Contains(s, "giving up") && strings.Contains(s, "payments") {
+			return
+		}
+		if time.Now().After(deadline) {
+			t.Fatalf("error.log = %q, want give-up entry with request ID", s)
+		}
+		time.Sleep(5 * time.Millisecond)
+	}
+}
@@ -0,0 +1,268 @@
+package retriever
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"maps"
+	"slices"
+	"sync"
+	"time"
+
+	"github.com/tenz-io/gokit/annotation/v3"
+)
+
+// ErrUnknownPolicy 在 [Registry.Options] 查询未注册的策略名时返回。
+var ErrUnknownPolicy = errors.New("retriever: unknown policy")
+
+// Policy 是可从配置文件加载的声明式重试策略,字段带 yaml/json tag 与
+// annotation/v3 的 default/validate tag。零值字段在 [Policy.Options] 中
+// 取默认值,因此配置里只需写出与默认不同的项:
+//
+//	payments:
+//	  max_attempts: 4
+//	  backoff: exponential
+//	  base_delay: 200ms
+//	  retry_on: [network, http]
+//
+// 时长字段在 yaml 与 json 中都写作 [time.ParseDuration] 格式的字符串,如
+// "200ms"/"1s";json 输入为兼容旧配置也接受纳秒整数。
+type Policy struct {
+	// MaxAttempts 是含首次调用的最大尝试次数,见 [WithMaxAttempts]。
+	MaxAttempts int `json:"max_attempts" yaml:"max_attempts" default:"3" validate:"gt=0"`
+	// Timeout 是全部尝试共享的截止时间,0 表示不设,见 [WithTimeout]。
+	Timeout time.Duration `json:"timeout" yaml:"timeout" validate:"gte=0"`
+	// AttemptTimeout 是单次尝试的超时,0 表示不设,见 [WithAttemptTimeout]。
+	AttemptTimeout time.Duration `json:"attempt_timeout" yaml:"attempt_timeout" validate:"gte=0"`
+	// Backoff 选择退避策略:constant、linear、exponential、fibonacci 或
+	// decorrelated。
+	Backoff string `json:"backoff" yaml:"backoff" default:"exponential" validate:"oneof=constant linear exponential fibonacci decorrelated"`
+	// BaseDelay 是退避的基准时长:constant 的固定等待、linear/exponential/
+	// fibonacci/decorrelated 的 Base。
+	BaseDelay time.Duration `json:"base_delay" yaml:"base_delay" default:"100ms" validate:"gte=0"`
+	// Step 是 linear 每次失败增加的时长,其余策略忽略。
+	Step time.Duration `json:"step" yaml:"step" default:"100ms" validate:"gte=0"`
+	// Factor 是 exponential 的倍数,其余策略忽略。
+	Factor float64 `json:"factor" yaml:"factor" default:"2" validate:"gt=0"`
+	// Jitter 选择抖动方式:none、additive、full 或 equal,见 [JitterMode]。
+	// decorrelated 自带随机性,忽略此项。
+	Jitter string `json:"jitter" yaml:"jitter" default:"none" validate:"oneof=none additive full equal"`
+	// JitterFactor 是 additive 抖动的比例,见 [Jitter]。Jitter 为 additive 时
+	// 必须大于 0,否则 [Policy.Options] 返回错误;其余方式忽略此项。
+	JitterFactor float64 `json:"jitter_factor" yaml:"jitter_factor" validate:"gte=0"`
+	// MaxDelay 是单次等待的上限,0 表示不设,见 [Limit] 的 Cap。
+	MaxDelay time.Duration `json:"max_delay" yaml:"max_delay" validate:"gte=0"`
+	// MaxElapsed 是自 Do 开始的累计耗时上限,超出后不再重试,0 表示不设,
+	// 见 [Limit] 的 MaxElapsed。
+	MaxElapsed time.Duration `json:"max_elapsed" yaml:"max_elapsed" validate:"gte=0"`
+	// MaxRetryAfter 是服务端建议延迟的上限,0 取默认 30s,见 [WithMaxRetryAfter]。
+	MaxRetryAfter time.Duration `json:"max_retry_after" yaml:"max_retry_after" validate:"gte=0"`
+	// RetryOn 列出值得重试的错误类别:network、http、context、sql,分别对应
+	// [RetryOnNetwork]、[RetryOnHTTPStatus]、[RetryOnContext]、[RetryOnSQL],
+	// 以 [Any] 组合。为空时对所有错误重试。
+	RetryOn []string `json:"retry_on" yaml:"retry_on" validate:"dive:oneof=network http context sql"`
+}
+
+// MarshalJSON 实现 [json.Marshaler],时长字段编码为 "200ms" 形式的字符串。
+func (p Policy) MarshalJSON() ([]byte, error) {
+	return json.Marshal(p.jsonView())
+}
+
+// UnmarshalJSON 实现 [json.Unmarshaler]。时长字段接受 "200ms" 形式的字符串
+// 或纳秒整数。
+func (p *Policy) UnmarshalJSON(data []byte) error {
+	return json.Unmarshal(data, p.jsonView())
+}
+
+// jsonView 返回 p 的 json 编解码视图:以 [jsonDuration] 覆盖各时长字段,其余
+// 字段沿用 Policy 自身的 tag。视图中的指针指向 p 的字段,解码直接写回 p。
+func (p *Policy) jsonView() any {
+	type plain Policy // 去掉方法,避免递归
+	return &struct {
+		*plain
+		Timeout        *jsonDuration `json:"timeout"`
+		AttemptTimeout *jsonDuration `json:"attempt_timeout"`
+		BaseDelay      *jsonDuration `json:"base_delay"`
+		Step           *jsonDuration `json:"step"`
+		MaxDelay       *jsonDuration `json:"max_delay"`
+		MaxElapsed     *jsonDuration `json:"max_elapsed"`
+		MaxRetryAfter  *jsonDuration `json:"max_retry_after"`
+	}{
+		plain:          (*plain)(p),
+		Timeout:        (*jsonDuration)(&p.Timeout),
+		AttemptTimeout: (*jsonDuration)(&p.AttemptTimeout),
+		BaseDelay:      (*jsonDuration)(&p.BaseDelay),
+		Step:           (*jsonDuration)(&p.Step),
+		MaxDelay:       (*jsonDuration)(&p.MaxDelay),
+		MaxElapsed:     (*jsonDuration)(&p.MaxElapsed),
+		MaxRetryAfter:  (*jsonDuration)(&p.MaxRetryAfter),
+	}
+}
+
+// jsonDuration 是 json 中以 [time.Duration.String] 字符串表示的时长。
+type jsonDuration time.Duration
+
+// MarshalJSON 实现 [json.Marshaler]。
+func (d jsonDuration) MarshalJSON() ([]byte, error) {
+	return json.Marshal(time.Duration(d).String())
+}
+
+// UnmarshalJSON 实现 [json.Unmarshaler]:接受 [time.ParseDuration] 字符串或
+// 纳秒整数;null 不修改 d。
+func (d *jsonDuration) UnmarshalJSON(data []byte) error {
+	if bytes.Equal(data, []byte("null")) {
+		return nil
+	}
+	if len(data) > 0 && data[0] == '"' {
+		var s string
+		if err := json.Unmarshal(data, &s); err != nil {
+			return err
+		}
+		v, err := time.ParseDuration(s)
+		if err != nil {
+			return fmt.Errorf("retriever: invalid duration %q: %w", s, err)
+		}
+		*d = jsonDuration(v)
+		return nil
+	}
+	var n int64
+	if err := json.Unmarshal(data, &n); err != nil {
+		return fmt.Errorf("retriever: invalid duration %s: want a string like \"200ms\" or integer nanoseconds", data)
+	}
+	*d = jsonDuration(n)
+	return nil
+}
+
+// retryClasses 是 [Policy].RetryOn 的可选类别。
+var retryClasses = map[string]Classifier{
+	"network": RetryOnNetwork,
+	"http":    RetryOnHTTPStatus,
+	"context": RetryOnContext,
+	"sql":     RetryOnSQL,
+}
+
+// jitterModes 是 [Policy].Jitter 除 none 外的可选值。
+var jitterModes = map[string]JitterMode{
+	"additive": JitterAdditive,
+	"full":     JitterFull,
+	"equal":    JitterEqual,
+}
+
+// Options 为 p 填入默认值、校验后转换为 [Option] 列表,可直接传给 [New]
+// 并在其后追加熔断器、重试预算等运行期对象。p 本身不被修改。校验失败时
+// 返回的 error 可用 annotation.AsErrors 取出逐字段的原因。
+func (p Policy) Options() ([]Option, error) {
+	if err := annotation.ApplyDefaults(&p); err != nil {
+		return nil, fmt.Errorf("retriever: policy defaults: %w", err)
+	}
+	if err := annotation.Validate(&p); err != nil {
+		return nil, fmt.Errorf("retriever: invalid policy: %w", err)
+	}
+	// Factor 为 0 的 additive 抖动等同于不抖动:配置要了抖动却得不到,按错误报告。
+	if p.Jitter == "additive" && p.JitterFactor <= 0 && p.Backoff != "decorrelated" {
+		err := annotation.Err("JitterFactor", "gt", "must be > 0 when jitter is additive")
+		return nil, fmt.Errorf("retriever: invalid policy: %w", err)
+	}
+
+	opts := []Option{
+		WithMaxAttempts(p.MaxAttempts),
+		WithTimeout(p.Timeout),
+		WithAttemptTimeout(p.AttemptTimeout),
+		WithBackoff(p.backoff()),
+	}
+	if p.MaxRetryAfter > 0 {
+		opts = append(opts, WithMaxRetryAfter(p.MaxRetryAfter))
+	}
+	if len(p.RetryOn) > 0 {
+		cs := make([]Classifier, 0, len(p.RetryOn))
+		for _, class := range p.RetryOn {
+			cs = append(cs, retryClasses[class])
+		}
+		opts = append(opts, WithRetryable(Any(cs...)))
+	}
+	return opts, nil
+}
+
+// backoff 按已校验的 p 组装 [Backoff]:基础策略,按需叠加 [Jitter],再按需
+// 以 [Limit] 限制。
+func (p Policy) backoff() Backoff {
+	var b Backoff
+	switch p.Backoff {
+	case "constant":
+		b = Constant(p.BaseDelay)
+	case "linear":
+		b = Linear{Base: p.BaseDelay, Step: p.Step}
+	case "fibonacci":
+		b = Fibonacci{Base: p.BaseDelay}
+	case "decorrelated":
+		b = Decorrelated{Base: p.BaseDelay, Cap: p.MaxDelay}
+	default:
+		b = Exponential{Base: p.BaseDelay, Factor: p.Factor}
+	}
+	if mode, ok := jitterModes[p.Jitter]; ok && p.Backoff != "decorrelated" {
+		b = Jitter{Backoff: b, Factor: p.JitterFactor, Mode: mode}
+	}
+	if p.MaxDelay > 0 || p.MaxElapsed > 0 {
+		b = Limit{Backoff: b, Cap: p.MaxDelay, MaxElapsed: p.MaxElapsed}
+	}
+	return b
+}
+
+// Policies 是按名字组织的一组 [Policy],可直接作为配置字段解码:
+//
+//	type Config struct {
+//	    Retry retriever.Policies `yaml:"retry" json:"retry"`
+//	}
+type Policies map[string]Policy
+
+// Registry 按名字保存已校验的重试策略,供各下游客户端按名取用。它是并发
+// 安全的;策略在注册时即完成校验与转换,查询不再解析。
+type Registry struct {
+	mu       sync.RWMutex
+	policies map[string][]Option
+}
+
+// NewRegistry 返回一个注册了 ps 全部策略的 [Registry]。任一策略非法时返回
+// 错误(指明策略名),不返回部分注册的 Registry。ps 可为 nil。
+func NewRegistry(ps Policies) (*Registry, error) {
+	r := &Registry{policies: make(map[string][]Option, len(ps))}
+	for _, name := range slices.Sorted(maps.Keys(ps)) {
+		if err := r.Register(name, ps[name]); err != nil {
+			return nil, err
+		}
+	}
+	return r, nil
+}
+
+// Register 校验 p 并以 name 注册,覆盖同名策略。p 非法时返回错误,原有
+// 同名策略保持不变。
+func (r *Registry) Register(name string, p Policy) error {
+	opts, err := p.Options()
+	if err != nil {
+		return fmt.Errorf("policy %q: %w", name, err)
+	}
+	r.mu.Lock()
+	r.policies[name] = opts
+	r.mu.Unlock()
+	return nil
+}
+
+// Options 返回名为 name 的策略的 [Option] 列表(每次返回新切片,调用方可
+// 放心追加)。未注册时返回 [ErrUnknownPolicy]。
+func (r *Registry) Options(name string) ([]Option, error) {
+	r.mu.RLock()
+	opts, ok := r.policies[name]
+	r.mu.RUnlock()
+	if !ok {
+		return nil, fmt.Errorf("%w: %q", ErrUnknownPolicy, name)
+	}
+	return slices.Clone(opts), nil
+}
+
+// Names 返回已注册的策略名,按字典序排列。
+func (r *Registry) Names() []string {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	return slices.Sorted(maps.Keys(r.policies))
+}
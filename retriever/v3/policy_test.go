@@ -0,0 +1,234 @@
+package retriever
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"reflect"
+	"syscall"
+	"testing"
+	"time"
+
+	"github.com/tenz-io/gokit/annotation/v3"
+	"gopkg.in/yaml.v3"
+)
+
+// resolve 把 opts 叠加到默认值上,便于断言策略转换结果。
+func resolve(t *testing.T, opts []Option) options {
+	t.Helper()
+	o := defaultOptions()
+	for _, opt := range opts {
+		opt(&o)
+	}
+	return o
+}
+
+func TestPolicy_ZeroValueUsesDefaults(t *testing.T) {
+	opts, err := Policy{}.Options()
+	if err != nil {
+		t.Fatalf("Options() err = %v", err)
+	}
+	o := resolve(t, opts)
+	if o.maxAttempts != 3 || o.timeout != 0 || o.attemptTimeout != 0 || o.retryable != nil {
+		t.Errorf("options = %+v, want package defaults", o)
+	}
+	want := Exponential{Base: 100 * time.Millisecond, Factor: 2}
+	if o.backoff != want {
+		t.Errorf("backoff = %#v, want %#v", o.backoff, want)
+	}
+}
+
+func TestPolicy_Backoffs(t *testing.T) {
+	cases := []struct {
+		p    Policy
+		want Backoff
+	}{
+		{Policy{Backoff: "constant", BaseDelay: time.Second}, Constant(time.Second)},
+		{Policy{Backoff: "linear", Step: time.Second}, Linear{Base: 100 * time.Millisecond, Step: time.Second}},
+		{Policy{Backoff: "fibonacci"}, Fibonacci{Base: 100 * time.Millisecond}},
+		{Policy{Backoff: "decorrelated", MaxDelay: time.Second, Jitter: "full"},
+			Limit{Backoff: Decorrelated{Base: 100 * time.Millisecond, Cap: time.Second}, Cap: time.Second}},
+		{Policy{Jitter: "full"}, Jitter{Backoff: Exponential{Base: 100 * time.Millisecond, Factor: 2}, Mode: JitterFull}},
+		{Policy{Jitter: "additive", JitterFactor: 0.3, MaxElapsed: time.Minute}, Limit{
+			Backoff:    Jitter{Backoff: Exponential{Base: 100 * time.Millisecond, Factor: 2}, Factor: 0.3},
+			MaxElapsed: time.Minute,
+		}},
+	}
+	for _, c := range cases {
+		opts, err := c.p.Options()
+		if err != nil {
+			t.Fatalf("%+v: Options() err = %v", c.p, err)
+		}
+		if got := resolve(t, opts).backoff; !reflect.DeepEqual(got, c.want) {
+			t.Errorf("%+v: backoff = %#v, want %#v", c.p, got, c.want)
+		}
+	}
+}
+
+func TestPolicy_Invalid(t *testing.T) {
+	cases := []Policy{
+		{MaxAttempts: -1},
+		{Backoff: "random"},
+		{Jitter: "lots"},
+		{Jitter: "additive"},
+		{Timeout: -time.Second},
+		{RetryOn: []string{"network", "disk"}},
+	}
+	for _, p := range cases {
+		_, err := p.Options()
+		if _, ok := annotation.AsErrors(err); !ok {
+			t.Errorf("%+v: err = %v, want annotation validation errors", p, err)
+		}
+	}
+}
+
+func TestPolicy_DoesNotMutateReceiver(t *testing.T) {
+	p := Policy{}
+	_, _ = p.Options()
+	if !reflect.DeepEqual(p, Policy{}) {
+		t.Errorf("Options() mutated the policy: %+v", p)
+	}
+}
+
+func TestPolicy_RetryOnClasses(t *testing.T) {
+	opts, err := Policy{RetryOn: []string{"network", "http"}, Backoff: "constant", BaseDelay: 1}.Options()
+	if err != nil {
+		t.Fatalf("Options() err = %v", err)
+	}
+	r := New[int](opts...)
+
+	calls := 0
+	_, _ = r.Do(context.Background(), func(context.Context) (int, error) {
+		calls++
+		return 0, HTTPStatus(errBoom, 404)
+	})
+	if calls != 1 {
+		t.Errorf("404: calls = %d, want 1", calls)
+	}
+	calls = 0
+	_, _ = r.Do(context.Background(), func(context.Context) (int, error) {
+		calls++
+		return 0, syscall.ECONNRESET
+	})
+	if calls != 3 {
+		t.Errorf("ECONNRESET: calls = %d, want 3", calls)
+	}
+}
+
+func TestPolicies_DecodeYAMLAndJSON(t *testing.T) {
+	const doc = `
+payments:
+  max_attempts: 4
+  backoff: exponential
+  base_delay: 200ms
+  attempt_timeout: 1s
+  retry_on: [network, http]
+search:
+  backoff: constant
+`
+	var ps Policies
+	if err := yaml.Unmarshal([]byte(doc), &ps); err != nil {
+		t.Fatalf("yaml.Unmarshal() err = %v", err)
+	}
+	want := Policy{
+		MaxAttempts:    4,
+		Backoff:        "exponential",
+		BaseDelay:      200 * time.Millisecond,
+		AttemptTimeout: time.Second,
+		RetryOn:        []string{"network", "http"},
+	}
+	if !reflect.DeepEqual(ps["payments"], want) {
+		t.Errorf("payments = %+v, want %+v", ps["payments"], want)
+	}
+
+	var fromJSON Policies
+	if err := json.Unmarshal([]byte(`{"search":{"max_attempts":2,"base_delay":"1ms","max_elapsed":"1m30s"}}`), &fromJSON); err != nil {
+		t.Fatalf("json.Unmarshal() err = %v", err)
+	}
+	if got := fromJSON["search"]; got.MaxAttempts != 2 || got.BaseDelay != time.Millisecond || got.MaxElapsed != 90*time.Second {
+		t.Errorf("search = %+v", got)
+	}
+}
+
+func TestPolicy_JSONDurations(t *testing.T) {
+	p := Policy{MaxAttempts: 4, BaseDelay: 200 * time.Millisecond, AttemptTimeout: time.Second, RetryOn: []string{"sql"}}
+	data, err := json.Marshal(p)
+	if err != nil {
+		t.Fatalf("json.Marshal() err = %v", err)
+	}
+	var raw map[string]any
+	if err := json.Unmarshal(data, &raw); err != nil {
+		t.Fatal(err)
+	}
+	if raw["base_delay"] != "200ms" || raw["attempt_timeout"] != "1s" || raw["timeout"] != "0s" || raw["max_attempts"] != 4.0 {
+		t.Errorf("json = %s, want durations as strings", data)
+	}
+
+	var back Policy
+	if err := json.Unmarshal(data, &back); err != nil {
+		t.Fatalf("json.Unmarshal() err = %v", err)
+	}
+	if !reflect.DeepEqual(back, p) {
+		t.Errorf("round trip = %+v, want %+v", back, p)
+	}
+
+	// 纳秒整数仍被接受,兼容旧配置。
+	var legacy Policy
+	if err := json.Unmarshal([]byte(`{"base_delay":1000000,"step":null}`), &legacy); err != nil || legacy.BaseDelay != time.Millisecond {
+		t.Errorf("legacy = %+v, err = %v", legacy, err)
+	}
+
+	for _, bad := range []string{`{"base_delay":"fast"}`, `{"base_delay":true}`, `{"max_attempts":"x"}`} {
+		if err := json.Unmarshal([]byte(bad), &Policy{}); err == nil {
+			t.Errorf("json.Unmarshal(%s) err = nil, want error", bad)
+		}
+	}
+}
+
+func TestRegistry(t *testing.T) {
+	reg, err := NewRegistry(Policies{
+		"payments": {MaxAttempts: 4},
+		"search":   {Backoff: "constant"},
+	})
+	if err != nil {
+		t.Fatalf("NewRegistry() err = %v", err)
+	}
+	if got := reg.Names(); !reflect.DeepEqual(got, []string{"payments", "search"}) {
+		t.Errorf("Names() = %v", got)
+	}
+	opts, err := reg.Options("payments")
+	if err != nil {
+		t.Fatalf("Options() err = %v", err)
+	}
+	if got := resolve(t, opts).maxAttempts; got != 4 {
+		t.Errorf("maxAttempts = %d, want 4", got)
+	}
+
+	// 返回的切片可被调用方追加而不影响注册表。
+	_ = append(opts, WithMaxAttempts(9))
+	again, _ := reg.Options("payments")
+	if got := resolve(t, again).maxAttempts; got != 4 {
+		t.Errorf("maxAttempts after caller append = %d, want 4", got)
+	}
+
+	if _, err := reg.Options("nope"); !errors.Is(err, ErrUnknownPolicy) {
+		t.Errorf("Options(nope) err = %v, want ErrUnknownPolicy", err)
+	}
+}
+
+func TestRegistry_RejectsInvalid(t *testing.T) {
+	if _, err := NewRegistry(Policies{"bad": {Backoff: "random"}}); err == nil {
+		t.Fatal("NewRegistry() should reject an invalid policy")
+	}
+	reg, _ := NewRegistry(nil)
+	if err := reg.Register("p", Policy{MaxAttempts: 2}); err != nil {
+		t.Fatalf("Register() err = %v", err)
+	}
+	if err := reg.Register("p", Policy{Jitter: "lots"}); err == nil {
+		t.Fatal("Register() should reject an invalid policy")
+	}
+	opts, err := reg.Options("p")
+	if err != nil || resolve(t, opts).maxAttempts != 2 {
+		t.Errorf("a rejected Register should keep the previous policy, got err = %v", err)
+	}
+}
@@ -8,10 +8,36 @@
 //     立即返回它;更精细的"哪些错误值得重试"判断可用 [WithRetryable];
 //   - 退避策略 [Backoff] 统一以 [time.Duration] 表达,均为可比较字段、
 //     可直接用复合字面量构造的值类型,并可通过 [Jitter] 装饰器叠加抖动;
-//   - 仅通过 [New] 构造,采用函数式 [Option],无导出的 Config 结构体。
+//   - 仅通过 [New] 构造,采用函数式 [Option],无导出的 Config 结构体
+//     (可从配置加载的 [Policy] 也只是转换为 Option)。
+//
+// 跨调用共享的保护策略可挂到每次尝试上:[CircuitBreaker] 在依赖持续失败时
+// 熔断,以 [ErrCircuitOpen] 快速失败而非继续重试放大故障;它既可用
+// [WrapBreaker] 单独保护任意 [DoFunc],也可经 [WithCircuitBreaker] 与
+// [Retriever] 组合。[Bulkhead] 则限定对一个依赖的并发调用数,名额与队列
+// 占满时以 [ErrBulkheadFull] 拒绝,同样可用 [WrapBulkhead] 或 [WithBulkhead]。
+//
+// fn 可用 [RetryAfter] 为错误附带服务端建议的重试延迟(如 429/503 的
+// Retry-After 头,可用 [ParseRetryAfter] 解析),Do 会优先按它等待,而非
+// [Backoff] 的计算值。
+//
+// 错误分类可用内置的 [Classifier] 声明式组合:[RetryOnNetwork]、
+// [RetryOnHTTPStatus]、[RetryOnContext]、[RetryOnSQL],经 [Any]/[All] 组合
+// 后传给 [WithRetryable]。
+//
+// 每个下游的重试行为可写进配置:[Policy] 经 [Policy.Options] 转为 Option,
+// [Registry] 按名保存一组策略。
+//
+// 重试过程可经 [WithOnRetry]/[WithOnGiveUp] 观测;[WithLogHooks] 与
+// [WithMonitorHooks] 提供内置的日志与 monitor 计数钩子。
+//
+// 多个 Retriever 可共享一个 [RetryBudget](经 [WithRetryBudget]):重试消耗
+// 令牌、成功补充令牌,预算耗尽时退化为单次尝试,防止故障期间的重试风暴。
 //
 // 重试期间,所有等待都会监听 ctx 的取消/超时,从而及时返回,不会多等一次
-// 退避时长。可选的 [WithTimeout] 为全部尝试设置统一截止时间。
+// 退避时长。可选的 [WithTimeout] 为全部尝试设置统一截止时间;
+// [WithAttemptTimeout] 则为每次尝试单独限时,单次超时以 [ErrAttemptTimeout]
+// 报告并继续重试。
 //
 // 返回值约定:[Do] 的所有终止路径都携带 fn 最后一次返回的 result(连同
 // error),便于调用方释放 fn 已创建但未消费的资源(如 *http.Response.Body)。
@@ -81,9 +107,20 @@ type Retriever[T any] interface {
 	//   - fn 返回 nil error:立即返回 (result, nil)。
 	//   - fn 返回 [NonRetryable] 标记的错误:立即返回 (result, err)。
 	//   - 分类器 ([WithRetryable]) 判定不可重试:立即返回 (result, err)。
+	//   - guard(如 [WithCircuitBreaker]、[WithBulkhead])拒绝本次尝试:立即
+	//     返回上一次尝试的 result 与拒绝错误(如 [ErrCircuitOpen]、
+	//     [ErrBulkheadFull],若有上一次失败则一并包裹)。
 	//   - ctx 在 fn 调用后已终止(超时/取消):返回 (result, ctx.Err()),
 	//     优先于 [ErrMaxAttempts],使同一超时不论发生在哪次尝试都统一归类。
 	//   - 退避等待期间 ctx 取消:返回 (result, ctx.Err())。
+	//   - 服务端建议延迟 ([RetryAfter]) 超出 ctx 剩余截止时间:立即返回
+	//     (result, err),err 为携带该建议的原错误。
+	//   - 重试预算 ([WithRetryBudget]) 耗尽:返回 (result, 用
+	//     [ErrRetryBudgetExhausted] 包裹的 lastErr)。
+	//   - 单次尝试超时 ([WithAttemptTimeout]) 总被重试;若最后一次仍超时,
+	//     返回的 [ErrMaxAttempts] 同时包裹 [ErrAttemptTimeout]。
+	//   - 退避策略经 [Stopper] 要求停止(如 [Limit] 的累计耗时上限):与尝试
+	//     耗尽相同。
 	//   - 耗尽全部尝试仍失败:返回 (result, 用 [ErrMaxAttempts] 包裹的 lastErr)。
 	//
 	// 各终止路径都携带 fn 最后一次返回的 result(可能为零值),见 [DoFunc]
@@ -95,10 +132,17 @@ type Retriever[T any] interface {
 type Option func(*options)
 
 type options struct {
-	maxAttempts int
-	timeout     time.Duration
-	backoff     Backoff
-	retryable   func(error) bool
+	maxAttempts    int
+	timeout        time.Duration
+	attemptTimeout time.Duration
+	backoff        Backoff
+	retryable      func(error) bool
+	guards         []guard
+	budget         *RetryBudget
+	maxRetryAfter  time.Duration
+	onRetry        []Hook
+	onSuccess      []Hook
+	onGiveUp       []Hook
 }
 
 func defaultOptions() options {
@@ -109,7 +153,8 @@ func defaultOptions() options {
 			Base:   100 * time.Millisecond,
 			Factor: 2,
 		},
-		retryable: nil,
+		retryable:     nil,
+		maxRetryAfter: defaultMaxRetryAfter,
 	}
 }
 
@@ -154,22 +199,37 @@ func New[T any](opts ...Option) Retriever[T] {
 		opt(&o)
 	}
 	return &retrier[T]{
-		maxAttempts: o.maxAttempts,
-		timeout:     o.timeout,
-		backoff:     o.backoff,
-		retryable:   o.retryable,
+		maxAttempts:    o.maxAttempts,
+		timeout:        o.timeout,
+		attemptTimeout: o.attemptTimeout,
+		backoff:        o.backoff,
+		retryable:      o.retryable,
+		guards:         o.guards,
+		budget:         o.budget,
+		maxRetryAfter:  o.maxRetryAfter,
+		onRetry:        o.onRetry,
+		onSuccess:      o.onSuccess,
+		onGiveUp:       o.onGiveUp,
 	}
 }
 
 // retrier 是 [Retriever] 的唯一实现。其自身字段在构造后不可变,故 Do 可被
 // 多个 goroutine 并发调用。但 [Backoff] 实现与 [WithRetryable] 闭包可能
-// 含共享可变状态:内置的 [Constant]/[Linear]/[Exponential]/[Jitter] 均
-// 并发安全,自定义 Backoff 或分类器须由调用方自行保证并发安全。
+// 含共享可变状态:内置策略均并发安全(有状态的 [Decorrelated]/[Limit] 经
+// [StatefulBackoff] 为每次调用创建独立状态),自定义 Backoff 或分类器须由
+// 调用方自行保证并发安全。
 type retrier[T any] struct {
-	maxAttempts int
-	timeout     time.Duration
-	backoff     Backoff
-	retryable   func(error) bool
+	maxAttempts    int
+	timeout        time.Duration
+	attemptTimeout time.Duration
+	backoff        Backoff
+	retryable      func(error) bool
+	guards         []guard
+	budget         *RetryBudget
+	maxRetryAfter  time.Duration
+	onRetry        []Hook
+	onSuccess      []Hook
+	onGiveUp       []Hook
 }
 
 // Do 实现 [Retriever]。
@@ -189,19 +249,46 @@ func (r *retrier[T]) Do(ctx context.Context, fn DoFunc[T]) (T, error) {
 		defer cancel()
 	}
 
+	start := time.Now()
+	result, attempts, err := r.run(ctx, fn, start)
+	info := AttemptInfo{Attempt: attempts, Err: err, Elapsed: time.Since(start)}
+	if err != nil {
+		fire(ctx, r.onGiveUp, info)
+	} else {
+		fire(ctx, r.onSuccess, info)
+	}
+	return result, err
+}
+
+// run 执行重试循环,额外返回 fn 实际被调用的次数,供成功与放弃钩子使用。
+func (r *retrier[T]) run(ctx context.Context, fn DoFunc[T], start time.Time) (T, int, error) {
+	var zero T
 	var (
 		lastErr    error
 		lastResult T
+		// 每次调用独立的退避状态,见 [StatefulBackoff]。
+		backoff = startBackoff(r.backoff)
 	)
 	for attempt := 0; attempt < r.maxAttempts; attempt++ {
 		// 在每次尝试前检查 ctx,使外部取消/超时能在退避等待期间被及时感知。
 		if err := ctx.Err(); err != nil {
-			return zero, err
+			return zero, attempt, err
 		}
 
-		result, err := fn(ctx)
+		result, rejected, err := r.attempt(ctx, fn)
+		if rejected {
+			// guard(如熔断器)拒绝了本次尝试:fn 未被调用,继续重试只会放大
+			// 故障。带回上一次尝试的 result,并一并包裹其错误。
+			if lastErr != nil {
+				return lastResult, attempt, fmt.Errorf("%w: %w", err, lastErr)
+			}
+			return lastResult, attempt, err
+		}
 		if err == nil {
-			return result, nil
+			if r.budget != nil {
+				r.budget.deposit(ctx)
+			}
+			return result, attempt + 1, nil
 		}
 		// 记录本次失败:即便要终止,也把 fn 返回的 result 带回去 —— 调用方可能
 		// 需要它(如已打开但需关闭的 *http.Response)。中间成功但被分类器/
@@ -211,17 +298,18 @@ func (r *retrier[T]) Do(ctx context.Context, fn DoFunc[T]) (T, error) {
 		// fn 调用后若 ctx 已终止(超时/取消),优先按 ctx 终止归类,而非尝试耗尽:
 		// 这样同一超时不论发生在哪次尝试,都统一返回 ctx.Err(),分类与序号解耦。
 		if cerr := ctx.Err(); cerr != nil {
-			return lastResult, cerr
+			return lastResult, attempt + 1, cerr
 		}
 
 		// NonRetryable 标记优先:立即返回该错误(标记本身字符串透明)。
 		if IsNonRetryable(err) {
-			return lastResult, err
+			return lastResult, attempt + 1, err
 		}
 
-		// 若提供了分类器,且它判定当前错误不可重试,则立即返回。
-		if r.retryable != nil && !r.retryable(err) {
-			return lastResult, lastErr
+		// 若提供了分类器,且它判定当前错误不可重试,则立即返回。单次尝试超时
+		// 总是可重试,不经分类器。
+		if r.retryable != nil && !errors.Is(err, ErrAttemptTimeout) && !r.retryable(err) {
+			return lastResult, attempt + 1, lastErr
 		}
 
 		// 最后一次尝试不再等待退避。
@@ -229,16 +317,107 @@ func (r *retrier[T]) Do(ctx context.Context, fn DoFunc[T]) (T, error) {
 			break
 		}
 
+		// 服务端建议的延迟长于剩余截止时间时,等下去也注定超时:直接放弃。
+		wait, ok, stop := r.delay(ctx, backoff, attempt, err)
+		if !ok {
+			return lastResult, attempt + 1, lastErr
+		}
+		// 退避策略要求停止(如 [Limit] 累计耗时超限):按尝试耗尽处理。
+		if stop {
+			return lastResult, attempt + 1, fmt.Errorf("%w: %w", ErrMaxAttempts, lastErr)
+		}
+
+		// 重试预算耗尽:退化为单次尝试,避免故障期间放大负载。
+		if r.budget != nil && !r.budget.withdraw(ctx) {
+			return lastResult, attempt + 1, fmt.Errorf("%w: %w", ErrRetryBudgetExhausted, lastErr)
+		}
+
+		fire(ctx, r.onRetry, AttemptInfo{
+			Attempt: attempt + 1,
+			Err:     err,
+			Delay:   wait,
+			Elapsed: time.Since(start),
+		})
+
 		// 退避等待,期间监听 ctx 取消。
-		timer := time.NewTimer(r.backoff.Next(attempt))
+		timer := time.NewTimer(wait)
 		select {
 		case <-ctx.Done():
 			timer.Stop()
-			return lastResult, ctx.Err()
+			return lastResult, attempt + 1, ctx.Err()
 		case <-timer.C:
 		}
 		timer.Stop()
 	}
 
-	return lastResult, fmt.Errorf("%w: %w", ErrMaxAttempts, lastErr)
+	return lastResult, r.maxAttempts, fmt.Errorf("%w: %w", ErrMaxAttempts, lastErr)
+}
+
+// delay 返回第 attempt 次失败(err)后的等待时长。err 携带服务端建议延迟
+// ([RetryAfterOf])时优先使用它,并以 maxRetryAfter 封顶;若该延迟超出 ctx
+// 的剩余截止时间,返回 ok == false,表示不应再重试。否则使用本次调用的
+// backoff;它经 [Stopper] 要求停止时返回 stop == true。
+func (r *retrier[T]) delay(ctx context.Context, backoff Backoff, attempt int, err error) (wait time.Duration, ok, stop bool) {
+	d, ok := RetryAfterOf(err)
+	if !ok {
+		wait, ok := nextOrStop(backoff, attempt)
+		return wait, true, !ok
+	}
+	d = min(d, r.maxRetryAfter)
+	if deadline, ok := ctx.Deadline(); ok && d > time.Until(deadline) {
+		return 0, false, false
+	}
+	return d, true, false
+}
+
+// attempt 执行一次尝试:按 guards 由外向内依次放行,全部放行后调用 fn
+// (设置了 [WithAttemptTimeout] 时以单次超时的子 context 调用),
+// 再把结果由内向外交还。某个 guard 拒绝时,已放行的外层 guard 以该拒绝
+// 错误释放,fn 不被调用,rejected 为 true。
+func (r *retrier[T]) attempt(ctx context.Context, fn DoFunc[T]) (result T, rejected bool, err error) {
+	call := fn
+	if r.attemptTimeout > 0 {
+		// 单次超时紧贴 fn,使 guard(如熔断器)把超时计为本次尝试的失败。
+		call = withAttemptTimeout(call, r.attemptTimeout)
+	}
+	for i := len(r.guards) - 1; i >= 0; i-- {
+		call = guardFunc(r.guards[i], call, &rejected)
+	}
+	result, err = call(ctx)
+	return result, rejected, err
+}
+
+// guard 在每次尝试前放行或拒绝,并在尝试结束后接收其结果。熔断器等
+// 跨调用共享的保护策略实现它。
+type guard interface {
+	// acquire 放行时返回一个须以本次尝试结果调用恰好一次的 release;
+	// 拒绝时返回非 nil error,此时不得调用 fn。
+	acquire(ctx context.Context) (release func(error), err error)
+}
+
+// guardFunc 返回经 g 放行后再调用 fn 的 [DoFunc],并把 fn 的结果交还 g。
+// g 拒绝时不调用 fn;若 rejected 非 nil 则置位它,供 Do 区分"被 guard
+// 拒绝"与"fn 返回了同一错误"。fn panic 时以 errAttemptPanicked 释放 g 再
+// 继续 panic,避免名额泄漏。
+func guardFunc[T any](g guard, fn DoFunc[T], rejected *bool) DoFunc[T] {
+	return func(ctx context.Context) (T, error) {
+		release, err := g.acquire(ctx)
+		if err != nil {
+			if rejected != nil {
+				*rejected = true
+			}
+			var zero T
+			return zero, err
+		}
+		done := false
+		defer func() {
+			if !done {
+				release(errAttemptPanicked)
+			}
+		}()
+		result, err := fn(ctx)
+		done = true
+		release(err)
+		return result, err
+	}
 }
@@ -0,0 +1,104 @@
+package retriever
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// defaultMaxRetryAfter 是 [WithMaxRetryAfter] 的默认上限:防止服务端给出一个
+// 过长的建议延迟(例如数小时)让 Do 长时间挂起。
+const defaultMaxRetryAfter = 30 * time.Second
+
+// retryAfterError 是 [RetryAfter] 的内部实现。与 [NonRetryable] 相同,它对
+// 错误字符串透明:Error() 直接委托给内层 error。
+type retryAfterError struct {
+	err   error
+	delay time.Duration
+}
+
+func (e *retryAfterError) Error() string             { return e.err.Error() }
+func (e *retryAfterError) Unwrap() error             { return e.err }
+func (e *retryAfterError) RetryAfter() time.Duration { return e.delay }
+
+// retryAfterer 是携带建议重试延迟的错误所实现的接口。任何实现了它的
+// error(包括第三方 SDK 的限流错误)都会被 [Retriever.Do] 识别,无需经过
+// [RetryAfter] 包装。
+type retryAfterer interface {
+	RetryAfter() time.Duration
+}
+
+// RetryAfter 包装 err,附带服务端建议的重试延迟 d(通常来自 429/503 响应的
+// Retry-After 头,或限流 API 的 "retry in 3s")。[Retriever.Do] 重试该错误时
+// 优先使用 d 而非 [Backoff] 计算的时长,并受 [WithMaxRetryAfter] 上限约束。
+// 负的 d 按 0 处理。nil 输入返回 nil。标记是字符串透明的:Error() 文本与
+// err 一致。
+func RetryAfter(err error, d time.Duration) error {
+	if err == nil {
+		return nil
+	}
+	return &retryAfterError{err: err, delay: max(d, 0)}
+}
+
+// RetryAfterOf 返回 err 链上第一个建议重试延迟(经由 [errors.As] 穿透),
+// 以及是否存在。它识别 [RetryAfter] 的包装,以及任何实现了
+// RetryAfter() time.Duration 方法的 error。
+func RetryAfterOf(err error) (time.Duration, bool) {
+	var ra retryAfterer
+	if !errors.As(err, &ra) {
+		return 0, false
+	}
+	return max(ra.RetryAfter(), 0), true
+}
+
+// ParseRetryAfter 从 resp 的 Retry-After 头解析建议延迟。头可以是非负秒数
+// ("120")或 HTTP-date("Wed, 21 Oct 2015 07:28:00 GMT",已过去的时刻按 0
+// 计)。resp 为 nil、头缺失或无法解析时返回 false。
+//
+//	if resp.StatusCode == http.StatusTooManyRequests {
+//	    err := fmt.Errorf("rate limited: %d", resp.StatusCode)
+//	    if d, ok := retriever.ParseRetryAfter(resp); ok {
+//	        err = retriever.RetryAfter(err, d)
+//	    }
+//	    return nil, err
+//	}
+func ParseRetryAfter(resp *http.Response) (time.Duration, bool) {
+	if resp == nil {
+		return 0, false
+	}
+	return parseRetryAfter(resp.Header.Get("Retry-After"), time.Now())
+}
+
+// parseRetryAfter 解析 Retry-After 头值,now 用于把 HTTP-date 换算为时长。
+func parseRetryAfter(v string, now time.Time) (time.Duration, bool) {
+	v = strings.TrimSpace(v)
+	if v == "" {
+		return 0, false
+	}
+	if secs, err := strconv.ParseInt(v, 10, 64); err == nil {
+		if secs < 0 {
+			return 0, false
+		}
+		if secs > int64(maxDuration/time.Second) {
+			return maxDuration, true
+		}
+		return time.Duration(secs) * time.Second, true
+	}
+	if at, err := http.ParseTime(v); err == nil {
+		return max(at.Sub(now), 0), true
+	}
+	return 0, false
+}
+
+// WithMaxRetryAfter 设置服务端建议延迟的上限:超过 d 的建议按 d 等待。
+// d<=0 被忽略(默认 30s)。该上限只作用于 [RetryAfter] 等建议延迟,不影响
+// [Backoff] 计算的时长。
+func WithMaxRetryAfter(d time.Duration) Option {
+	return func(o *options) {
+		if d > 0 {
+			o.maxRetryAfter = d
+		}
+	}
+}
@@ -0,0 +1,166 @@
+package retriever
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"testing"
+	"time"
+)
+
+// sdkThrottle 模拟第三方 SDK 自带 RetryAfter() 方法的限流错误。
+type sdkThrottle struct{ d time.Duration }
+
+func (e sdkThrottle) Error() string             { return "throttled" }
+func (e sdkThrottle) RetryAfter() time.Duration { return e.d }
+
+func TestRetryAfter_Nil(t *testing.T) {
+	if RetryAfter(nil, time.Second) != nil {
+		t.Error("RetryAfter(nil) should be nil")
+	}
+}
+
+func TestRetryAfter_TransparentAndExtractable(t *testing.T) {
+	err := RetryAfter(errBoom, 3*time.Second)
+	if err.Error() != errBoom.Error() {
+		t.Errorf("Error() = %q, want %q", err.Error(), errBoom.Error())
+	}
+	if !errors.Is(err, errBoom) {
+		t.Error("errors.Is should reach the inner error")
+	}
+	wrapped := errors.Join(errPerm, err)
+	if d, ok := RetryAfterOf(wrapped); !ok || d != 3*time.Second {
+		t.Errorf("RetryAfterOf() = %v, %v; want 3s, true", d, ok)
+	}
+	if d, ok := RetryAfterOf(RetryAfter(errBoom, -time.Second)); !ok || d != 0 {
+		t.Errorf("RetryAfterOf(negative) = %v, %v; want 0, true", d, ok)
+	}
+	if _, ok := RetryAfterOf(errBoom); ok {
+		t.Error("RetryAfterOf(plain error) should report false")
+	}
+}
+
+func TestRetryAfterOf_ThirdPartyError(t *testing.T) {
+	if d, ok := RetryAfterOf(sdkThrottle{d: time.Second}); !ok || d != time.Second {
+		t.Errorf("RetryAfterOf() = %v, %v; want 1s, true", d, ok)
+	}
+}
+
+func TestParseRetryAfter(t *testing.T) {
+	now := time.Date(2015, 10, 21, 7, 28, 0, 0, time.UTC)
+	cases := []struct {
+		in     string
+		want   time.Duration
+		wantOK bool
+	}{
+		{"120", 120 * time.Second, true},
+		{" 0 ", 0, true},
+		{"-1", 0, false},
+		{"", 0, false},
+		{"soon", 0, false},
+		{"Wed, 21 Oct 2015 07:28:30 GMT", 30 * time.Second, true},
+		{"Wed, 21 Oct 2015 07:27:00 GMT", 0, true}, // 已过去的时刻
+		{"99999999999999999", maxDuration, true},
+	}
+	for _, c := range cases {
+		got, ok := parseRetryAfter(c.in, now)
+		if got != c.want || ok != c.wantOK {
+			t.Errorf("parseRetryAfter(%q) = %v, %v; want %v, %v", c.in, got, ok, c.want, c.wantOK)
+		}
+	}
+}
+
+func TestParseRetryAfter_Response(t *testing.T) {
+	if _, ok := ParseRetryAfter(nil); ok {
+		t.Error("ParseRetryAfter(nil) should report false")
+	}
+	resp := &http.Response{Header: http.Header{}}
+	resp.Header.Set("Retry-After", "5")
+	if d, ok := ParseRetryAfter(resp); !ok || d != 5*time.Second {
+		t.Errorf("ParseRetryAfter() = %v, %v; want 5s, true", d, ok)
+	}
+}
+
+func TestDo_PrefersRetryAfterOverBackoff(t *testing.T) {
+	calls := 0
+	r := New[int](WithMaxAttempts(2), WithBackoff(Constant(time.Hour)))
+	start := time.Now()
+	_, err := r.Do(context.Background(), func(context.Context) (int, error) {
+		calls++
+		if calls == 1 {
+			return 0, RetryAfter(errBoom, 10*time.Millisecond)
+		}
+		return 1, nil
+	})
+	if err != nil {
+		t.Fatalf("Do() err = %v", err)
+	}
+	if elapsed := time.Since(start); elapsed < 10*time.Millisecond || elapsed > time.Second {
+		t.Errorf("elapsed = %v, want ~10ms (Retry-After, not the 1h backoff)", elapsed)
+	}
+}
+
+func TestDo_RetryAfterCapped(t *testing.T) {
+	calls := 0
+	r := New[int](WithMaxAttempts(2), WithMaxRetryAfter(10*time.Millisecond))
+	start := time.Now()
+	_, _ = r.Do(context.Background(), func(context.Context) (int, error) {
+		calls++
+		return 0, RetryAfter(errBoom, time.Hour)
+	})
+	if calls != 2 {
+		t.Errorf("calls = %d, want 2", calls)
+	}
+	if elapsed := time.Since(start); elapsed > time.Second {
+		t.Errorf("elapsed = %v, want capped at ~10ms", elapsed)
+	}
+}
+
+func TestDo_RetryAfterBeyondDeadlineSkipsRetry(t *testing.T) {
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+	calls := 0
+	r := New[int](WithMaxAttempts(3))
+	start := time.Now()
+	result, err := r.Do(ctx, func(context.Context) (int, error) {
+		calls++
+		return 7, RetryAfter(errBoom, 10*time.Second)
+	})
+	if calls != 1 {
+		t.Errorf("calls = %d, want 1", calls)
+	}
+	if elapsed := time.Since(start); elapsed > 40*time.Millisecond {
+		t.Errorf("elapsed = %v, want an immediate return", elapsed)
+	}
+	if !errors.Is(err, errBoom) || errors.Is(err, context.DeadlineExceeded) {
+		t.Errorf("err = %v, want the original error, not a deadline error", err)
+	}
+	if d, ok := RetryAfterOf(err); !ok || d != 10*time.Second {
+		t.Errorf("RetryAfterOf(err) = %v, %v; the suggestion should stay inspectable", d, ok)
+	}
+	if result != 7 {
+		t.Errorf("result = %d, want 7", result)
+	}
+}
+
+func TestDo_RetryAfterBeyondDeadlineDoesNotChargeBudget(t *testing.T) {
+	b := NewRetryBudget("dep", WithBudgetMaxTokens(5))
+	ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+	defer cancel()
+	r := New[int](WithRetryBudget(b))
+	_, _ = r.Do(ctx, func(context.Context) (int, error) {
+		return 0, RetryAfter(errBoom, time.Minute)
+	})
+	if got := b.Stats().Retries; got != 0 {
+		t.Errorf("budget Retries = %d, want 0 for a skipped retry", got)
+	}
+}
+
+func TestWithMaxRetryAfter_NonPositiveIgnored(t *testing.T) {
+	o := defaultOptions()
+	WithMaxRetryAfter(0)(&o)
+	WithMaxRetryAfter(-time.Second)(&o)
+	if o.maxRetryAfter != defaultMaxRetryAfter {
+		t.Errorf("maxRetryAfter = %v, want default %v", o.maxRetryAfter, defaultMaxRetryAfter)
+	}
+}
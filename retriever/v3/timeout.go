@@ -0,0 +1,40 @@
+package retriever
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+)
+
+// ErrAttemptTimeout 标记单次尝试因 [WithAttemptTimeout] 超时而失败。它包裹
+// fn 返回的原错误(通常即 [context.DeadlineExceeded]),因此须先于
+// context.DeadlineExceeded 判别,才能区分"单次尝试超时"与"整体截止时间
+// 到达":后者返回的 error 不含 ErrAttemptTimeout。
+var ErrAttemptTimeout = errors.New("retriever: attempt timed out")
+
+// WithAttemptTimeout 为每次尝试设置独立的超时:每次调用 fn 时传入一个
+// d 后到期的子 context,使单次挂起的尝试不会耗尽全部时间。单次超时总被
+// 视为可重试(跳过 [WithRetryable] 分类器,[NonRetryable] 标记仍优先),
+// 其错误以 [ErrAttemptTimeout] 包裹;整体时长仍受 [WithTimeout] 与 ctx 自身
+// 约束,子 context 的截止时间不会晚于它们。d<=0 表示不设(默认)。
+//
+// 超时只经由 ctx 传达:无视 ctx 的 fn 无法被中断,Do 会等它返回。
+func WithAttemptTimeout(d time.Duration) Option {
+	return func(o *options) { o.attemptTimeout = max(d, 0) }
+}
+
+// withAttemptTimeout 返回以 d 为单次超时调用 fn 的 [DoFunc]。若 fn 失败时
+// 子 context 已超时而父 ctx 仍存活,即本次失败由单次超时导致,错误以
+// [ErrAttemptTimeout] 包裹;父 ctx 先终止时原样返回,交由 Do 按 ctx 终止归类。
+func withAttemptTimeout[T any](fn DoFunc[T], d time.Duration) DoFunc[T] {
+	return func(ctx context.Context) (T, error) {
+		actx, cancel := context.WithTimeout(ctx, d)
+		defer cancel()
+		result, err := fn(actx)
+		if err != nil && ctx.Err() == nil && errors.Is(actx.Err(), context.DeadlineExceeded) {
+			err = fmt.Errorf("%w: %w", ErrAttemptTimeout, err)
+		}
+		return result, err
+	}
+}
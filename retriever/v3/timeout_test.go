@@ -0,0 +1,129 @@
+package retriever
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+)
+
+// hang 阻塞直到 ctx 终止,模拟一次挂起的调用。
+func hang(ctx context.Context) (int, error) {
+	<-ctx.Done()
+	return 0, ctx.Err()
+}
+
+func TestDo_AttemptTimeoutRetriesHungAttempt(t *testing.T) {
+	calls := 0
+	r := New[int](WithAttemptTimeout(20*time.Millisecond), WithBackoff(Constant(0)))
+	result, err := r.Do(context.Background(), func(ctx context.Context) (int, error) {
+		calls++
+		if calls == 1 {
+			return hang(ctx)
+		}
+		return 42, nil
+	})
+	if err != nil || result != 42 {
+		t.Fatalf("Do() = %v, %v; want 42, nil", result, err)
+	}
+	if calls != 2 {
+		t.Errorf("calls = %d, want 2", calls)
+	}
+}
+
+func TestDo_AttemptTimeoutReportedOnExhaustion(t *testing.T) {
+	r := New[int](WithAttemptTimeout(10*time.Millisecond), WithBackoff(Constant(0)))
+	_, err := r.Do(context.Background(), hang)
+	if !errors.Is(err, ErrMaxAttempts) || !errors.Is(err, ErrAttemptTimeout) {
+		t.Errorf("err = %v, want ErrMaxAttempts wrapping ErrAttemptTimeout", err)
+	}
+}
+
+func TestDo_AttemptTimeoutBypassesClassifier(t *testing.T) {
+	calls := 0
+	r := New[int](
+		WithAttemptTimeout(10*time.Millisecond),
+		WithBackoff(Constant(0)),
+		WithRetryable(func(err error) bool { return !errors.Is(err, context.DeadlineExceeded) }),
+	)
+	_, _ = r.Do(context.Background(), func(ctx context.Context) (int, error) {
+		calls++
+		return hang(ctx)
+	})
+	if calls != 3 {
+		t.Errorf("calls = %d, want 3: attempt timeouts are always retryable", calls)
+	}
+}
+
+func TestDo_OverallDeadlineNotReportedAsAttemptTimeout(t *testing.T) {
+	// 整体截止时间先于单次超时到达:返回 ctx 错误,不含 ErrAttemptTimeout。
+	r := New[int](WithTimeout(20*time.Millisecond), WithAttemptTimeout(time.Hour))
+	start := time.Now()
+	_, err := r.Do(context.Background(), hang)
+	if !errors.Is(err, context.DeadlineExceeded) || errors.Is(err, ErrAttemptTimeout) {
+		t.Errorf("err = %v, want a bare DeadlineExceeded", err)
+	}
+	if elapsed := time.Since(start); elapsed > time.Second {
+		t.Errorf("elapsed = %v, the overall deadline should govern", elapsed)
+	}
+}
+
+func TestDo_OverallDeadlineBoundsAttemptTimeouts(t *testing.T) {
+	calls := 0
+	r := New[int](WithTimeout(50*time.Millisecond), WithAttemptTimeout(20*time.Millisecond),
+		WithMaxAttempts(100), WithBackoff(Constant(0)))
+	_, err := r.Do(context.Background(), func(ctx context.Context) (int, error) {
+		calls++
+		return hang(ctx)
+	})
+	if !errors.Is(err, context.DeadlineExceeded) || errors.Is(err, ErrAttemptTimeout) {
+		t.Errorf("err = %v, want the overall DeadlineExceeded", err)
+	}
+	if calls < 2 || calls > 4 {
+		t.Errorf("calls = %d, want a few retries within the overall deadline", calls)
+	}
+}
+
+func TestDo_AttemptTimeoutChildContext(t *testing.T) {
+	r := New[int](WithAttemptTimeout(time.Minute))
+	_, _ = r.Do(context.Background(), func(ctx context.Context) (int, error) {
+		deadline, ok := ctx.Deadline()
+		if !ok || time.Until(deadline) > time.Minute {
+			t.Errorf("attempt ctx deadline = %v, %v; want within 1m", deadline, ok)
+		}
+		return 0, nil
+	})
+}
+
+func TestDo_AttemptTimeoutNotAppliedToFastFailure(t *testing.T) {
+	r := New[int](WithAttemptTimeout(time.Minute), WithMaxAttempts(1))
+	_, err := r.Do(context.Background(), func(context.Context) (int, error) { return 0, errBoom })
+	if errors.Is(err, ErrAttemptTimeout) {
+		t.Errorf("err = %v, an ordinary failure should not be flagged as a timeout", err)
+	}
+}
+
+func TestDo_AttemptTimeoutCountsAsBreakerFailure(t *testing.T) {
+	cb := NewCircuitBreaker("dep", WithBreakerMinRequests(2))
+	r := New[int](WithAttemptTimeout(5*time.Millisecond), WithMaxAttempts(2),
+		WithBackoff(Constant(0)), WithCircuitBreaker(cb))
+	_, _ = r.Do(context.Background(), hang)
+	if cb.State() != StateOpen {
+		t.Errorf("State() = %v, want open after timed-out attempts", cb.State())
+	}
+}
+
+func TestWithAttemptTimeout_NonPositiveMeansNone(t *testing.T) {
+	o := defaultOptions()
+	WithAttemptTimeout(-time.Second)(&o)
+	if o.attemptTimeout != 0 {
+		t.Errorf("attemptTimeout = %v, want 0", o.attemptTimeout)
+	}
+	r := New[int](WithAttemptTimeout(0))
+	_, _ = r.Do(context.Background(), func(ctx context.Context) (int, error) {
+		if _, ok := ctx.Deadline(); ok {
+			t.Error("ctx should carry no deadline without an attempt timeout")
+		}
+		return 0, nil
+	})
+}